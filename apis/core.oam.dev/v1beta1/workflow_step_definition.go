@@ -41,9 +41,60 @@ type WorkflowStepDefinitionStatus struct {
 	condition.ConditionedStatus `json:",inline"`
 	// ConfigMapRef refer to a ConfigMap which contains OpenAPI V3 JSON schema of Component parameters.
 	ConfigMapRef string `json:"configMapRef,omitempty"`
+	// ConfigMapNamespace is the namespace of the ConfigMapRef when the controller stores the schema ConfigMaps in a
+	// centralized namespace, it's not set if the ConfigMap is in the namespace of the definition.
+	// +optional
+	ConfigMapNamespace string `json:"configMapNamespace,omitempty"`
+	// ConfigMapHash is the sha256 hash of the data the controller stores in the schema ConfigMap, the out-of-band edits of
+	// the data are detected and reverted against it. It's not set if the schemas are stored in the immutable ConfigMaps.
+	// +optional
+	ConfigMapHash string `json:"configMapHash,omitempty"`
 	// LatestRevision of the component definition
 	// +optional
 	LatestRevision *common.Revision `json:"latestRevision,omitempty"`
+	// ComplexityScore is the computed complexity of the parameter schema, only set when the controller enables it.
+	// +optional
+	ComplexityScore int `json:"complexityScore,omitempty"`
+	// Warnings are the findings of the lint rules enabled in the controller which don't block the reconciliation.
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+	// SchemaVersion is increased every time the content of the parameter schema changes, independent of the revision names.
+	// +optional
+	SchemaVersion int64 `json:"schemaVersion,omitempty"`
+	// SchemaHash is the sha256 hash of the rendered parameter schema.
+	// +optional
+	SchemaHash string `json:"schemaHash,omitempty"`
+	// PhaseDurations are the durations of the schema generation phases in the reconciliation which changed the status,
+	// only set when the controller enables it.
+	// +optional
+	PhaseDurations map[string]metav1.Duration `json:"phaseDurations,omitempty"`
+	// Provenance is the source the definition comes from, only set when the controller enables it.
+	// +optional
+	Provenance *DefinitionProvenance `json:"provenance,omitempty"`
+	// Idempotent is whether the step declares running it repeatedly has the same effect as running it once, it's not set
+	// if the step doesn't declare it.
+	// +optional
+	Idempotent *bool `json:"idempotent,omitempty"`
+	// FirstSeen is when the controller first reconciled the definition, it's set once and never overwritten. Only set when
+	// the controller enables it.
+	// +optional
+	FirstSeen *metav1.Time `json:"firstSeen,omitempty"`
+	// PendingRevision is the revision with breaking changes waiting for the approval to become the latest revision, only
+	// set when the controller requires the approval.
+	// +optional
+	PendingRevision *common.Revision `json:"pendingRevision,omitempty"`
+	// Stability is the stability level declared by the definition, e.g. alpha, beta or stable. It's not set if the
+	// definition declares none or an invalid one.
+	// +optional
+	Stability string `json:"stability,omitempty"`
+}
+
+// DefinitionProvenance is the source repository and commit the definition comes from
+type DefinitionProvenance struct {
+	// Repository is the url of the source repository
+	Repository string `json:"repository,omitempty"`
+	// Commit is the commit SHA in the source repository
+	Commit string `json:"commit,omitempty"`
 }
 
 // SetConditions set condition for WorkflowStepDefinition
@@ -56,6 +56,12 @@ const (
 	AnnoDefinitionIcon = "definition.oam.dev/icon"
 	// AnnoDefinitionAppliedWorkloads is the annotation which describe what is the workloads used for in a TraitDefinition Object
 	AnnoDefinitionAppliedWorkloads = "definition.oam.dev/appliedWorkloads"
+	// AnnoDefinitionExportFormats is the annotation which lists the extra formats, separated by comma, the schema of the definition is exported in
+	AnnoDefinitionExportFormats = "definition.oam.dev/export-formats"
+	// AnnoDefinitionSourceRepo is the annotation which describe the url of the repository the definition comes from
+	AnnoDefinitionSourceRepo = "definition.oam.dev/source-repo"
+	// AnnoDefinitionSourceCommit is the annotation which describe the commit SHA of the repository the definition comes from
+	AnnoDefinitionSourceCommit = "definition.oam.dev/source-commit"
 	// LabelDefinition is the label for definition
 	LabelDefinition = "definition.oam.dev"
 	// LabelDefinitionName is the label for definition name
@@ -30,6 +30,7 @@ import (
 	pkgmulticluster "github.com/kubevela/pkg/multicluster"
 	flag "github.com/spf13/pflag"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -47,6 +48,7 @@ import (
 	commonconfig "github.com/oam-dev/kubevela/pkg/controller/common"
 	oamcontroller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	oamv1alpha2 "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core/workflow/workflowstepdefinition"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
 	"github.com/oam-dev/kubevela/pkg/features"
 	_ "github.com/oam-dev/kubevela/pkg/monitor/metrics"
@@ -143,6 +145,80 @@ func main() {
 	flag.BoolVar(&controllerArgs.EnableCompatibility, "enable-asi-compatibility", false, "enable compatibility for asi")
 	flag.BoolVar(&controllerArgs.IgnoreAppWithoutControllerRequirement, "ignore-app-without-controller-version", false, "If true, application controller will not process the app without 'app.oam.dev/controller-version-require' annotation")
 	flag.BoolVar(&controllerArgs.IgnoreDefinitionWithoutControllerRequirement, "ignore-definition-without-controller-version", false, "If true, trait/component/workflowstep definition controller will not process the definition without 'definition.oam.dev/controller-version-require' annotation")
+	flag.BoolVar(&controllerArgs.StepDefinitionComplexityScore, "step-definition-complexity-score", false, "If true, workflowstep definition controller will compute the complexity score of the parameter schema and record it in the status and labels")
+	flag.StringVar(&controllerArgs.StepDefinitionSchemaNotifyConfigMap, "step-definition-schema-notify-configmap", "", "The name of the ConfigMap in the system definition namespace which will be bumped on every workflowstep definition schema change, downstream controllers can watch it to pre-populate their caches. Empty means disabled")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckReservedNames, "step-definition-check-reserved-names", false, "If true, workflowstep definition controller will check whether the definition name shadows a built-in step type")
+	flag.StringSliceVar(&controllerArgs.StepDefinitionReservedNames, "step-definition-reserved-names", workflowstepdefinition.DefaultReservedStepNames, "The reserved built-in step type names checked by --step-definition-check-reserved-names")
+	flag.BoolVar(&controllerArgs.StepDefinitionRejectReservedNames, "step-definition-reject-reserved-names", false, "If true, the workflowstep definition shadowing a reserved name will be rejected, otherwise it's only warned")
+	flag.BoolVar(&controllerArgs.StepDefinitionUsageSnippet, "step-definition-usage-snippet", false, "If true, workflowstep definition controller will generate a CLI usage snippet under the usage.txt key of the schema ConfigMap")
+	flag.BoolVar(&controllerArgs.StepDefinitionStrictCUE, "step-definition-strict-cue", false, "If true, workflowstep definition controller will compile the template in strict mode, which rejects the incomplete values where concrete ones are expected")
+	flag.BoolVar(&controllerArgs.StepDefinitionParamLifecycle, "step-definition-param-lifecycle", false, "If true, workflowstep definition controller will summarize the parameter lifecycle across the retained revisions under the param-lifecycle.json key of the schema ConfigMap")
+	flag.StringSliceVar(&controllerArgs.StepDefinitionRequiredAnnotations, "step-definition-required-annotations", nil, "The annotations every workflowstep definition must carry, the definition missing any of them will not be processed. Default none is required")
+	flag.StringVar(&controllerArgs.StepDefinitionSchemaIDBaseURL, "step-definition-schema-id-base-url", "", "The base URL of the $id populated in the schema of workflowstep definition, the $id is <base-url>/<name>/v<revision>. Default no $id is populated")
+	flag.BoolVar(&controllerArgs.StepDefinitionDetectDuplicates, "step-definition-detect-duplicates", false, "If true, workflowstep definition controller will report the identical definitions across namespaces via events")
+	flag.BoolVar(&controllerArgs.StepDefinitionAliasDuplicates, "step-definition-alias-duplicates", false, "If true, the duplicated workflowstep definitions found by --step-definition-detect-duplicates will be annotated with the identical one they are alias of, otherwise they are only reported")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckParameterBounds, "step-definition-check-parameter-bounds", false, "If true, workflowstep definition controller will warn on the string parameters without maxLength and the number parameters without bounds")
+	flag.IntVar(&controllerArgs.StepDefinitionMaxDescriptionLength, "step-definition-max-description-length", 0, "The max length of the parameter descriptions of workflowstep definition, the exceeding ones are warned. Default 0 means no limit")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckEnumDefaults, "step-definition-check-enum-defaults", false, "If true, workflowstep definition controller will warn on the enum parameters without a valid default value")
+	flag.BoolVar(&controllerArgs.StepDefinitionRequiredSchema, "step-definition-required-schema", false, "If true, workflowstep definition controller will generate a schema variant containing only the required parameters under the schema.required.json key of the schema ConfigMap")
+	flag.BoolVar(&controllerArgs.StepDefinitionRequireDescription, "step-definition-require-description", false, "If true, workflowstep definition controller will check the definition declares a description")
+	flag.BoolVar(&controllerArgs.StepDefinitionRejectNoDescription, "step-definition-reject-no-description", false, "If true, the workflowstep definition without a description will be rejected, otherwise it's only warned")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckNameCollisions, "step-definition-check-name-collisions", false, "If true, workflowstep definition controller will warn on the parameters whose names collide case-insensitively")
+	flag.BoolVar(&controllerArgs.StepDefinitionAsyncAPI, "step-definition-asyncapi", false, "If true, workflowstep definition controller will generate an AsyncAPI document under the asyncapi.json key of the schema ConfigMap for the definition declaring event channels")
+	flag.BoolVar(&controllerArgs.StepDefinitionSnapshot, "step-definition-snapshot", false, "If true, workflowstep definition controller will write a snapshot ConfigMap containing the definition spec, schema, revision and fingerprint")
+	flag.IntVar(&controllerArgs.StepDefinitionMaxGroupParameters, "step-definition-max-group-parameters", 0, "The max count of the parameters in a group of workflowstep definition declared by the @group attribute, the exceeding groups are warned. Default 0 means no limit")
+	flag.BoolVar(&controllerArgs.StepDefinitionAuditSchemaChanges, "step-definition-audit-schema-changes", false, "If true, workflowstep definition controller will write a structured annotation on the definition for every schema change to be captured by the audit policies")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckArrayItems, "step-definition-check-array-items", false, "If true, workflowstep definition controller will warn on the array parameters without a concrete item schema")
+	flag.BoolVar(&controllerArgs.StepDefinitionPhaseDurations, "step-definition-phase-durations", false, "If true, workflowstep definition controller will record the durations of the schema generation phases in the status and the metrics")
+	flag.BoolVar(&controllerArgs.StepDefinitionMigrationNote, "step-definition-migration-note", false, "If true, workflowstep definition controller will generate a migration note in the schema ConfigMap when the parameters change in a breaking way")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckBooleanDescriptions, "step-definition-check-boolean-descriptions", false, "If true, workflowstep definition controller will warn on the boolean parameters whose description doesn't mention the effect of both true and false")
+	flag.StringSliceVar(&controllerArgs.StepDefinitionBooleanTrueKeywords, "step-definition-boolean-true-keywords", workflowstepdefinition.DefaultBooleanTrueKeywords, "The words clarifying the true state of a boolean parameter checked by --step-definition-check-boolean-descriptions")
+	flag.StringSliceVar(&controllerArgs.StepDefinitionBooleanFalseKeywords, "step-definition-boolean-false-keywords", workflowstepdefinition.DefaultBooleanFalseKeywords, "The words clarifying the false state of a boolean parameter checked by --step-definition-check-boolean-descriptions")
+	flag.BoolVar(&controllerArgs.StepDefinitionParamDependencies, "step-definition-param-dependencies", false, "If true, workflowstep definition controller will generate the dependency graph of the parameters declared by the @dependsOn attributes in the schema ConfigMap")
+	flag.StringVar(&controllerArgs.StepDefinitionConfigMapPrefix, "step-definition-configmap-prefix", "", "The prefix of the schema ConfigMap names of workflowstep definition, e.g. for filtering and RBAC. Default empty means no prefix")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckIntegerTypes, "step-definition-check-integer-types", false, "If true, workflowstep definition controller will warn on the number parameters intended as integers, e.g. with a whole number default value")
+	flag.StringVar(&controllerArgs.StepDefinitionSchemaEventTarget, "step-definition-schema-event-target", "", "The name of the ConfigMap in the namespace of workflowstep definition which the schema change events are recorded on, it's created if missing. Default empty means the events are recorded on the definition")
+	flag.BoolVar(&controllerArgs.StepDefinitionFuzzCorpus, "step-definition-fuzz-corpus", false, "If true, workflowstep definition controller will generate a corpus of the valid and the boundary-invalid parameter sets in the schema ConfigMap")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckReferences, "step-definition-check-references", false, "If true, workflowstep definition controller will warn on the component and trait definitions referenced by the definition which cannot be resolved")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckTraitOverlap, "step-definition-check-trait-overlap", false, "If true, workflowstep definition controller will warn on the parameters whose names match the available traits, which may duplicate the trait functionality")
+	flag.BoolVar(&controllerArgs.StepDefinitionProvenance, "step-definition-provenance", false, "If true, workflowstep definition controller will propagate the source repository and commit annotations onto the schema ConfigMap, the definition revision and the status")
+	flag.BoolVar(&controllerArgs.StepDefinitionExampleApp, "step-definition-example-app", false, "If true, workflowstep definition controller will generate a minimal application using the step in the schema ConfigMap")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckIdempotency, "step-definition-check-idempotency", false, "If true, workflowstep definition controller will warn on the steps running side-effecting operations without declaring the idempotency")
+	flag.BoolVar(&controllerArgs.StepDefinitionNormalizeDefaults, "step-definition-normalize-defaults", false, "If true, workflowstep definition controller will normalize the quantity and duration defaults of the parameters to the canonical form in the generated schema")
+	flag.IntVar(&controllerArgs.StepDefinitionMaxEnumValues, "step-definition-max-enum-values", 0, "The max count of the enum values of the workflowstep definition parameters, the exceeding ones are warned. Default 0 means no limit")
+	flag.BoolVar(&controllerArgs.StepDefinitionChecksums, "step-definition-checksums", false, "If true, workflowstep definition controller will generate the SHA-256 checksum manifest of the schemas in the schema ConfigMap for the air-gapped verification")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckResourceRequirements, "step-definition-check-resource-requirements", false, "If true, workflowstep definition controller will warn on the containers run by the steps without declaring the resource requests or limits")
+	flag.BoolVar(&controllerArgs.StepDefinitionGraphQL, "step-definition-graphql", false, "If true, workflowstep definition controller will generate the GraphQL input types of the parameters in the schema ConfigMap")
+	flag.StringArrayVar(&controllerArgs.StepDefinitionForbiddenDescriptionPatterns, "step-definition-forbidden-description-pattern", nil, "The regular expression the descriptions of workflowstep definition and its parameters must not match, the matching ones are warned. It can be repeated. Default none is checked")
+	flag.StringSliceVar(&controllerArgs.StepDefinitionSchemaProfiles, "step-definition-schema-profiles", nil, "The consumer profiles the schema variants of workflowstep definition are generated for, the supported ones are ui, cli and api. Default none is generated unless the definition requests them by annotation")
+	flag.BoolVar(&controllerArgs.StepDefinitionFirstSeen, "step-definition-first-seen", false, "If true, workflowstep definition controller will record when the definition was first reconciled in its status and schema ConfigMap")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckExamples, "step-definition-check-examples", false, "If true, workflowstep definition controller will warn on the documented examples referencing the parameters not in the schema")
+	flag.StringVar(&controllerArgs.StepDefinitionExportConfigMap, "step-definition-export-configmap", "", "The name of the ConfigMap in the system definition namespace which the canonicalized workflowstep definition manifests are exported to for the drift detection against Git. Empty means disabled")
+	flag.StringVar(&controllerArgs.StepDefinitionExportDir, "step-definition-export-dir", "", "The directory, e.g. a volume synced with Git, which the canonicalized workflowstep definition manifests are exported to. Empty means disabled")
+	flag.BoolVar(&controllerArgs.StepDefinitionBreakingChangeApproval, "step-definition-breaking-change-approval", false, "If true, the workflowstep definition revision with breaking changes of the parameters will not become the latest revision until it's approved by the annotation "+oam.AnnotationApprovedRevision)
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckDurations, "step-definition-check-durations", false, "If true, workflowstep definition controller will warn on the duration parameters, by their names or the @duration() attribute, whose default or example values are not valid Go durations")
+	flag.BoolVar(&controllerArgs.StepDefinitionMockOutput, "step-definition-mock-output", false, "If true, workflowstep definition controller will generate a mock output conforming to the output schema declared by the annotation "+oam.AnnotationOutputSchema+" in the schema ConfigMap")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckSecretDefaults, "step-definition-check-secret-defaults", false, "If true, workflowstep definition controller will reject the workflowstep definition giving default values to the sensitive parameters marked by the @secret() attribute")
+	flag.BoolVar(&controllerArgs.StepDefinitionBlockAlphaInProduction, "step-definition-block-alpha-in-production", false, "If true, the application webhook will block the applications in the namespaces labeled by "+oam.LabelNamespaceProduction+"=true from using the alpha workflowstep definitions")
+	flag.DurationVar(&controllerArgs.StepDefinitionReconcileTimeout, "step-definition-reconcile-timeout", 0, "The timeout of reconciling a workflowstep definition, e.g. for the large CUE templates. Default 0 means the timeout set by --reconcile-timeout")
+	flag.StringVar(&controllerArgs.StepDefinitionEnvMapConvention, "step-definition-env-map-convention", "", "The naming convention of the env vars mapped from the workflowstep definition parameters in the schema ConfigMap, one of snake, flat or prefixed. Default empty means disabled")
+	flag.BoolVar(&controllerArgs.StepDefinitionCheckUnusedParameters, "step-definition-check-unused-parameters", false, "If true, workflowstep definition controller will warn on the parameters declared but never referenced by the template body")
+	flag.BoolVar(&controllerArgs.StepDefinitionRejectUnusedParameters, "step-definition-reject-unused-parameters", false, "If true, workflowstep definition controller will reject the workflowstep definition declaring the parameters never referenced by the template body")
+	flag.StringVar(&controllerArgs.StepDefinitionSchemaServerAddr, "step-definition-schema-server-addr", "", "The address serving the workflowstep definition schemas at /schemas/{namespace}/{name}/{revision|latest} with the ETags of the schema fingerprints, e.g. :9090. Default empty means disabled")
+	flag.StringSliceVar(&controllerArgs.StepDefinitionPropagatedMetadata, "step-definition-propagated-metadata", nil, "The label and annotation keys of the workflowstep definition copied onto its schema ConfigMap, the entries ending with / match the keys by prefix, e.g. workflow.oam.dev/. Default none is copied")
+	flag.BoolVar(&controllerArgs.StepDefinitionEnforceAdditiveOnly, "step-definition-enforce-additive-only", false, "If true, workflowstep definition controller will reject the spec changes removing or narrowing the parameters of the workflowstep definitions annotated by "+oam.AnnotationAdditiveOnly+"=true")
+	flag.IntVar(&controllerArgs.StepDefinitionStatusBackoff.Steps, "step-definition-status-retry-steps", retry.DefaultBackoff.Steps, "The max number of retries of the workflowstep definition status updates on conflict")
+	flag.DurationVar(&controllerArgs.StepDefinitionStatusBackoff.Duration, "step-definition-status-retry-duration", retry.DefaultBackoff.Duration, "The initial interval of retrying the workflowstep definition status updates on conflict")
+	flag.Float64Var(&controllerArgs.StepDefinitionStatusBackoff.Factor, "step-definition-status-retry-factor", retry.DefaultBackoff.Factor, "The factor the interval of retrying the workflowstep definition status updates is multiplied by on each retry")
+	flag.Float64Var(&controllerArgs.StepDefinitionStatusBackoff.Jitter, "step-definition-status-retry-jitter", retry.DefaultBackoff.Jitter, "The jitter of the interval of retrying the workflowstep definition status updates")
+	flag.BoolVar(&controllerArgs.StepDefinitionSweepOrphanRevisions, "step-definition-sweep-orphan-revisions", false, "If true, workflowstep definition controller will delete the definition revisions labeled with the workflowstep definition beyond definition-revision-limit which it no longer owns")
+	flag.BoolVar(&controllerArgs.StepDefinitionDryRun, "step-definition-dry-run", false, "If true, workflowstep definition controller will only log and emit the events of the definition revisions and schema configmaps it would write, without writing anything")
+	flag.StringVar(&controllerArgs.StepDefinitionSchemaNamespace, "step-definition-schema-namespace", "", "The centralized namespace where workflowstep definition controller stores the schema configmaps of the workflowstep definitions in all the namespaces, the configmaps are named with the namespaces of the definitions and linked to them by labels. Default empty means the namespace of each definition")
+	flag.BoolVar(&controllerArgs.StepDefinitionImmutableSchemas, "step-definition-immutable-schemas", false, "If true, workflowstep definition controller will store the schemas in immutable configmaps, a changed schema is stored in a new configmap named with the hash of its data and the previous ones are retained up to the definition revision limit")
+	flag.IntVar(&controllerArgs.StepDefinitionMaxSchemaSize, "step-definition-max-schema-size", utils.DefaultMaxSchemaSize, "The max size in bytes of the schema workflowstep definition controller stores in configmap, the larger schema fails the definition before writing. Raise it with the size limit of the cluster, 0 means no limit")
+	flag.StringVar(&controllerArgs.StepDefinitionConfigMapNameTemplate, "step-definition-configmap-name-template", "", "The Go template of the schema configmap names of workflowstep definition, rendered with {{.Name}}, {{.Namespace}} and {{.Revision}} which is empty for the configmap of the definition itself, e.g. team-{{.Namespace}}-{{.Name}}{{with .Revision}}-{{.}}{{end}}. Default empty means the default names")
+	flag.DurationVar(&controllerArgs.StepDefinitionDiscoveryRefreshInterval, "step-definition-discovery-refresh-interval", 0, "The min interval between the discovery mapper refreshes of workflowstep definition controller, e.g. for the definitions reconciled with unknown kinds after a restart. The concurrent refreshes are coalesced into one regardless of it. Default 0 means no min interval")
+	flag.BoolVar(&controllerArgs.StepDefinitionDisableRevisions, "step-definition-disable-revisions", false, "If true, workflowstep definition controller will not create the definition revisions, the revisions are only kept in the status of the workflowstep definitions and the schema configmaps of them, e.g. for the ephemeral environments never rolling back. It can't be enabled with step-definition-breaking-change-approval")
 	standardcontroller.AddOptimizeFlags()
 	standardcontroller.AddAdmissionFlags()
 	flag.IntVar(&resourcekeeper.MaxDispatchConcurrent, "max-dispatch-concurrent", 10, "Set the max dispatch concurrent number, default is 10")
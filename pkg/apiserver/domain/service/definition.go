@@ -237,10 +237,20 @@ func (d *definitionServiceImpl) DetailDefinition(ctx context.Context, name, defT
 	if err != nil {
 		return nil, err
 	}
+	// the schema ConfigMap may be prefixed by the controller, its name is referred by the status
+	cmName := fmt.Sprintf("%s-schema-%s", defType, name)
+	if ref, _, _ := unstructured.NestedString(def.Object, "status", "configMapRef"); ref != "" {
+		cmName = ref
+	}
+	// the schema ConfigMap may be stored in a centralized namespace, which is referred along with the name
+	cmNamespace := types.DefaultKubeVelaNS
+	if ns, _, _ := unstructured.NestedString(def.Object, "status", "configMapNamespace"); ns != "" {
+		cmNamespace = ns
+	}
 	var cm v1.ConfigMap
 	if err := d.KubeClient.Get(ctx, k8stypes.NamespacedName{
-		Namespace: types.DefaultKubeVelaNS,
-		Name:      fmt.Sprintf("%s-schema-%s", defType, name),
+		Namespace: cmNamespace,
+		Name:      cmName,
 	}, &cm); err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
@@ -192,6 +192,38 @@ var _ = Describe("Test namespace service functions", func() {
 		Expect(definitionDetail.WorkflowStep).ShouldNot(BeNil())
 	})
 
+	It("Test DetailDefinition function with the schema ConfigMap in a centralized namespace", func() {
+		webserver, err := ioutil.ReadFile("./testdata/apply-object.yaml")
+		Expect(err).Should(Succeed())
+		var cd v1beta1.WorkflowStepDefinition
+		Expect(yaml.Unmarshal(webserver, &cd)).Should(Succeed())
+		cd.SetName("centralized-apply-object")
+		Expect(k8sClient.Create(context.Background(), &cd)).Should(Succeed())
+		cd.Status.ConfigMapRef = "default-workflowstep-schema-centralized-apply-object"
+		cd.Status.ConfigMapNamespace = "vela-schemas"
+		Expect(k8sClient.Status().Update(context.Background(), &cd)).Should(Succeed())
+
+		Expect(k8sClient.Create(context.Background(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "vela-schemas"},
+		})).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default-workflowstep-schema-centralized-apply-object",
+				Namespace: "vela-schemas",
+			},
+			Data: map[string]string{
+				types.OpenapiV3JSONSchema: `{"properties":{"cluster":{"title":"cluster","type":"string"}},"type":"object"}`,
+			},
+		}
+		Expect(k8sClient.Create(context.Background(), cm)).Should(Succeed())
+		definitionDetail, err := definitionService.DetailDefinition(context.TODO(), "centralized-apply-object", "workflowstep")
+		Expect(err).Should(Succeed())
+
+		schemaFromCM := &openapi3.Schema{}
+		Expect(schemaFromCM.UnmarshalJSON([]byte(cm.Data[types.OpenapiV3JSONSchema]))).Should(Succeed())
+		Expect(definitionDetail.APISchema).Should(Equal(schemaFromCM))
+	})
+
 	It("Test renderDefaultUISchema", func() {
 		schema := &v1.DetailDefinitionResponse{}
 		data, err := ioutil.ReadFile("./testdata/api-schema.json")
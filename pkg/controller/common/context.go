@@ -37,3 +37,16 @@ var (
 func NewReconcileContext(ctx context.Context) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(ctx, ReconcileTimeout)
 }
+
+// NewReconcileContextWithTimeout create context with the given timeout, the default timeout is used if it's not positive
+func NewReconcileContextWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, ReconcileTimeoutOrDefault(timeout))
+}
+
+// ReconcileTimeoutOrDefault returns the timeout if it's positive, otherwise the default timeout
+func ReconcileTimeoutOrDefault(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return ReconcileTimeout
+	}
+	return timeout
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core_oam_dev
+
+import "context"
+
+// CatalogEntry is the entry of a definition in the catalog search index
+type CatalogEntry struct {
+	Namespace   string
+	Name        string
+	Description string
+	// Tags are the labels of the definition
+	Tags map[string]string
+	// Parameters are the paths of all the parameters of the definition, the nested ones included
+	Parameters []string
+}
+
+// CatalogIndexer pushes the definitions to a catalog search index, e.g. Elasticsearch or Bleve.
+// It's invoked when the entry of a definition changes, the failed ones will be retried.
+type CatalogIndexer interface {
+	Index(ctx context.Context, entry CatalogEntry) error
+}
+
+// NopCatalogIndexer is the CatalogIndexer doing nothing
+type NopCatalogIndexer struct{}
+
+// Index does nothing
+func (NopCatalogIndexer) Index(_ context.Context, _ CatalogEntry) error {
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/kubevela/workflow/pkg/cue/packages"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 )
@@ -90,4 +91,283 @@ type Args struct {
 
 	// IgnoreDefinitionWithoutControllerRequirement indicates that trait/component/workflowstep definition controller will not process the definition without 'definition.oam.dev/controller-version-require' annotation.
 	IgnoreDefinitionWithoutControllerRequirement bool
+
+	// StepDefinitionComplexityScore indicates that workflowstep definition controller will compute the complexity score of the parameter schema.
+	StepDefinitionComplexityScore bool
+
+	// StepDefinitionSchemaNotifyConfigMap is the name of the ConfigMap in the system definition namespace which workflowstep definition controller bumps
+	// on every schema change, so that downstream controllers can watch it to pre-populate their caches. Empty means disabled.
+	StepDefinitionSchemaNotifyConfigMap string
+
+	// StepDefinitionCheckReservedNames indicates that workflowstep definition controller will check whether the definition shadows a built-in step type.
+	StepDefinitionCheckReservedNames bool
+
+	// StepDefinitionReservedNames are the reserved built-in step type names, the default built-in step types are used if it's empty.
+	StepDefinitionReservedNames []string
+
+	// StepDefinitionRejectReservedNames indicates that the WorkflowStepDefinition which shadows a reserved name will be rejected rather than warned.
+	StepDefinitionRejectReservedNames bool
+
+	// StepDefinitionUsageSnippet indicates that workflowstep definition controller will generate a CLI usage snippet in the schema ConfigMap.
+	StepDefinitionUsageSnippet bool
+
+	// StepDefinitionStrictCUE indicates that workflowstep definition controller will compile the template in strict mode,
+	// which evaluates every field and rejects the incomplete values where concrete ones are expected.
+	StepDefinitionStrictCUE bool
+
+	// StepDefinitionParamLifecycle indicates that workflowstep definition controller will summarize the stable, new and removed
+	// parameters across the retained revisions in the schema ConfigMap.
+	StepDefinitionParamLifecycle bool
+
+	// StepDefinitionRequiredAnnotations are the annotations every workflowstep definition must carry, e.g. the license or
+	// ownership header, the definition missing any of them will not be processed.
+	StepDefinitionRequiredAnnotations []string
+
+	// StepDefinitionSchemaIDBaseURL is the base URL of the `$id` populated in the schema of workflowstep definition,
+	// the `$id` is the base URL plus the definition name and revision. Empty means no `$id` is populated.
+	StepDefinitionSchemaIDBaseURL string
+
+	// StepDefinitionDetectDuplicates indicates that workflowstep definition controller will report the identical definitions
+	// across namespaces as a dedup opportunity via events.
+	StepDefinitionDetectDuplicates bool
+
+	// StepDefinitionAliasDuplicates indicates that workflowstep definition controller will annotate the duplicated definitions
+	// found by StepDefinitionDetectDuplicates with the identical one they are alias of.
+	StepDefinitionAliasDuplicates bool
+
+	// StepDefinitionCheckParameterBounds indicates that workflowstep definition controller will warn on the unbounded parameters,
+	// i.e. the string parameters without maxLength and the number parameters without minimum or maximum.
+	StepDefinitionCheckParameterBounds bool
+
+	// StepDefinitionCatalogIndexer is invoked by workflowstep definition controller to index the definition in the catalog
+	// on its entry change. The default value is NopCatalogIndexer.
+	StepDefinitionCatalogIndexer CatalogIndexer
+
+	// StepDefinitionMaxDescriptionLength is the max length of the parameter descriptions of workflowstep definition,
+	// the exceeding ones are warned. The default value is 0, which means no limit.
+	StepDefinitionMaxDescriptionLength int
+
+	// StepDefinitionCheckEnumDefaults indicates that workflowstep definition controller will warn on the enum parameters
+	// without a default value or whose default value is not one of the enum values.
+	StepDefinitionCheckEnumDefaults bool
+
+	// StepDefinitionRequiredSchema indicates that workflowstep definition controller will generate a schema variant
+	// containing only the required parameters in the schema ConfigMap.
+	StepDefinitionRequiredSchema bool
+
+	// StepDefinitionRequireDescription indicates that workflowstep definition controller will warn on the definition
+	// without the description annotation.
+	StepDefinitionRequireDescription bool
+
+	// StepDefinitionRejectNoDescription indicates that the workflowstep definition without the description annotation
+	// will be rejected rather than warned when StepDefinitionRequireDescription is set.
+	StepDefinitionRejectNoDescription bool
+
+	// StepDefinitionCheckNameCollisions indicates that workflowstep definition controller will warn on the parameters
+	// whose names collide case-insensitively.
+	StepDefinitionCheckNameCollisions bool
+
+	// StepDefinitionAsyncAPI indicates that workflowstep definition controller will generate an AsyncAPI document for
+	// the definition declaring event channels in the schema ConfigMap.
+	StepDefinitionAsyncAPI bool
+
+	// StepDefinitionSnapshot indicates that workflowstep definition controller will write a consolidated snapshot ConfigMap
+	// containing the definition spec, schema, revision and fingerprint for the backup and migration tools.
+	StepDefinitionSnapshot bool
+
+	// StepDefinitionMaxGroupParameters is the max count of the parameters in a group of workflowstep definition, which is
+	// declared by the @group attribute, the exceeding groups are warned. The default value is 0, which means no limit.
+	StepDefinitionMaxGroupParameters int
+
+	// StepDefinitionAuditSchemaChanges indicates that workflowstep definition controller will write a structured annotation
+	// on the definition for every schema change, which is designed to be captured by the audit policies.
+	StepDefinitionAuditSchemaChanges bool
+
+	// StepDefinitionCheckArrayItems indicates that workflowstep definition controller will warn on the array parameters
+	// without a concrete item schema.
+	StepDefinitionCheckArrayItems bool
+
+	// StepDefinitionPhaseDurations records the durations of the schema generation phases of the WorkflowStepDefinition in
+	// its status and the metrics
+	StepDefinitionPhaseDurations bool
+
+	// StepDefinitionMigrationNote generates the migration note of the breaking parameter changes in the schema ConfigMap
+	// of the WorkflowStepDefinition
+	StepDefinitionMigrationNote bool
+
+	// StepDefinitionCheckBooleanDescriptions indicates that workflowstep definition controller will warn on the boolean
+	// parameters whose description doesn't clarify the effect of both true and false
+	StepDefinitionCheckBooleanDescriptions bool
+
+	// StepDefinitionBooleanTrueKeywords are the words clarifying the true state of a boolean parameter, the default ones are used if it's empty.
+	StepDefinitionBooleanTrueKeywords []string
+
+	// StepDefinitionBooleanFalseKeywords are the words clarifying the false state of a boolean parameter, the default ones are used if it's empty.
+	StepDefinitionBooleanFalseKeywords []string
+
+	// StepDefinitionParamDependencies generates the dependency graph of the parameters declared by the @dependsOn attributes
+	// in the schema ConfigMap of the WorkflowStepDefinition
+	StepDefinitionParamDependencies bool
+
+	// StepDefinitionConfigMapPrefix is prepended to the names of the schema ConfigMaps of the WorkflowStepDefinitions
+	StepDefinitionConfigMapPrefix string
+
+	// StepDefinitionCheckIntegerTypes indicates that workflowstep definition controller will warn on the number parameters
+	// intended as integers
+	StepDefinitionCheckIntegerTypes bool
+
+	// StepDefinitionSchemaEventTarget is the name of the ConfigMap in the namespace of the WorkflowStepDefinition which the
+	// schema change events are recorded on, the events are recorded on the WorkflowStepDefinition if it's empty
+	StepDefinitionSchemaEventTarget string
+
+	// StepDefinitionFuzzCorpus generates the corpus of the valid and the boundary-invalid parameter sets in the schema
+	// ConfigMap of the WorkflowStepDefinition
+	StepDefinitionFuzzCorpus bool
+
+	// StepDefinitionCheckReferences indicates that workflowstep definition controller will warn on the referenced component
+	// and trait definitions which cannot be resolved
+	StepDefinitionCheckReferences bool
+
+	// StepDefinitionCheckTraitOverlap indicates that workflowstep definition controller will warn on the parameters whose
+	// names match the available traits
+	StepDefinitionCheckTraitOverlap bool
+
+	// StepDefinitionProvenance propagates the source repository and commit annotations of the WorkflowStepDefinition onto
+	// its schema ConfigMap, DefinitionRevision and status
+	StepDefinitionProvenance bool
+
+	// StepDefinitionExampleApp generates a minimal Application using the WorkflowStepDefinition in its schema ConfigMap
+	StepDefinitionExampleApp bool
+
+	// StepDefinitionCheckIdempotency indicates that workflowstep definition controller will warn on the steps running
+	// side-effecting operations without declaring the idempotency
+	StepDefinitionCheckIdempotency bool
+
+	// StepDefinitionNormalizeDefaults normalizes the quantity and duration defaults of the parameters to the canonical
+	// form in the generated schema of WorkflowStepDefinition
+	StepDefinitionNormalizeDefaults bool
+
+	// StepDefinitionMaxEnumValues is the max count of the enum values of the workflowstep definition parameters,
+	// the exceeding ones are warned. Default 0 means no limit
+	StepDefinitionMaxEnumValues int
+
+	// StepDefinitionChecksums generates the checksum manifest of the schemas in the schema ConfigMap of
+	// WorkflowStepDefinition
+	StepDefinitionChecksums bool
+
+	// StepDefinitionCheckResourceRequirements indicates that workflowstep definition controller will warn on the
+	// containers run by the steps without declaring the resource requirements
+	StepDefinitionCheckResourceRequirements bool
+
+	// StepDefinitionGraphQL generates the GraphQL input types of the parameters in the schema ConfigMap of
+	// WorkflowStepDefinition
+	StepDefinitionGraphQL bool
+
+	// StepDefinitionForbiddenDescriptionPatterns are the regular expressions the descriptions of workflowstep definition
+	// and its parameters must not match, e.g. the secrets or the internal hostnames. Default none is checked
+	StepDefinitionForbiddenDescriptionPatterns []string
+
+	// StepDefinitionSchemaProfiles are the consumer profiles the schema variants of WorkflowStepDefinition are generated
+	// for, e.g. ui, cli or api. Default none is generated unless the definition requests them by annotation
+	StepDefinitionSchemaProfiles []string
+
+	// StepDefinitionFirstSeen records when the WorkflowStepDefinition was first reconciled in its status and schema ConfigMap
+	StepDefinitionFirstSeen bool
+
+	// StepDefinitionCheckExamples indicates that workflowstep definition controller will warn on the documented examples
+	// referencing the parameters not in the schema
+	StepDefinitionCheckExamples bool
+
+	// StepDefinitionExportConfigMap is the name of the ConfigMap in the system definition namespace which the canonicalized
+	// WorkflowStepDefinition manifests are exported to for the drift detection against Git. Empty means disabled
+	StepDefinitionExportConfigMap string
+
+	// StepDefinitionExportDir is the directory, e.g. a volume synced with Git, which the canonicalized WorkflowStepDefinition
+	// manifests are exported to. Empty means disabled
+	StepDefinitionExportDir string
+
+	// StepDefinitionBreakingChangeApproval indicates that the WorkflowStepDefinition revision with breaking changes of the
+	// parameters doesn't become the latest revision until it's approved by annotation
+	StepDefinitionBreakingChangeApproval bool
+
+	// StepDefinitionCheckDurations indicates that workflowstep definition controller will warn on the duration parameters
+	// whose default or example values are not valid Go durations
+	StepDefinitionCheckDurations bool
+
+	// StepDefinitionMockOutput generates a mock output conforming to the output schema declared by the WorkflowStepDefinition
+	// in its schema ConfigMap for the testing frameworks
+	StepDefinitionMockOutput bool
+
+	// StepDefinitionCheckSecretDefaults indicates that workflowstep definition controller will reject the WorkflowStepDefinition
+	// giving default values to the sensitive parameters marked by the @secret() attribute
+	StepDefinitionCheckSecretDefaults bool
+
+	// StepDefinitionBlockAlphaInProduction blocks the Applications in the namespaces labeled as production from using the
+	// WorkflowStepDefinitions declared as alpha
+	StepDefinitionBlockAlphaInProduction bool
+
+	// StepDefinitionReconcileTimeout is the timeout of reconciling a WorkflowStepDefinition, e.g. rendering the large CUE
+	// templates against a slow apiserver. Zero means the default reconcile timeout
+	StepDefinitionReconcileTimeout time.Duration
+
+	// StepDefinitionEnvMapConvention is the naming convention of the env vars mapped from the WorkflowStepDefinition
+	// parameters in its schema ConfigMap, one of snake, flat or prefixed. Empty means disabled
+	StepDefinitionEnvMapConvention string
+
+	// StepDefinitionCheckUnusedParameters indicates that workflowstep definition controller will warn on the parameters
+	// declared but never referenced by the template body
+	StepDefinitionCheckUnusedParameters bool
+
+	// StepDefinitionRejectUnusedParameters indicates that workflowstep definition controller will reject the
+	// WorkflowStepDefinition declaring the parameters never referenced by the template body
+	StepDefinitionRejectUnusedParameters bool
+
+	// StepDefinitionSchemaServerAddr is the address serving the schemas of the WorkflowStepDefinitions at the
+	// deterministic paths /schemas/{namespace}/{name}/{revision|latest}. Empty means disabled
+	StepDefinitionSchemaServerAddr string
+
+	// StepDefinitionPropagatedMetadata are the label and annotation keys of the WorkflowStepDefinition copied onto its
+	// schema ConfigMap, the entries ending with `/` match the keys by prefix, e.g. workflow.oam.dev/
+	StepDefinitionPropagatedMetadata []string
+
+	// StepDefinitionEnforceAdditiveOnly indicates that workflowstep definition controller will reject the spec changes
+	// removing or narrowing the parameters of the WorkflowStepDefinitions annotated as additive-only
+	StepDefinitionEnforceAdditiveOnly bool
+
+	// StepDefinitionStatusBackoff is the backoff of retrying the WorkflowStepDefinition status updates on conflict, the
+	// zero value means retry.DefaultBackoff
+	StepDefinitionStatusBackoff wait.Backoff
+
+	// StepDefinitionSweepOrphanRevisions indicates that workflowstep definition controller will delete the
+	// DefinitionRevisions labeled with the WorkflowStepDefinition beyond the revision limit which it no longer owns
+	StepDefinitionSweepOrphanRevisions bool
+
+	// StepDefinitionDryRun indicates that workflowstep definition controller will only report the DefinitionRevisions
+	// and the schema ConfigMaps it would write, without writing anything
+	StepDefinitionDryRun bool
+
+	// StepDefinitionSchemaNamespace is the namespace where workflowstep definition controller stores the schema
+	// ConfigMaps of the WorkflowStepDefinitions in all the namespaces, empty means the namespace of each definition
+	StepDefinitionSchemaNamespace string
+
+	// StepDefinitionImmutableSchemas indicates that workflowstep definition controller will store the schemas in the
+	// immutable ConfigMaps, a changed schema is stored in a new ConfigMap named after the hash of its data
+	StepDefinitionImmutableSchemas bool
+
+	// StepDefinitionMaxSchemaSize is the max size in bytes of the schema workflowstep definition controller stores in
+	// the ConfigMap, the larger schema fails the definition before writing, 0 means no limit
+	StepDefinitionMaxSchemaSize int
+
+	// StepDefinitionConfigMapNameTemplate is the Go template rendering the names of the schema ConfigMaps of the
+	// WorkflowStepDefinitions from the definition name, namespace and revision, empty means the default names
+	StepDefinitionConfigMapNameTemplate string
+
+	// StepDefinitionDiscoveryRefreshInterval is the min interval between the DiscoveryMapper refreshes of workflowstep
+	// definition controller, the concurrent refreshes are coalesced into one regardless of it, 0 means no limit
+	StepDefinitionDiscoveryRefreshInterval time.Duration
+
+	// StepDefinitionDisableRevisions indicates that workflowstep definition controller will keep the revisions of the
+	// WorkflowStepDefinitions in their status only without persisting the DefinitionRevisions, e.g. in the ephemeral
+	// environments never rolling back
+	StepDefinitionDisableRevisions bool
 }
@@ -17,15 +17,21 @@ limitations under the License.
 package core
 
 import (
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
-// MatchControllerRequirement check the requirement
+// MatchControllerRequirement check the requirement, the requirement is either a semver range, e.g. ">=1.8.0 <2.0.0",
+// or the exact controller version
 func MatchControllerRequirement(definition util.ConditionedObject, controllerVersion string, ignoreDefNoCtrlReq bool) bool {
 	if definition.GetAnnotations() != nil {
 		if requireVersion, ok := definition.GetAnnotations()[oam.AnnotationControllerRequirement]; ok {
-			return requireVersion == controllerVersion
+			return matchVersionRequirement(requireVersion, controllerVersion)
 		}
 	}
 	if ignoreDefNoCtrlReq {
@@ -33,3 +39,94 @@ func MatchControllerRequirement(definition util.ConditionedObject, controllerVer
 	}
 	return true
 }
+
+var (
+	// hyphenRangePattern matches the hyphen ranges e.g. "1.2 - 1.4" of a semver range
+	hyphenRangePattern = regexp.MustCompile(`(\S+)\s+-\s+(\S+)`)
+	// operatorSpacePattern matches the spaces between the operators and the versions of a semver range
+	operatorSpacePattern = regexp.MustCompile(`(>=|<=|!=|~>|=|<|>|~|\^)\s+`)
+	// comparisonPattern splits a comparison of a semver range into its operator and version
+	comparisonPattern = regexp.MustCompile(`^(>=|<=|!=|~>|=|<|>|~|\^)?\s*v?(.*)$`)
+)
+
+// matchVersionRequirement checks the controller version against the requirement, the requirement not being a valid
+// semver range is compared to the version literally. A pre-release version is checked as its release version, except
+// that it sorts before its release, e.g. v1.9.0-beta.2 is picked by ">=1.8.0 <2.0.0" and "<1.9.0" but not by
+// ">=1.9.0" or "~1.9", unless the range constrains the pre-releases itself
+func matchVersionRequirement(requireVersion, controllerVersion string) bool {
+	if requireVersion == controllerVersion {
+		return true
+	}
+	constraint, err := semver.NewConstraint(requireVersion)
+	if err != nil {
+		return false
+	}
+	version, err := semver.NewVersion(controllerVersion)
+	if err != nil {
+		return false
+	}
+	if version.Prerelease() == "" || hasPrerelease(requireVersion) {
+		return constraint.Check(version)
+	}
+	release, err := version.SetPrerelease("")
+	if err != nil {
+		return false
+	}
+	for _, group := range strings.Split(requireVersion, "||") {
+		if matchPrereleaseComparisons(group, &release) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrereleaseComparisons checks a pre-release version by its release version against the comparisons of a range
+// joined by "and". The pre-release only differs from its release on the comparisons whose version is the release:
+// it's less than the release, so it matches "<", "<=" and "!=" but none of the others.
+func matchPrereleaseComparisons(group string, release *semver.Version) bool {
+	group = hyphenRangePattern.ReplaceAllString(strings.ReplaceAll(group, ",", " "), ">=$1 <=$2")
+	group = operatorSpacePattern.ReplaceAllString(group, "$1")
+	for _, comparison := range strings.Fields(group) {
+		constraint, err := semver.NewConstraint(comparison)
+		if err != nil {
+			return false
+		}
+		matches := comparisonPattern.FindStringSubmatch(comparison)
+		if bound, ok := comparisonBound(matches[2]); ok && bound.Equal(release) {
+			if op := matches[1]; op != "<" && op != "<=" && op != "!=" {
+				return false
+			}
+			continue
+		}
+		if !constraint.Check(release) {
+			return false
+		}
+	}
+	return true
+}
+
+// comparisonBound returns the version of the comparison with its wildcards e.g. "1.x" zeroed, the bare wildcard has no
+// bound
+func comparisonBound(version string) (*semver.Version, bool) {
+	var segments []string
+	for _, segment := range strings.Split(version, ".") {
+		if segment == "x" || segment == "X" || segment == "*" {
+			break
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return nil, false
+	}
+	bound, err := semver.NewVersion(strings.Join(segments, "."))
+	if err != nil {
+		return nil, false
+	}
+	return bound, true
+}
+
+// hasPrerelease returns whether the versions of the semver range have pre-releases, the hyphens of the hyphen ranges
+// e.g. "1.2 - 1.4" are not taken as pre-releases
+func hasPrerelease(requireVersion string) bool {
+	return strings.Contains(strings.ReplaceAll(requireVersion, " - ", " "), "-")
+}
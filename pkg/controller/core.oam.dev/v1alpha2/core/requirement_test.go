@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestMatchControllerRequirement(t *testing.T) {
+	testCases := map[string]struct {
+		requirement        *string
+		controllerVersion  string
+		ignoreDefNoCtrlReq bool
+		match              bool
+	}{
+		"no requirement": {
+			controllerVersion: "v1.9.0",
+			match:             true,
+		},
+		"no requirement ignored": {
+			controllerVersion:  "v1.9.0",
+			ignoreDefNoCtrlReq: true,
+			match:              false,
+		},
+		"exact version": {
+			requirement:       pointer.String("v1.9.0"),
+			controllerVersion: "v1.9.0",
+			match:             true,
+		},
+		"exact version mismatch": {
+			requirement:       pointer.String("v1.9.0"),
+			controllerVersion: "v1.9.1",
+			match:             false,
+		},
+		"non-semver requirement": {
+			requirement:       pointer.String("canary"),
+			controllerVersion: "canary",
+			match:             true,
+		},
+		"non-semver requirement mismatch": {
+			requirement:       pointer.String("canary"),
+			controllerVersion: "v1.9.0",
+			match:             false,
+		},
+		"non-semver controller version": {
+			requirement:       pointer.String(">=1.8.0"),
+			controllerVersion: "UNKNOWN",
+			match:             false,
+		},
+		"in range": {
+			requirement:       pointer.String(">=1.8.0 <2.0.0"),
+			controllerVersion: "v1.9.0",
+			match:             true,
+		},
+		"out of range": {
+			requirement:       pointer.String(">=1.8.0 <2.0.0"),
+			controllerVersion: "v2.0.0",
+			match:             false,
+		},
+		"pre-release in range": {
+			requirement:       pointer.String(">=1.8.0 <2.0.0"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             true,
+		},
+		"pre-release out of range": {
+			requirement:       pointer.String(">=1.8.0 <2.0.0"),
+			controllerVersion: "v1.7.0-beta.2",
+			match:             false,
+		},
+		"pre-release before its release": {
+			requirement:       pointer.String("<1.9.0"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             true,
+		},
+		"pre-release not reaching its release": {
+			requirement:       pointer.String(">=1.9.0"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             false,
+		},
+		"pre-release in wildcard": {
+			requirement:       pointer.String("*"),
+			controllerVersion: "v1.9.0-rc.1",
+			match:             true,
+		},
+		"pre-release not reaching partial lower bound": {
+			requirement:       pointer.String(">=1.9"),
+			controllerVersion: "v1.9.0-rc.1",
+			match:             false,
+		},
+		"pre-release above partial lower bound": {
+			requirement:       pointer.String(">= 1.9"),
+			controllerVersion: "v1.9.1-rc.1",
+			match:             true,
+		},
+		"pre-release in x-range": {
+			requirement:       pointer.String("1.x"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             true,
+		},
+		"pre-release not reaching x-range": {
+			requirement:       pointer.String("1.x"),
+			controllerVersion: "v1.0.0-beta.2",
+			match:             false,
+		},
+		"pre-release not reaching tilde range": {
+			requirement:       pointer.String("~1.9"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             false,
+		},
+		"pre-release in tilde range": {
+			requirement:       pointer.String("~1.9"),
+			controllerVersion: "v1.9.2-rc.1",
+			match:             true,
+		},
+		"pre-release in either range": {
+			requirement:       pointer.String(">=2.0.0 || <1.9.0"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             true,
+		},
+		"pre-release range": {
+			requirement:       pointer.String(">=1.9.0-beta.1"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             true,
+		},
+		"pre-release range mismatch": {
+			requirement:       pointer.String(">=1.9.0-beta.3"),
+			controllerVersion: "v1.9.0-beta.2",
+			match:             false,
+		},
+		"hyphen range": {
+			requirement:       pointer.String("1.8 - 1.9"),
+			controllerVersion: "v1.9.0-alpha.1",
+			match:             true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			def := &v1beta1.WorkflowStepDefinition{ObjectMeta: metav1.ObjectMeta{Name: "apply-job"}}
+			if tc.requirement != nil {
+				def.SetAnnotations(map[string]string{oam.AnnotationControllerRequirement: *tc.requirement})
+			}
+			require.Equal(t, tc.match, MatchControllerRequirement(def, tc.controllerVersion, tc.ignoreDefNoCtrlReq))
+		})
+	}
+}
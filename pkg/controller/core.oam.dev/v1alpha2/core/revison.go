@@ -298,7 +298,7 @@ func CleanUpDefinitionRevision(ctx context.Context, cli client.Client, def runti
 	if needKill <= 0 {
 		return nil
 	}
-	klog.InfoS("cleanup old definitionRevision", "needKillNum", needKill)
+	klog.FromContext(ctx).Info("cleanup old definitionRevision", "needKillNum", needKill)
 
 	sortedRevision := defRevList.Items
 	sort.Sort(historiesByRevision(sortedRevision))
@@ -338,7 +338,7 @@ func ReconcileDefinitionRevision(ctx context.Context,
 	// generate DefinitionRevision from componentDefinition
 	defRev, isNewRevision, err := GenerateDefinitionRevision(ctx, cli, definition)
 	if err != nil {
-		klog.ErrorS(err, "Could not generate DefinitionRevision", "componentDefinition", klog.KObj(definition))
+		klog.FromContext(ctx).Error(err, "Could not generate DefinitionRevision", "componentDefinition", klog.KObj(definition))
 		record.Event(definition, event.Warning("Could not generate DefinitionRevision", err))
 		return nil, &ctrl.Result{}, util.PatchCondition(ctx, cli, definition,
 			condition.ReconcileError(fmt.Errorf(util.ErrGenerateDefinitionRevision, definition.GetName(), err)))
@@ -346,29 +346,29 @@ func ReconcileDefinitionRevision(ctx context.Context,
 
 	if isNewRevision {
 		if err := CreateDefinitionRevision(ctx, cli, definition, defRev.DeepCopy()); err != nil {
-			klog.ErrorS(err, "Could not create DefinitionRevision")
+			klog.FromContext(ctx).Error(err, "Could not create DefinitionRevision")
 			record.Event(definition, event.Warning("cannot create DefinitionRevision", err))
 			return nil, &ctrl.Result{}, util.PatchCondition(ctx, cli, definition,
 				condition.ReconcileError(fmt.Errorf(util.ErrCreateDefinitionRevision, defRev.Name, err)))
 		}
-		klog.InfoS("Successfully created definitionRevision", "definitionRevision", klog.KObj(defRev))
+		klog.FromContext(ctx).Info("Successfully created definitionRevision", "definitionRevision", klog.KObj(defRev))
 
 		if err := updateLatestRevision(&common.Revision{
 			Name:         defRev.Name,
 			Revision:     defRev.Spec.Revision,
 			RevisionHash: defRev.Spec.RevisionHash,
 		}); err != nil {
-			klog.ErrorS(err, "Could not update Definition Status")
+			klog.FromContext(ctx).Error(err, "Could not update Definition Status")
 			record.Event(definition, event.Warning("cannot update the definition status", err))
 			return nil, &ctrl.Result{}, util.PatchCondition(ctx, cli, definition,
 				condition.ReconcileError(fmt.Errorf(util.ErrUpdateComponentDefinition, definition.GetName(), err)))
 		}
-		klog.InfoS("Successfully updated the status.latestRevision of the definition", "Definition", klog.KRef(definition.GetNamespace(), definition.GetName()),
+		klog.FromContext(ctx).Info("Successfully updated the status.latestRevision of the definition", "Definition", klog.KRef(definition.GetNamespace(), definition.GetName()),
 			"Name", defRev.Name, "Revision", defRev.Spec.Revision, "RevisionHash", defRev.Spec.RevisionHash)
 	}
 
 	if err = CleanUpDefinitionRevision(ctx, cli, definition, revisionLimit); err != nil {
-		klog.InfoS("Failed to collect garbage", "err", err)
+		klog.FromContext(ctx).Info("Failed to collect garbage", "err", err)
 		record.Event(definition, event.Warning("failed to garbage collect DefinitionRevision of type ComponentDefinition", err))
 	}
 	return defRev, nil, nil
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// checkAdditiveOnly rejects the spec change of the definition annotated by oam.AnnotationAdditiveOnly if its parameter
+// schema removes or narrows any parameter of the latest revision, the changes are reported by the compatibility checker
+func (r *Reconciler) checkAdditiveOnly(ctx context.Context, def *v1beta1.WorkflowStepDefinition, schema *openapi3.Schema) error {
+	latest := def.Status.LatestRevision
+	if def.GetAnnotations()[oam.AnnotationAdditiveOnly] != "true" || latest == nil || schema == nil {
+		return nil
+	}
+	latestRev := &v1beta1.DefinitionRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: latest.Name}, latestRev); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	latestSchema, err := revisionSchema(latestRev)
+	if err != nil {
+		return err
+	}
+	changes := checkCompatibility(latestSchema, schema)
+	if len(changes) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(changes))
+	for _, change := range changes {
+		messages = append(messages, change.String())
+	}
+	return fmt.Errorf("the definition is annotated by %s but the spec change %s against the latest revision %s",
+		oam.AnnotationAdditiveOnly, strings.Join(messages, ", "), latest.Name)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition additive schema changes", func() {
+	It("Enforce additive only", func() {
+		ctx := context.Background()
+		def := newTestDefinition("additive", `
+parameter: {
+	name:  string
+	image: string
+}
+`)
+		def.SetAnnotations(map[string]string{oam.AnnotationAdditiveOnly: "true"})
+		r := newTestReconciler(options{enforceAdditiveOnly: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("additive-v1"))
+
+		update := func(template string) {
+			got.Spec.Schematic.CUE.Template = template
+			Expect(r.Update(ctx, got)).Should(Succeed())
+			got = reconcileTestDefinition(r, got)
+		}
+
+		// adding an optional parameter is additive
+		update(`
+parameter: {
+	name:  string
+	image: string
+	tag?:  string
+}
+`)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("additive-v2"))
+
+		// removing a parameter is rejected
+		update(`
+parameter: {
+	name: string
+	tag?: string
+}
+`)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("additive-v2"))
+		cond := got.GetCondition(condition.TypeSynced)
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Message).Should(ContainSubstring("the spec change removed image against the latest revision additive-v2"))
+
+		// the definition without the annotation evolves freely
+		got.SetAnnotations(nil)
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("additive-v3"))
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// String describes the breaking change, e.g. `renamed image to images`
+func (c breakingChange) String() string {
+	switch c.Kind {
+	case breakingChangeRenamed:
+		return fmt.Sprintf("renamed %s to %s", c.Path, c.NewPath)
+	case breakingChangeTypeChanged:
+		return fmt.Sprintf("changed the type of %s from %s to %s", c.Path, c.OldType, c.NewType)
+	case breakingChangeRequired:
+		return fmt.Sprintf("required %s", c.Path)
+	default:
+		return fmt.Sprintf("removed %s", c.Path)
+	}
+}
+
+// awaitsApproval checks whether the new revision has breaking changes against the previous one and isn't approved by
+// the annotation yet, such revision is created but doesn't become the latest revision until it's approved
+func (r *Reconciler) awaitsApproval(ctx context.Context, def *v1beta1.WorkflowStepDefinition, revision *common.Revision) (bool, error) {
+	if def.Status.LatestRevision == nil || def.GetAnnotations()[oam.AnnotationApprovedRevision] == revision.Name {
+		return false, nil
+	}
+	defRev := &v1beta1.DefinitionRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: revision.Name}, defRev); err != nil {
+		return false, err
+	}
+	report, err := r.checkRevisionCompatibility(ctx, def, defRev)
+	if err != nil {
+		return false, err
+	}
+	if report == nil || len(report.Changes) == 0 {
+		return false, nil
+	}
+	if def.Status.PendingRevision == nil || def.Status.PendingRevision.Name != revision.Name {
+		changes := make([]string, 0, len(report.Changes))
+		for _, change := range report.Changes {
+			changes = append(changes, change.String())
+		}
+		r.recorder(ctx).Event(def, event.Warning("WorkflowStepDefinition revision pending approval",
+			fmt.Errorf("the revision %s has breaking changes: %s, set the annotation %s to %s to make it the latest revision",
+				revision.Name, strings.Join(changes, ", "), oam.AnnotationApprovedRevision, revision.Name)))
+	}
+	return true, nil
+}
+
+// dropStalePendingRevision deletes the pending revision if the spec has changed since, e.g. the breaking change is
+// fixed or reverted. The revision following the latest one is generated by the same name, it must be gone before the
+// revision of the current spec is created.
+func (r *Reconciler) dropStalePendingRevision(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	pending := def.Status.PendingRevision
+	if pending == nil {
+		return nil
+	}
+	current, _, err := coredef.GatherRevisionInfo(def)
+	if err != nil {
+		return err
+	}
+	if current.Spec.RevisionHash == pending.RevisionHash {
+		return nil
+	}
+	defRev := &v1beta1.DefinitionRevision{}
+	defRev.SetNamespace(def.Namespace)
+	defRev.SetName(pending.Name)
+	if err := r.Delete(ctx, defRev); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	reconcileLogger(ctx).Info("Deleted the stale pending revision of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "revision", pending.Name)
+	def.Status.PendingRevision = nil
+	return r.UpdateStatus(ctx, def)
+}
+
+// servedRevision returns the latest revision of the definition whose revision following it is pending the approval, and
+// rebuilds the capability from it, so that the schema ConfigMap keeps serving the approved schema meanwhile. The returned
+// definition carries the spec of the latest revision, it's only meant for generating the schema and the artifacts.
+func (r *Reconciler) servedRevision(ctx context.Context, def *v1beta1.WorkflowStepDefinition, capability *utils.CapabilityStepDefinition) (*v1beta1.DefinitionRevision, *v1beta1.WorkflowStepDefinition, error) {
+	defRev := &v1beta1.DefinitionRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: def.Status.LatestRevision.Name}, defRev); err != nil {
+		return nil, nil, err
+	}
+	served := def.DeepCopy()
+	served.Spec = *defRev.Spec.WorkflowStepDefinition.Spec.DeepCopy()
+	capability.StepDefinition = *served.DeepCopy()
+	if err := r.composeTemplate(ctx, served, capability); err != nil {
+		return nil, nil, err
+	}
+	return defRev, served, nil
+}
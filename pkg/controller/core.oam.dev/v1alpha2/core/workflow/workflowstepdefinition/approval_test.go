@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition breaking change approval", func() {
+	It("Breaking change approval", func() {
+		ctx := context.Background()
+		def := newTestDefinition("approved", `
+parameter: {
+	name:  string
+	image: string
+}
+`)
+		recorder := &recordingRecorder{}
+		r := newTestReconciler(options{holdBreakingChanges: true, schemaIDBaseURL: "https://schemas.example.com"}, def)
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("approved-v1"))
+
+		// storedSchema reads the `$id` and the parameters served by the schema ConfigMap
+		storedSchema := func() (string, []string) {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+			var schema struct {
+				ID         string                 `json:"$id"`
+				Properties map[string]interface{} `json:"properties"`
+			}
+			Expect(json.Unmarshal([]byte(cm.Data[types.OpenapiV3JSONSchema]), &schema)).Should(Succeed())
+			var params []string
+			for name := range schema.Properties {
+				params = append(params, name)
+			}
+			return schema.ID, params
+		}
+
+		update := func(template string) {
+			got.Spec.Schematic.CUE.Template = template
+			Expect(r.Update(ctx, got)).Should(Succeed())
+			got = reconcileTestDefinition(r, got)
+		}
+
+		// the non-breaking change becomes the latest revision
+		update(`
+parameter: {
+	name:  string
+	image: string
+	tag?:  string
+}
+`)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("approved-v2"))
+		Expect(got.Status.PendingRevision).Should(BeNil())
+
+		// the breaking change is pinned pending the approval
+		update(`
+parameter: {
+	name: string
+	tag?: string
+}
+`)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("approved-v2"))
+		Expect(got.Status.PendingRevision).ShouldNot(BeNil())
+		Expect(got.Status.PendingRevision.Name).Should(Equal("approved-v3"))
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: "approved-v3"}, &v1beta1.DefinitionRevision{})).Should(Succeed())
+		servedHash := got.Status.SchemaHash
+		// the schema of the latest revision is still served
+		id, params := storedSchema()
+		Expect(id).Should(Equal("https://schemas.example.com/approved/v2"))
+		Expect(params).Should(ConsistOf([]string{"name", "image", "tag"}))
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("approved-v2"))
+		Expect(got.Status.PendingRevision.Name).Should(Equal("approved-v3"))
+		Expect(got.Status.SchemaHash).Should(Equal(servedHash))
+		var pendingEvents []string
+		for _, e := range recorder.events {
+			if e.Reason == "WorkflowStepDefinition revision pending approval" {
+				pendingEvents = append(pendingEvents, e.Message)
+			}
+		}
+		Expect(pendingEvents).Should(HaveLen(1))
+		Expect(pendingEvents[0]).Should(ContainSubstring("the revision approved-v3 has breaking changes: removed image"))
+
+		// the pending revision is regenerated as the spec changes again
+		update(`
+parameter: {
+	name:   string
+	tag?:   string
+	debug?: bool
+}
+`)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("approved-v2"))
+		pending := &v1beta1.DefinitionRevision{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: "approved-v3"}, pending)).Should(Succeed())
+		Expect(pending.Spec.RevisionHash).Should(Equal(got.Status.PendingRevision.RevisionHash))
+		Expect(pending.Spec.WorkflowStepDefinition.Spec.Schematic.CUE.Template).Should(ContainSubstring("debug"))
+
+		// the approved revision becomes the latest revision
+		got.SetAnnotations(map[string]string{oam.AnnotationApprovedRevision: "approved-v3"})
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("approved-v3"))
+		Expect(got.Status.PendingRevision).Should(BeNil())
+		Expect(got.Status.SchemaHash).ShouldNot(Equal(servedHash))
+		id, params = storedSchema()
+		Expect(id).Should(Equal("https://schemas.example.com/approved/v3"))
+		Expect(params).Should(ConsistOf([]string{"name", "tag", "debug"}))
+	})
+})
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// artifactContext is the input of the artifact generators
+type artifactContext struct {
+	ctx context.Context
+	def *v1beta1.WorkflowStepDefinition
+	// schema is the rendered parameter schema and schemaData is its serialized form stored in the ConfigMap
+	schema     *openapi3.Schema
+	schemaData []byte
+	// compatibility is the result of checking the revision against the previous one, only set when the migration
+	// note is enabled
+	compatibility *compatibilityReport
+}
+
+// artifactGenerator generates the content of an extra data key stored in the schema ConfigMap
+type artifactGenerator struct {
+	key      string
+	generate func(actx *artifactContext) (string, error)
+}
+
+// artifactGenerators returns the artifact generators enabled by the options and the export formats requested by the definition
+func (r *Reconciler) artifactGenerators(actx *artifactContext) []artifactGenerator {
+	def := actx.def
+	var generators []artifactGenerator
+	if r.usageSnippet {
+		generators = append(generators, artifactGenerator{key: usageSnippetKey, generate: generateUsageSnippet})
+	}
+	if r.paramLifecycle {
+		generators = append(generators, artifactGenerator{key: paramLifecycleKey, generate: r.generateParamLifecycle})
+	}
+	if r.requiredSchema {
+		generators = append(generators, artifactGenerator{key: requiredSchemaKey, generate: generateRequiredSchema})
+	}
+	if r.asyncAPI && len(eventChannels(def)) > 0 {
+		generators = append(generators, artifactGenerator{key: asyncAPIKey, generate: generateAsyncAPI})
+	}
+	if r.exampleApp {
+		generators = append(generators, artifactGenerator{key: exampleAppKey, generate: generateExampleApp})
+	}
+	if _, ok := def.GetAnnotations()[oam.AnnotationOutputSchema]; r.mockOutput && ok {
+		generators = append(generators, artifactGenerator{key: mockOutputKey, generate: generateMockOutput})
+	}
+	if r.graphQL && actx.schema != nil && len(actx.schema.Properties) > 0 {
+		generators = append(generators, artifactGenerator{key: graphQLKey, generate: generateGraphQL})
+	}
+	if r.envMapConvention != "" && actx.schema != nil && len(actx.schema.Properties) > 0 {
+		generators = append(generators, artifactGenerator{key: envMapKey, generate: r.generateEnvMap})
+	}
+	if r.fuzzCorpus {
+		generators = append(generators, artifactGenerator{key: fuzzCorpusKey, generate: generateFuzzCorpus})
+	}
+	if r.paramDependencies {
+		generators = append(generators, artifactGenerator{key: paramDepsKey, generate: generateParamDependencies})
+	}
+	if r.migrationNote && actx.compatibility != nil && len(actx.compatibility.Changes) > 0 {
+		generators = append(generators, artifactGenerator{key: migrationNoteKey, generate: generateMigrationNote})
+	}
+	for _, profile := range r.requestedSchemaProfiles(def) {
+		if project, ok := schemaProfiles[profile]; ok && len(actx.schemaData) > 0 {
+			generators = append(generators, schemaProfileGenerator(profile, project))
+		}
+	}
+	for _, format := range requestedExportFormats(def) {
+		gen, ok := exportFormatGenerators[format]
+		if !ok || (format == exportFormatUsage && r.usageSnippet) {
+			continue
+		}
+		generators = append(generators, gen)
+	}
+	return generators
+}
+
+// storeArtifacts generates the enabled artifacts and merges them into the data of the schema ConfigMap, the checksum
+// manifest is generated at last to cover all the data keys. The artifacts stored before but no longer generated, e.g.
+// their generators are disabled, are removed by the keys recorded in the annotation of the ConfigMap.
+func (r *Reconciler) storeArtifacts(ctx context.Context, namespace, cmName string, actx *artifactContext) error {
+	artifacts, err := r.generateArtifacts(actx)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: cmName}, cm); err != nil {
+			return err
+		}
+		changed := false
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		managed := sets.NewString()
+		for key, content := range artifacts {
+			managed.Insert(key)
+			if current, ok := cm.Data[key]; !ok || current != content {
+				cm.Data[key] = content
+				changed = true
+			}
+		}
+		if r.checksums {
+			managed.Insert(checksumsKey)
+		}
+		for _, key := range strings.Split(cm.GetAnnotations()[oam.AnnotationArtifactKeys], ",") {
+			if _, ok := cm.Data[key]; ok && !managed.Has(key) {
+				delete(cm.Data, key)
+				changed = true
+			}
+		}
+		if r.checksums {
+			if checksums := schemaChecksums(cm.Data); cm.Data[checksumsKey] != checksums {
+				cm.Data[checksumsKey] = checksums
+				changed = true
+			}
+		}
+		if keys := strings.Join(managed.List(), ","); cm.GetAnnotations()[oam.AnnotationArtifactKeys] != keys {
+			cm.SetAnnotations(util.MergeMapOverrideWithDst(cm.GetAnnotations(), map[string]string{oam.AnnotationArtifactKeys: keys}))
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		if err := r.Update(ctx, cm); err != nil {
+			return err
+		}
+		reconcileLogger(ctx).Info("Successfully stored the artifacts of WorkflowStepDefinition in ConfigMap", "configMap", klog.KRef(namespace, cmName))
+		return nil
+	})
+}
+
+// generateArtifacts generates the enabled artifacts keyed by their data keys in the schema ConfigMap
+func (r *Reconciler) generateArtifacts(actx *artifactContext) (map[string]string, error) {
+	generators := r.artifactGenerators(actx)
+	artifacts := make(map[string]string, len(generators))
+	for _, gen := range generators {
+		content, err := gen.generate(actx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate %s: %w", gen.key, err)
+		}
+		artifacts[gen.key] = content
+	}
+	return artifacts, nil
+}
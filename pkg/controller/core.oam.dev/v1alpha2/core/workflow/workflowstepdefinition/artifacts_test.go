@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema artifacts", func() {
+	It("Store artifacts removes stale", func() {
+		ctx := context.Background()
+		def := newTestDefinition("apply-object", simpleTemplate)
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: def.Namespace, Name: "workflowstep-schema-apply-object"},
+			Data:       map[string]string{types.OpenapiV3JSONSchema: `{"type":"object"}`, "custom.txt": "{}"},
+		}
+		r := newTestReconciler(options{usageSnippet: true, requiredSchema: true, checksums: true}, def, cm)
+		actx := &artifactContext{ctx: ctx, def: def, schema: renderTestSchema(GinkgoT(), simpleTemplate)}
+		actx.schemaData, _ = actx.schema.MarshalJSON()
+
+		Expect(r.storeArtifacts(ctx, cm.Namespace, cm.Name, actx)).Should(Succeed())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(cm), cm)).Should(Succeed())
+		Expect(cm.Data).Should(HaveKey(usageSnippetKey))
+		Expect(cm.Data[checksumsKey]).Should(ContainSubstring(usageSnippetKey))
+		Expect(cm.Annotations[oam.AnnotationArtifactKeys]).Should(Equal("checksums.txt,schema.required.json,usage.txt"))
+
+		// the artifacts no longer generated are removed, the data keys not stored by the artifacts are kept
+		r.usageSnippet = false
+		r.checksums = false
+		Expect(r.storeArtifacts(ctx, cm.Namespace, cm.Name, actx)).Should(Succeed())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(cm), cm)).Should(Succeed())
+		Expect(cm.Data).ShouldNot(HaveKey(usageSnippetKey))
+		Expect(cm.Data).ShouldNot(HaveKey(checksumsKey))
+		Expect(cm.Data).Should(HaveKey(requiredSchemaKey))
+		Expect(cm.Data).Should(HaveKey("custom.txt"))
+		Expect(cm.Annotations[oam.AnnotationArtifactKeys]).Should(Equal(requiredSchemaKey))
+
+		r.requiredSchema = false
+		Expect(r.storeArtifacts(ctx, cm.Namespace, cm.Name, actx)).Should(Succeed())
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(cm), cm)).Should(Succeed())
+		Expect(cm.Data).Should(Equal(map[string]string{types.OpenapiV3JSONSchema: `{"type":"object"}`, "custom.txt": "{}"}))
+	})
+})
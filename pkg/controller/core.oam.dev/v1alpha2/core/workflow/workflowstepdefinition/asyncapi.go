@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	// asyncAPIKey is the data key of the AsyncAPI document in the schema ConfigMap
+	asyncAPIKey = "asyncapi.json"
+	// asyncAPIVersion is the version of the AsyncAPI specification the document follows
+	asyncAPIVersion = "2.6.0"
+
+	eventOperationPublish   = "publish"
+	eventOperationSubscribe = "subscribe"
+)
+
+// eventChannel is an event channel declared by the definition
+type eventChannel struct {
+	Name      string
+	Operation string
+}
+
+// eventChannels parses the event channels declared by the annotation of the definition
+func eventChannels(def *v1beta1.WorkflowStepDefinition) []eventChannel {
+	var channels []eventChannel
+	for _, item := range strings.Split(def.GetAnnotations()[oam.AnnotationEventChannels], ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, operation, _ := strings.Cut(item, "=")
+		channels = append(channels, eventChannel{Name: strings.TrimSpace(name), Operation: strings.TrimSpace(operation)})
+	}
+	return channels
+}
+
+// generateAsyncAPI generates the AsyncAPI document of the event channels declared by the definition,
+// the messages on the channels carry the parameters of the step as the payload
+func generateAsyncAPI(actx *artifactContext) (string, error) {
+	messageName := pascalCase(actx.def.Name) + "Event"
+	message := map[string]interface{}{"$ref": "#/components/messages/" + messageName}
+	channels := map[string]interface{}{}
+	for _, channel := range eventChannels(actx.def) {
+		if channel.Operation != eventOperationPublish && channel.Operation != eventOperationSubscribe {
+			return "", fmt.Errorf("invalid operation %q of the event channel %s, must be %s or %s",
+				channel.Operation, channel.Name, eventOperationPublish, eventOperationSubscribe)
+		}
+		item, ok := channels[channel.Name].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			channels[channel.Name] = item
+		}
+		item[channel.Operation] = map[string]interface{}{
+			"operationId": fmt.Sprintf("%s-%s-%s", actx.def.Name, channel.Operation, channel.Name),
+			"message":     message,
+		}
+	}
+	info := map[string]interface{}{"title": actx.def.Name, "version": "v1"}
+	if rev := actx.def.Status.LatestRevision; rev != nil {
+		info["version"] = fmt.Sprintf("v%d", rev.Revision)
+	}
+	if desc := actx.def.GetAnnotations()[types.AnnoDefinitionDescription]; desc != "" {
+		info["description"] = desc
+	}
+	doc := map[string]interface{}{
+		"asyncapi": asyncAPIVersion,
+		"info":     info,
+		"channels": channels,
+		"components": map[string]interface{}{
+			"messages": map[string]interface{}{
+				messageName: map[string]interface{}{
+					"name":        messageName,
+					"contentType": "application/json",
+					"payload":     json.RawMessage(actx.schemaData),
+				},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
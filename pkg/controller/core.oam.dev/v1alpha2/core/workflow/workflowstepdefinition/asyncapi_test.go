@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition AsyncAPI document", func() {
+	It("Async API", func() {
+		ctx := context.Background()
+		def := newTestDefinition("notify-order", `
+parameter: {
+	orderID: string
+}
+`)
+		def.SetAnnotations(map[string]string{oam.AnnotationEventChannels: "orders.created=publish, orders.paid=subscribe"})
+		plain := newTestDefinition("plain", simpleTemplate)
+		r := newTestReconciler(options{asyncAPI: true}, def, plain)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		doc := struct {
+			AsyncAPI string `json:"asyncapi"`
+			Info     struct {
+				Title   string `json:"title"`
+				Version string `json:"version"`
+			} `json:"info"`
+			Channels map[string]map[string]struct {
+				OperationID string `json:"operationId"`
+				Message     struct {
+					Ref string `json:"$ref"`
+				} `json:"message"`
+			} `json:"channels"`
+			Components struct {
+				Messages map[string]struct {
+					Payload struct {
+						Properties map[string]interface{} `json:"properties"`
+					} `json:"payload"`
+				} `json:"messages"`
+			} `json:"components"`
+		}{}
+		Expect(json.Unmarshal([]byte(cm.Data[asyncAPIKey]), &doc)).Should(Succeed())
+		Expect(doc.AsyncAPI).Should(Equal("2.6.0"))
+		Expect(doc.Info.Title).Should(Equal("notify-order"))
+		Expect(doc.Info.Version).Should(Equal("v1"))
+		Expect(doc.Channels["orders.created"]["publish"].OperationID).Should(Equal("notify-order-publish-orders.created"))
+		Expect(doc.Channels["orders.paid"]).Should(HaveKey("subscribe"))
+		Expect(doc.Channels["orders.paid"]["subscribe"].Message.Ref).Should(Equal("#/components/messages/NotifyOrderEvent"))
+		Expect(doc.Components.Messages["NotifyOrderEvent"].Payload.Properties).Should(HaveKey("orderID"))
+
+		got = reconcileTestDefinition(r, plain)
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data).ShouldNot(HaveKey(asyncAPIKey))
+	})
+})
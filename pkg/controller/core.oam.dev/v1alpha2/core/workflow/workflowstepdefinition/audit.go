@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// schemaChangeAudit is the structured record of a schema change written in the audit annotation
+type schemaChangeAudit struct {
+	// OldFingerprint is empty for the first schema of the definition
+	OldFingerprint string `json:"oldFingerprint"`
+	NewFingerprint string `json:"newFingerprint"`
+	Revision       string `json:"revision,omitempty"`
+	SchemaVersion  int64  `json:"schemaVersion"`
+}
+
+// auditSchemaChange writes the schema change in the audit annotation of the definition, every change results in a
+// distinct write of the definition so the audit policies on the WorkflowStepDefinition capture it
+func (r *Reconciler) auditSchemaChange(ctx context.Context, def *v1beta1.WorkflowStepDefinition, oldFingerprint, newFingerprint string) error {
+	audit := schemaChangeAudit{
+		OldFingerprint: oldFingerprint,
+		NewFingerprint: newFingerprint,
+		SchemaVersion:  def.Status.SchemaVersion + 1,
+	}
+	if def.Status.LatestRevision != nil {
+		audit.Revision = def.Status.LatestRevision.Name
+	}
+	data, err := json.Marshal(audit)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(def.DeepCopy())
+	annotations := def.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[oam.AnnotationSchemaChangeAudit] = string(data)
+	def.SetAnnotations(annotations)
+	if err := r.Patch(ctx, def, patch); err != nil {
+		return err
+	}
+	reconcileLogger(ctx).Info("Audited the schema change of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "audit", string(data))
+	return nil
+}
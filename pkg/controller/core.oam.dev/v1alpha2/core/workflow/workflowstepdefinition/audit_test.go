@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition audit annotations", func() {
+	It("Audit schema change", func() {
+		ctx := context.Background()
+		def := newTestDefinition("audited", simpleTemplate)
+		r := newTestReconciler(options{auditSchemaChanges: true}, def)
+
+		got := reconcileTestDefinition(r, def)
+		audit := schemaChangeAudit{}
+		Expect(json.Unmarshal([]byte(got.Annotations[oam.AnnotationSchemaChangeAudit]), &audit)).Should(Succeed())
+		Expect(audit).Should(Equal(schemaChangeAudit{NewFingerprint: got.Status.SchemaHash, Revision: "audited-v1", SchemaVersion: 1}))
+
+		// reconcile without schema change should not write the audit annotation
+		resourceVersion := got.ResourceVersion
+		got = reconcileTestDefinition(r, got)
+		Expect(got.ResourceVersion).Should(Equal(resourceVersion))
+
+		oldFingerprint := got.Status.SchemaHash
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: *1 | int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(json.Unmarshal([]byte(got.Annotations[oam.AnnotationSchemaChangeAudit]), &audit)).Should(Succeed())
+		Expect(audit).Should(Equal(schemaChangeAudit{OldFingerprint: oldFingerprint, NewFingerprint: got.Status.SchemaHash, Revision: "audited-v2", SchemaVersion: 2}))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	oamctrl "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+)
+
+// indexCatalog indexes the definition in the catalog if its entry, e.g. the parameters or the description, changes
+// since the last successful indexing, all the definitions are indexed again after the controller restarts
+func (r *Reconciler) indexCatalog(ctx context.Context, def *v1beta1.WorkflowStepDefinition, schema *openapi3.Schema) error {
+	entry := oamctrl.CatalogEntry{
+		Namespace:   def.Namespace,
+		Name:        def.Name,
+		Description: def.GetAnnotations()[types.AnnoDefinitionDescription],
+		Tags:        def.GetLabels(),
+	}
+	for _, field := range flattenParameters(schema) {
+		entry.Parameters = append(entry.Parameters, field.Path)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	key, fingerprint := client.ObjectKeyFromObject(def), schemaFingerprint(data)
+	if indexed, ok := r.indexedFingerprints.Load(key); ok && indexed == fingerprint {
+		return nil
+	}
+	if err := r.catalogIndexer.Index(ctx, entry); err != nil {
+		return err
+	}
+	r.indexedFingerprints.Store(key, fingerprint)
+	reconcileLogger(ctx).Info("Successfully indexed the WorkflowStepDefinition in the catalog", "workflowStepDefinition", klog.KObj(def))
+	return nil
+}
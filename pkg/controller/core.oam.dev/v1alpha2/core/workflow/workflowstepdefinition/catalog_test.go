@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	oamctrl "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+)
+
+// recordingIndexer records the indexed entries, it fails the first `failures` calls
+type recordingIndexer struct {
+	entries  []oamctrl.CatalogEntry
+	failures int
+}
+
+func (i *recordingIndexer) Index(_ context.Context, entry oamctrl.CatalogEntry) error {
+	if i.failures > 0 {
+		i.failures--
+		return errors.New("index unavailable")
+	}
+	i.entries = append(i.entries, entry)
+	return nil
+}
+
+var _ = Describe("Test WorkflowStepDefinition catalog index", func() {
+	It("Index catalog", func() {
+		ctx := context.Background()
+		def := newTestDefinition("apply-object", simpleTemplate)
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionDescription: "Apply raw kubernetes objects"})
+		def.SetLabels(map[string]string{"custom.definition.oam.dev/category": "resource"})
+		indexer := &recordingIndexer{failures: 1}
+		r := newTestReconciler(options{catalogIndexer: indexer}, def)
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)}
+
+		// the failed indexing should be retried
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Requeue).Should(BeTrue())
+		Expect(indexer.entries).Should(BeEmpty())
+		def = reconcileTestDefinition(r, def)
+		Expect(indexer.entries).Should(Equal([]oamctrl.CatalogEntry{{
+			Namespace:   "default",
+			Name:        "apply-object",
+			Description: "Apply raw kubernetes objects",
+			Tags:        map[string]string{"custom.definition.oam.dev/category": "resource"},
+			Parameters:  []string{"name"},
+		}}))
+
+		// reconcile without schema change should not index again
+		def = reconcileTestDefinition(r, def)
+		Expect(indexer.entries).Should(HaveLen(1))
+
+		def.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: int
+}
+`
+		Expect(r.Update(ctx, def)).Should(Succeed())
+		reconcileTestDefinition(r, def)
+		Expect(indexer.entries).Should(HaveLen(2))
+		Expect(indexer.entries[1].Parameters).Should(Equal([]string{"name", "replicas"}))
+
+		// the description change not changing the schema should index again
+		Expect(r.Get(ctx, req.NamespacedName, def)).Should(Succeed())
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionDescription: "Apply the kubernetes objects"})
+		Expect(r.Update(ctx, def)).Should(Succeed())
+		def = reconcileTestDefinition(r, def)
+		Expect(indexer.entries).Should(HaveLen(3))
+		Expect(indexer.entries[2].Description).Should(Equal("Apply the kubernetes objects"))
+
+		// the deleted definition should be forgotten
+		Expect(r.Delete(ctx, def)).Should(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, ok := r.indexedFingerprints.Load(req.NamespacedName)
+		Expect(ok).Should(BeFalse())
+	})
+})
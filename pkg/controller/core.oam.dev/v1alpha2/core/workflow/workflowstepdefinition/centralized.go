@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// schemaNamespaceOf returns the namespace the schema ConfigMaps of the definition are stored in
+func (r *Reconciler) schemaNamespaceOf(def *v1beta1.WorkflowStepDefinition) string {
+	if r.schemaNamespace == "" {
+		return def.Namespace
+	}
+	return r.schemaNamespace
+}
+
+// configMapNamespaceRef returns the namespace recorded along with the status.configMapRef, it's empty if the schema
+// ConfigMaps are in the namespace of the definition
+func (r *Reconciler) configMapNamespaceRef(def *v1beta1.WorkflowStepDefinition) string {
+	if !r.centralized(def) {
+		return ""
+	}
+	return r.schemaNamespaceOf(def)
+}
+
+// centralized checks whether the schema ConfigMaps of the definition are stored out of its namespace
+func (r *Reconciler) centralized(def *v1beta1.WorkflowStepDefinition) bool {
+	return r.schemaNamespaceOf(def) != def.Namespace
+}
+
+// configMapPrefixOf returns the name prefix of the schema ConfigMaps of the definition
+func (r *Reconciler) configMapPrefixOf(def *v1beta1.WorkflowStepDefinition) string {
+	return schemaConfigMapPrefix(r.configMapPrefix, r.schemaNamespace, def.Namespace)
+}
+
+// schemaConfigMapPrefix returns the name prefix of the schema ConfigMaps of the definitions in the namespace, the
+// namespace is prepended when the ConfigMaps are stored in the centralized namespace so that the same definition
+// names in different namespaces don't collide
+func schemaConfigMapPrefix(configMapPrefix, schemaNamespace, namespace string) string {
+	if schemaNamespace == "" || schemaNamespace == namespace {
+		return configMapPrefix
+	}
+	return configMapPrefix + namespace + "-"
+}
+
+// linkedConfigMap checks whether the schema ConfigMap belongs to the definition, either by the ownerReference or by
+// the labels of the ConfigMaps stored in the centralized namespace
+func linkedConfigMap(cm *corev1.ConfigMap, def *v1beta1.WorkflowStepDefinition) bool {
+	if metav1.IsControlledBy(cm, def) {
+		return true
+	}
+	return cm.Namespace != def.Namespace &&
+		cm.Labels[oam.LabelWorkflowStepDefinitionName] == def.Name &&
+		cm.Labels[oam.LabelWorkflowStepDefinitionNamespace] == def.Namespace
+}
+
+// listCentralizedConfigMaps lists the schema ConfigMaps of the definition and its revisions in the centralized namespace
+func (r *Reconciler) listCentralizedConfigMaps(ctx context.Context, def *v1beta1.WorkflowStepDefinition) ([]corev1.ConfigMap, error) {
+	cmList := &corev1.ConfigMapList{}
+	if err := r.List(ctx, cmList, client.InNamespace(r.schemaNamespaceOf(def)), client.MatchingLabels{
+		oam.LabelWorkflowStepDefinitionName:      def.Name,
+		oam.LabelWorkflowStepDefinitionNamespace: def.Namespace,
+	}); err != nil {
+		return nil, err
+	}
+	return cmList.Items, nil
+}
+
+// collectCentralizedConfigMaps deletes the schema ConfigMaps in the centralized namespace whose DefinitionRevisions
+// are gone, which the garbage collector can't do without the ownerReferences. The revisions themselves are subject to
+// the revision limit, so are their ConfigMaps. The latest revision is retained though it's not persisted if the
+// revisions are disabled.
+func (r *Reconciler) collectCentralizedConfigMaps(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	cms, err := r.listCentralizedConfigMaps(ctx, def)
+	if err != nil {
+		return err
+	}
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := r.List(ctx, revList, client.InNamespace(def.Namespace),
+		client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: def.Name}); err != nil {
+		return err
+	}
+	retained := sets.NewString(def.Name)
+	if latest := def.Status.LatestRevision; latest != nil {
+		retained.Insert(latest.Name)
+	}
+	for _, rev := range revList.Items {
+		retained.Insert(rev.Name)
+	}
+	var collected []string
+	for i := range cms {
+		if retained.Has(cms[i].Labels[types.LabelDefinitionName]) {
+			continue
+		}
+		if err := r.Delete(ctx, &cms[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		collected = append(collected, cms[i].Name)
+	}
+	if len(collected) > 0 {
+		reconcileLogger(ctx).Info("Collected the schema ConfigMaps of the garbage collected DefinitionRevisions", "workflowStepDefinition", klog.KObj(def),
+			"namespace", r.schemaNamespaceOf(def), "configMaps", collected)
+	}
+	return nil
+}
+
+// schemaNamespaceVerbs are the verbs on the ConfigMaps the controller needs in the centralized namespace
+var schemaNamespaceVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+// checkSchemaNamespaceAccess checks the controller is allowed to manage the ConfigMaps in the centralized namespace,
+// so that the missing permissions fail the startup instead of every reconciliation
+func checkSchemaNamespaceAccess(ctx context.Context, cli client.Client, namespace string) error {
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid schema namespace %s: %s", namespace, strings.Join(errs, "; "))
+	}
+	var denied []string
+	for _, verb := range schemaNamespaceVerbs {
+		review := &authorizationv1.SelfSubjectAccessReview{Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: namespace, Verb: verb, Resource: "configmaps"},
+		}}
+		if err := cli.Create(ctx, review); err != nil {
+			return fmt.Errorf("cannot review the access to the ConfigMaps in the schema namespace %s: %w", namespace, err)
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, verb)
+		}
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf("the controller is not allowed to %s the ConfigMaps in the schema namespace %s", strings.Join(denied, ", "), namespace)
+	}
+	return nil
+}
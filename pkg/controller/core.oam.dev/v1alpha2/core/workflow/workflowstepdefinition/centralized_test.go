@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition centralized schema namespace", func() {
+	It("Centralized schema namespace", func() {
+		ctx := context.Background()
+		def := newTestDefinition("central", simpleTemplate)
+		r := newTestReconciler(options{schemaNamespace: "vela-system", defRevLimit: 1}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(Equal("default-workflowstep-schema-central"))
+		Expect(got.Status.ConfigMapNamespace).Should(Equal("vela-system"))
+
+		// the ConfigMaps are linked to the definition by the labels instead of the ownerReferences
+		for _, name := range []string{"default-workflowstep-schema-central", "default-workflowstep-schema-central-v1"} {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: name}, cm)).Should(Succeed())
+			Expect(cm.OwnerReferences).Should(BeEmpty())
+			Expect(cm.Labels[oam.LabelWorkflowStepDefinitionName]).Should(Equal("central"))
+			Expect(cm.Labels[oam.LabelWorkflowStepDefinitionNamespace]).Should(Equal("default"))
+			Expect(cm.Data[types.OpenapiV3JSONSchema]).ShouldNot(BeEmpty())
+		}
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"))).Should(Succeed())
+		Expect(cmList.Items).Should(BeEmpty())
+
+		// the stored schema is not written again
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: "default-workflowstep-schema-central"}, cm)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		stored := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(cm), stored)).Should(Succeed())
+		Expect(stored.ResourceVersion).Should(Equal(cm.ResourceVersion))
+
+		// the ConfigMaps of the garbage collected revisions are collected along
+		for i := 1; i <= 2; i++ {
+			got.Spec.Schematic.CUE.Template = fmt.Sprintf("parameter: {\n\tname: string\n\tfield%d: string\n}\n", i)
+			Expect(r.Update(ctx, got)).Should(Succeed())
+			got = reconcileTestDefinition(r, got)
+		}
+		Expect(got.Status.LatestRevision.Name).Should(Equal("central-v3"))
+		listNames := func() []string {
+			Expect(r.List(ctx, cmList, client.InNamespace("vela-system"))).Should(Succeed())
+			var names []string
+			for _, cm := range cmList.Items {
+				names = append(names, cm.Name)
+			}
+			return names
+		}
+		Expect(listNames()).Should(ConsistOf([]string{"default-workflowstep-schema-central", "default-workflowstep-schema-central-v2",
+			"default-workflowstep-schema-central-v3"}))
+
+		// the ConfigMaps are deleted with the definition
+		Expect(r.Delete(ctx, got)).Should(Succeed())
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(got)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(listNames()).Should(BeEmpty())
+	})
+
+	It("Centralized schema namespace switch", func() {
+		ctx := context.Background()
+		def := newTestDefinition("switched", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(Equal("workflowstep-schema-switched"))
+		Expect(got.Status.ConfigMapNamespace).Should(BeEmpty())
+
+		// the ConfigMap in the namespace of the definition is cleaned up once the schema namespace is set
+		r.schemaNamespace = "vela-system"
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapRef).Should(Equal("default-workflowstep-schema-switched"))
+		Expect(got.Status.ConfigMapNamespace).Should(Equal("vela-system"))
+		err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-switched"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+	})
+})
+
+// accessReviewClient answers the SelfSubjectAccessReviews with the allowed verbs
+type accessReviewClient struct {
+	client.Client
+	allowed map[string]bool
+}
+
+func (c *accessReviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	review := obj.(*authorizationv1.SelfSubjectAccessReview)
+	review.Status.Allowed = c.allowed[review.Spec.ResourceAttributes.Verb]
+	return nil
+}
+
+func TestCheckSchemaNamespaceAccess(t *testing.T) {
+	ctx := context.Background()
+	allowed := map[string]bool{}
+	for _, verb := range schemaNamespaceVerbs {
+		allowed[verb] = true
+	}
+	cli := &accessReviewClient{allowed: allowed}
+	require.NoError(t, checkSchemaNamespaceAccess(ctx, cli, "vela-system"))
+
+	allowed["delete"], allowed["patch"] = false, false
+	require.EqualError(t, checkSchemaNamespaceAccess(ctx, cli, "vela-system"),
+		"the controller is not allowed to patch, delete the ConfigMaps in the schema namespace vela-system")
+	require.Error(t, checkSchemaNamespaceAccess(ctx, cli, "Vela_System"))
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checksumsKey is the data key of the checksum manifest of the other data keys in the schema ConfigMap
+const checksumsKey = "checksums.txt"
+
+// schemaChecksums generates the checksum manifest of the ConfigMap data in the format of sha256sum, so that the
+// schemas transferred to the air-gapped environments can be verified by `sha256sum -c`
+func schemaChecksums(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if key != checksumsKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte(data[key])), key))
+	}
+	return sb.String()
+}
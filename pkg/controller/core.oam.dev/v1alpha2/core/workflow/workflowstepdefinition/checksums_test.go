@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema checksums", func() {
+	It("Checksums", func() {
+		ctx := context.Background()
+		def := newTestDefinition("checksums", simpleTemplate)
+		r := newTestReconciler(options{checksums: true, usageSnippet: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		lines := strings.Split(strings.TrimSuffix(cm.Data[checksumsKey], "\n"), "\n")
+		Expect(lines).Should(HaveLen(len(cm.Data) - 1))
+		covered := map[string]bool{}
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			Expect(fields).Should(HaveLen(2))
+			Expect(fields[0]).Should(Equal(fmt.Sprintf("%x", sha256.Sum256([]byte(cm.Data[fields[1]])))))
+			covered[fields[1]] = true
+		}
+		Expect(covered[types.OpenapiV3JSONSchema]).Should(BeTrue())
+		Expect(covered[usageSnippetKey]).Should(BeTrue())
+
+		// the checksums are updated on the schema change
+		previous := cm.Data[checksumsKey]
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: *1 | int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data[checksumsKey]).ShouldNot(Equal(previous))
+		Expect(cm.Data[checksumsKey]).Should(Equal(schemaChecksums(cm.Data)))
+	})
+})
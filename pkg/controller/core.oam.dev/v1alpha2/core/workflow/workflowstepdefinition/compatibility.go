@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// the kinds of the breaking changes of the parameter schema
+const (
+	breakingChangeRemoved     = "removed"
+	breakingChangeRenamed     = "renamed"
+	breakingChangeTypeChanged = "type-changed"
+	breakingChangeRequired    = "required"
+)
+
+// breakingChange is a change of the parameter schema which may break the existing workflow steps
+type breakingChange struct {
+	Kind string
+	Path string
+	// NewPath is the path the parameter is renamed to, it's guessed from a parameter of the same type added
+	// under the same parent
+	NewPath string
+	OldType string
+	NewType string
+}
+
+// compatibilityReport is the result of checking a revision against its previous revision
+type compatibilityReport struct {
+	PreviousRevision int64
+	Revision         int64
+	Changes          []breakingChange
+}
+
+// checkRevisionCompatibility checks the parameter schema of the revision against the previous revision of the
+// definition, it returns nil if there is no previous revision
+func (r *Reconciler) checkRevisionCompatibility(ctx context.Context, def *v1beta1.WorkflowStepDefinition, defRev *v1beta1.DefinitionRevision) (*compatibilityReport, error) {
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := r.List(ctx, revList, client.InNamespace(def.Namespace),
+		client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: def.Name}); err != nil {
+		return nil, err
+	}
+	var previous *v1beta1.DefinitionRevision
+	for i := range revList.Items {
+		rev := &revList.Items[i]
+		if rev.Spec.Revision < defRev.Spec.Revision && (previous == nil || rev.Spec.Revision > previous.Spec.Revision) {
+			previous = rev
+		}
+	}
+	if previous == nil {
+		return nil, nil
+	}
+	previousSchema, err := revisionSchema(previous)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := revisionSchema(defRev)
+	if err != nil {
+		return nil, err
+	}
+	return &compatibilityReport{
+		PreviousRevision: previous.Spec.Revision,
+		Revision:         defRev.Spec.Revision,
+		Changes:          checkCompatibility(previousSchema, schema),
+	}, nil
+}
+
+// checkCompatibility returns the breaking changes from the previous parameter schema to the current one, the changes
+// of the nested parameters are left out once their parent is removed or renamed
+func checkCompatibility(previous, current *openapi3.Schema) []breakingChange {
+	previousFields := map[string]parameterField{}
+	for _, field := range flattenParameters(previous) {
+		previousFields[field.Path] = field
+	}
+	currentFields := map[string]parameterField{}
+	var added []parameterField
+	for _, field := range flattenParameters(current) {
+		currentFields[field.Path] = field
+		if _, ok := previousFields[field.Path]; !ok {
+			added = append(added, field)
+		}
+	}
+
+	var changes []breakingChange
+	gone := sets.NewString()
+	renamedTo := sets.NewString()
+	for _, field := range flattenParameters(previous) {
+		if hasAncestor(field.Path, gone) {
+			continue
+		}
+		currentField, ok := currentFields[field.Path]
+		if !ok {
+			gone.Insert(field.Path)
+			change := breakingChange{Kind: breakingChangeRemoved, Path: field.Path, OldType: field.Schema.Type}
+			if newPath := renameCandidate(field, added, previousFields, renamedTo); newPath != "" {
+				renamedTo.Insert(newPath)
+				change.Kind = breakingChangeRenamed
+				change.NewPath = newPath
+			}
+			changes = append(changes, change)
+			continue
+		}
+		if field.Schema.Type != "" && currentField.Schema.Type != "" && field.Schema.Type != currentField.Schema.Type {
+			changes = append(changes, breakingChange{Kind: breakingChangeTypeChanged, Path: field.Path,
+				OldType: field.Schema.Type, NewType: currentField.Schema.Type})
+		}
+		if isRequiredParameter(currentField) && !isRequiredParameter(field) {
+			changes = append(changes, breakingChange{Kind: breakingChangeRequired, Path: field.Path})
+		}
+	}
+	for _, field := range added {
+		if renamedTo.Has(field.Path) || !isRequiredParameter(field) {
+			continue
+		}
+		// the parameters added under a new parent are covered by the parent itself
+		if parent := parentPath(field.Path); parent != "" {
+			if _, ok := previousFields[parent]; !ok {
+				continue
+			}
+		}
+		changes = append(changes, breakingChange{Kind: breakingChangeRequired, Path: field.Path})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// renameCandidate returns the only parameter added under the same parent with the same type as the removed one
+func renameCandidate(removed parameterField, added []parameterField, previousFields map[string]parameterField, renamedTo sets.String) string {
+	var candidates []string
+	for _, field := range added {
+		if renamedTo.Has(field.Path) || parentPath(field.Path) != parentPath(removed.Path) ||
+			field.Schema.Type != removed.Schema.Type {
+			continue
+		}
+		if _, ok := previousFields[field.Path]; ok {
+			continue
+		}
+		candidates = append(candidates, field.Path)
+	}
+	if len(candidates) != 1 {
+		return ""
+	}
+	return candidates[0]
+}
+
+// parentPath returns the path of the parent parameter, the items marker of an array parameter is kept
+func parentPath(path string) string {
+	idx := strings.LastIndex(path, ".")
+	if idx < 0 {
+		return ""
+	}
+	return path[:idx]
+}
+
+// hasAncestor checks whether any ancestor of the parameter is in the paths
+func hasAncestor(path string, paths sets.String) bool {
+	for parent := parentPath(path); parent != ""; parent = parentPath(parent) {
+		if paths.Has(parent) || paths.Has(strings.TrimSuffix(parent, "[]")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// computeComplexityScore computes the complexity score of the parameter schema as
+//
+//	score = P + 2*D + C
+//
+// where P is the number of parameters (nested ones included), D is the maximum nesting
+// depth of the parameters and C is the number of constraints declared on them. The enum,
+// each numeric bound, each length or items bound and the pattern are counted as a constraint.
+func computeComplexityScore(schema *openapi3.Schema) int {
+	var params, depth, constraints int
+	for _, field := range flattenParameters(schema) {
+		params++
+		if field.Depth > depth {
+			depth = field.Depth
+		}
+		constraints += countConstraints(field.Schema)
+	}
+	return params + 2*depth + constraints
+}
+
+func countConstraints(s *openapi3.Schema) int {
+	var count int
+	if len(s.Enum) > 0 {
+		count++
+	}
+	if s.Min != nil {
+		count++
+	}
+	if s.Max != nil {
+		count++
+	}
+	if s.MinLength > 0 {
+		count++
+	}
+	if s.MaxLength != nil {
+		count++
+	}
+	if s.Pattern != "" {
+		count++
+	}
+	if s.MinItems > 0 {
+		count++
+	}
+	if s.MaxItems != nil {
+		count++
+	}
+	return count
+}
+
+// reconcileComplexityScore records the complexity score of the parameter schema in the status and the labels of the WorkflowStepDefinition
+func (r *Reconciler) reconcileComplexityScore(ctx context.Context, wfStepDefinition *v1beta1.WorkflowStepDefinition,
+	schema *openapi3.Schema, status *v1beta1.WorkflowStepDefinitionStatus) error {
+	status.ComplexityScore = computeComplexityScore(schema)
+	return r.patchLabels(ctx, wfStepDefinition, map[string]string{
+		oam.LabelWorkflowStepDefinitionComplexityScore: strconv.Itoa(status.ComplexityScore),
+	})
+}
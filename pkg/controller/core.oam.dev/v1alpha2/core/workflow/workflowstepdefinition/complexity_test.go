@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema complexity", func() {
+	It("Complexity score", func() {
+		simple := newTestDefinition("simple", `
+parameter: {
+	name: string
+}
+`)
+		complexDef := newTestDefinition("complex", `
+parameter: {
+	name: string
+	replicas: int & >=1 & <=10
+	mode: *"fast" | "safe"
+	target: {
+		cluster: string
+		labels: [string]: string
+		resources: {
+			cpu: =~"^[0-9]+m$"
+			memory: string
+		}
+	}
+	ports: [...{
+		port: int & >0
+	}]
+}
+`)
+		r := newTestReconciler(options{complexityScore: true}, simple, complexDef)
+
+		gotSimple := reconcileTestDefinition(r, simple)
+		gotComplex := reconcileTestDefinition(r, complexDef)
+		// 1 parameter at depth 1
+		Expect(gotSimple.Status.ComplexityScore).Should(Equal(3))
+		Expect(gotComplex.Status.ComplexityScore).Should(BeNumerically(">", gotSimple.Status.ComplexityScore))
+		Expect(gotSimple.Labels[oam.LabelWorkflowStepDefinitionComplexityScore]).Should(Equal(strconv.Itoa(gotSimple.Status.ComplexityScore)))
+		Expect(gotComplex.Labels[oam.LabelWorkflowStepDefinitionComplexityScore]).Should(Equal(strconv.Itoa(gotComplex.Status.ComplexityScore)))
+	})
+
+	It("Complexity score disabled", func() {
+		def := newTestDefinition("simple", `
+parameter: {
+	name: string
+}
+`)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ComplexityScore).Should(Equal(0))
+		Expect(got.Labels).ShouldNot(HaveKey(oam.LabelWorkflowStepDefinitionComplexityScore))
+	})
+})
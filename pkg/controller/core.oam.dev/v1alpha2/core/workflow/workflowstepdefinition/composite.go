@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kubevela/workflow/pkg/cue/model/value"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// embeddedDefinitions returns the names of the base definitions embedded in the composite definition
+func embeddedDefinitions(def *v1beta1.WorkflowStepDefinition) []string {
+	var names []string
+	for _, name := range strings.Split(def.GetAnnotations()[oam.AnnotationEmbeddedStepDefinitions], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// composeTemplate unifies the parameters of the embedded base definitions, transitively, into the template of the capability,
+// so the schema of the composite definition reflects the bases. The definition itself is left untouched.
+func (r *Reconciler) composeTemplate(ctx context.Context, def *v1beta1.WorkflowStepDefinition, capability *utils.CapabilityStepDefinition) error {
+	schematic := capability.StepDefinition.Spec.Schematic
+	if schematic == nil || schematic.CUE == nil {
+		return nil
+	}
+	parameters, err := r.embeddedParameters(ctx, def, []string{def.Name})
+	if err != nil {
+		return err
+	}
+	schematic.CUE.Template += parameters
+	return nil
+}
+
+// embeddedParameters returns the parameter sections of the base definitions embedded in the definition, the chain is
+// the names of the definitions embedding it which is used to detect the cycles
+func (r *Reconciler) embeddedParameters(ctx context.Context, def *v1beta1.WorkflowStepDefinition, chain []string) (string, error) {
+	var sb strings.Builder
+	for _, name := range embeddedDefinitions(def) {
+		next := append(append([]string{}, chain...), name)
+		if sets.NewString(chain...).Has(name) {
+			return "", fmt.Errorf("cyclic embedding of WorkflowStepDefinitions %s", strings.Join(next, " -> "))
+		}
+		base := &v1beta1.WorkflowStepDefinition{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: name}, base); err != nil {
+			return "", fmt.Errorf("cannot get the embedded WorkflowStepDefinition %s: %w", name, err)
+		}
+		if base.Spec.Schematic != nil && base.Spec.Schematic.CUE != nil {
+			v, err := value.NewValue(base.Spec.Schematic.CUE.Template, nil, "")
+			if err != nil {
+				return "", fmt.Errorf("cannot parse the embedded WorkflowStepDefinition %s: %w", name, err)
+			}
+			if parameter, err := v.LookupValue(process.ParameterFieldName); err == nil {
+				ps, err := parameter.String()
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(fmt.Sprintf("\n%s: {\n%s\n}\n", process.ParameterFieldName, ps))
+			}
+		}
+		parameters, err := r.embeddedParameters(ctx, base, next)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(parameters)
+	}
+	return sb.String(), nil
+}
+
+// dependencyIndex indexes the composite definitions by the base definitions they embed, it's rebuilt as the
+// definitions are reconciled after the controller restarts
+type dependencyIndex struct {
+	mu sync.Mutex
+	// dependents maps the base definition to its composite definitions
+	dependents map[types.NamespacedName]sets.String
+	// bases maps the composite definition to its base definitions
+	bases map[types.NamespacedName][]string
+}
+
+// update records the base definitions embedded in the composite definition
+func (idx *dependencyIndex) update(composite types.NamespacedName, bases []string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.dependents == nil {
+		idx.dependents = map[types.NamespacedName]sets.String{}
+		idx.bases = map[types.NamespacedName][]string{}
+	}
+	for _, base := range idx.bases[composite] {
+		key := types.NamespacedName{Namespace: composite.Namespace, Name: base}
+		if idx.dependents[key].Delete(composite.Name); idx.dependents[key].Len() == 0 {
+			delete(idx.dependents, key)
+		}
+	}
+	delete(idx.bases, composite)
+	if len(bases) == 0 {
+		return
+	}
+	idx.bases[composite] = bases
+	for _, base := range bases {
+		key := types.NamespacedName{Namespace: composite.Namespace, Name: base}
+		if _, ok := idx.dependents[key]; !ok {
+			idx.dependents[key] = sets.NewString()
+		}
+		idx.dependents[key].Insert(composite.Name)
+	}
+}
+
+// dependentsOf returns the composite definitions embedding the base definition directly
+func (idx *dependencyIndex) dependentsOf(base types.NamespacedName) []string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.dependents[base].List()
+}
+
+// enqueueDependents enqueues the composite definitions embedding the changed definition, transitively. The status-only
+// updates of the direct ones are filtered out by the watch predicate, so the indirect ones are enqueued here as well.
+func (r *Reconciler) enqueueDependents(obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	visited := sets.NewString(obj.GetName())
+	queue := []string{obj.GetName()}
+	for len(queue) > 0 {
+		base := queue[0]
+		queue = queue[1:]
+		for _, name := range r.dependencies.dependentsOf(types.NamespacedName{Namespace: obj.GetNamespace(), Name: base}) {
+			if visited.Has(name) {
+				continue
+			}
+			visited.Insert(name)
+			queue = append(queue, name)
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition composite steps", func() {
+	It("Composite definition", func() {
+		ctx := context.Background()
+		base := newTestDefinition("base", `
+parameter: {
+	cluster: *"" | string
+}
+`)
+		composite := newTestDefinition("composite", `
+parameter: {
+	value: {...}
+}
+`)
+		composite.SetAnnotations(map[string]string{oam.AnnotationEmbeddedStepDefinitions: "base"})
+		r := newTestReconciler(options{}, base, composite)
+
+		readParameters := func(def *v1beta1.WorkflowStepDefinition) []string {
+			latest := reconcileTestDefinition(r, def)
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: latest.Namespace, Name: latest.Status.ConfigMapRef}, cm)).Should(Succeed())
+			var params []string
+			schema := &openapi3.Schema{}
+			Expect(schema.UnmarshalJSON([]byte(cm.Data[types.OpenapiV3JSONSchema]))).Should(Succeed())
+			for _, field := range flattenParameters(schema) {
+				params = append(params, field.Path)
+			}
+			return params
+		}
+		Expect(readParameters(base)).Should(Equal([]string{"cluster"}))
+		Expect(readParameters(composite)).Should(Equal([]string{"cluster", "value"}))
+
+		// updating the base should enqueue and regenerate the composite
+		latest := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(base), latest)).Should(Succeed())
+		latest.Spec.Schematic.CUE.Template = `
+parameter: {
+	cluster:    *"" | string
+	namespace?: string
+}
+`
+		Expect(r.Update(ctx, latest)).Should(Succeed())
+		Expect(r.enqueueDependents(latest)).Should(Equal([]ctrl.Request{{NamespacedName: client.ObjectKeyFromObject(composite)}}))
+		Expect(r.enqueueDependents(composite)).Should(BeEmpty())
+		Expect(readParameters(composite)).Should(Equal([]string{"cluster", "namespace", "value"}))
+	})
+
+	It("Composite definition cycle", func() {
+		a := newTestDefinition("a", simpleTemplate)
+		a.SetAnnotations(map[string]string{oam.AnnotationEmbeddedStepDefinitions: "b"})
+		b := newTestDefinition("b", simpleTemplate)
+		b.SetAnnotations(map[string]string{oam.AnnotationEmbeddedStepDefinitions: "a"})
+		r := newTestReconciler(options{}, a, b)
+		got := reconcileTestDefinition(r, a)
+		Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+		cond := got.GetCondition(condition.TypeSynced)
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Message).Should(ContainSubstring("cyclic embedding of WorkflowStepDefinitions a -> b -> a"))
+	})
+
+	It("Composite definition transitive", func() {
+		ctx := context.Background()
+		c := newTestDefinition("c", `
+parameter: {
+	cluster: *"" | string
+}
+`)
+		b := newTestDefinition("b", `
+parameter: {
+	value: {...}
+}
+`)
+		b.SetAnnotations(map[string]string{oam.AnnotationEmbeddedStepDefinitions: "c"})
+		a := newTestDefinition("a", `
+parameter: {
+	message: string
+}
+`)
+		a.SetAnnotations(map[string]string{oam.AnnotationEmbeddedStepDefinitions: "b"})
+		r := newTestReconciler(options{}, a, b, c)
+		for _, def := range []*v1beta1.WorkflowStepDefinition{c, b, a} {
+			reconcileTestDefinition(r, def)
+		}
+
+		// updating the innermost base should enqueue both the direct and the indirect composites
+		latest := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(c), latest)).Should(Succeed())
+		latest.Spec.Schematic.CUE.Template = `
+parameter: {
+	cluster:    *"" | string
+	namespace?: string
+}
+`
+		Expect(r.Update(ctx, latest)).Should(Succeed())
+		requests := r.enqueueDependents(latest)
+		Expect(requests).Should(Equal([]ctrl.Request{{NamespacedName: client.ObjectKeyFromObject(b)}, {NamespacedName: client.ObjectKeyFromObject(a)}}))
+		for _, req := range requests {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		got := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(a), got)).Should(Succeed())
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		schema := &openapi3.Schema{}
+		Expect(schema.UnmarshalJSON([]byte(cm.Data[types.OpenapiV3JSONSchema]))).Should(Succeed())
+		var params []string
+		for _, field := range flattenParameters(schema) {
+			params = append(params, field.Path)
+		}
+		Expect(params).Should(Equal([]string{"cluster", "message", "namespace", "value"}))
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// dependsOnAttr is the CUE attribute declaring the top-level parameters a top-level parameter only matters under,
+// e.g. @dependsOn(mode=advanced|expert,tls) means the parameter matters if mode is advanced or expert and tls is set
+const dependsOnAttr = "dependsOn"
+
+// paramDepsKey is the data key of the parameter dependency graph in the schema ConfigMap
+const paramDepsKey = "param-deps.json"
+
+// paramDependency is an edge of the parameter dependency graph
+type paramDependency struct {
+	Parameter string `json:"parameter"`
+	// DependsOn is the parameter controlling whether the parameter matters
+	DependsOn string `json:"dependsOn"`
+	// Values are the values of DependsOn under which the parameter matters, empty means any value set
+	Values []string `json:"values,omitempty"`
+}
+
+// paramDependencyGraph is the dependency graph of the top-level parameters for the UIs to show or hide the fields
+type paramDependencyGraph struct {
+	// Parameters are all the top-level parameters in their declaration order
+	Parameters   []string          `json:"parameters"`
+	Dependencies []paramDependency `json:"dependencies"`
+}
+
+// parameterDependencies builds the dependency graph of the top-level parameters from their attributes
+func parameterDependencies(def *v1beta1.WorkflowStepDefinition) (*paramDependencyGraph, error) {
+	attrs, err := parameterAttributes(def, dependsOnAttr)
+	if err != nil {
+		return nil, err
+	}
+	graph := &paramDependencyGraph{Parameters: []string{}, Dependencies: []paramDependency{}}
+	for _, attr := range attrs {
+		graph.Parameters = append(graph.Parameters, attr.parameter)
+		if attr.Err() != nil {
+			continue
+		}
+		for i := 0; i < attr.NumArgs(); i++ {
+			key, val := attr.Arg(i)
+			dep := paramDependency{Parameter: attr.parameter, DependsOn: strings.TrimSpace(key)}
+			for _, v := range strings.Split(val, "|") {
+				if v = strings.TrimSpace(v); v != "" {
+					dep.Values = append(dep.Values, v)
+				}
+			}
+			graph.Dependencies = append(graph.Dependencies, dep)
+		}
+	}
+	return graph, nil
+}
+
+// validate checks the dependencies refer to the existing parameters and contain no cycle
+func (g *paramDependencyGraph) validate() error {
+	params := sets.NewString(g.Parameters...)
+	edges := map[string][]string{}
+	for _, dep := range g.Dependencies {
+		if !params.Has(dep.DependsOn) {
+			return fmt.Errorf("the parameter %s depends on the unknown parameter %s", dep.Parameter, dep.DependsOn)
+		}
+		edges[dep.Parameter] = append(edges[dep.Parameter], dep.DependsOn)
+	}
+	// depth-first search, the parameters on the current path are visiting and the finished ones are visited
+	visiting, visited := sets.NewString(), sets.NewString()
+	var path []string
+	var visit func(param string) error
+	visit = func(param string) error {
+		if visiting.Has(param) {
+			start := 0
+			for path[start] != param {
+				start++
+			}
+			return fmt.Errorf("cyclic parameter dependencies %s", strings.Join(append(path[start:], param), " -> "))
+		}
+		if visited.Has(param) {
+			return nil
+		}
+		visiting.Insert(param)
+		path = append(path, param)
+		for _, next := range edges[param] {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visiting.Delete(param)
+		visited.Insert(param)
+		return nil
+	}
+	names := make([]string, 0, len(edges))
+	for name := range edges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lintParamDependencies rejects the parameter dependencies which refer to unknown parameters or contain cycles
+func lintParamDependencies(lctx *lintContext) []lintFinding {
+	graph, err := parameterDependencies(lctx.def)
+	if err != nil {
+		// the template error is left to the schema rendering to report
+		return nil
+	}
+	if err := graph.validate(); err != nil {
+		return []lintFinding{{Rule: "param-dependencies", Severity: lintSeverityError, Message: err.Error()}}
+	}
+	return nil
+}
+
+// generateParamDependencies generates the parameter dependency graph, it's validated by the lint before
+func generateParamDependencies(actx *artifactContext) (string, error) {
+	graph, err := parameterDependencies(actx.def)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+)
+
+var _ = Describe("Test WorkflowStepDefinition parameter dependencies", func() {
+	It("Param dependencies", func() {
+		ctx := context.Background()
+		def := newTestDefinition("notify", `
+parameter: {
+	channel: *"slack" | "email"
+	smtpHost?: string @dependsOn(channel=email)
+	message: string
+}
+`)
+		r := newTestReconciler(options{paramDependencies: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		graph := paramDependencyGraph{}
+		Expect(json.Unmarshal([]byte(cm.Data[paramDepsKey]), &graph)).Should(Succeed())
+		Expect(graph).Should(Equal(paramDependencyGraph{
+			Parameters:   []string{"channel", "smtpHost", "message"},
+			Dependencies: []paramDependency{{Parameter: "smtpHost", DependsOn: "channel", Values: []string{"email"}}},
+		}))
+	})
+
+	It("Param dependencies cycle", func() {
+		def := newTestDefinition("cyclic", `
+parameter: {
+	a?: string @dependsOn(b)
+	b?: string @dependsOn(c=x|y)
+	c?: string @dependsOn(a)
+}
+`)
+		r := newTestReconciler(options{paramDependencies: true}, def)
+		got := reconcileTestDefinition(r, def)
+		cond := got.GetCondition(condition.TypeSynced)
+		Expect(cond.Reason).Should(Equal(condition.ReasonReconcileError))
+		Expect(cond.Message).Should(ContainSubstring("cyclic parameter dependencies a -> b -> c -> a"))
+		Expect(got.Status.LatestRevision).Should(BeNil())
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// TypeDeprecated is the condition type telling whether the definition is deprecated and its spec frozen
+const TypeDeprecated condition.ConditionType = "Deprecated"
+
+const (
+	reasonDeprecated        condition.ConditionReason = "Deprecated"
+	reasonSpecChangeRefused condition.ConditionReason = "SpecChangeRefused"
+	reasonNotDeprecated     condition.ConditionReason = "NotDeprecated"
+)
+
+// isDeprecated checks whether the definition is marked as deprecated by the annotation
+func isDeprecated(def *v1beta1.WorkflowStepDefinition) bool {
+	return def.GetAnnotations()[oam.AnnotationDeprecated] == "true"
+}
+
+// specChangedSinceLatestRevision checks whether the spec of the definition differs from its latest revision, it's
+// false if the definition has no revision yet
+func specChangedSinceLatestRevision(def *v1beta1.WorkflowStepDefinition) (bool, error) {
+	latest := def.Status.LatestRevision
+	if latest == nil {
+		return false, nil
+	}
+	current, _, err := coredef.GatherRevisionInfo(def)
+	if err != nil {
+		return false, err
+	}
+	return current.Spec.RevisionHash != latest.RevisionHash, nil
+}
+
+// refuseDeprecatedChange sets the Deprecated condition telling the spec change of the deprecated definition is not
+// revisioned, the latest revision and the schema ConfigMap are left as they are
+func (r *Reconciler) refuseDeprecatedChange(ctx context.Context, def *v1beta1.WorkflowStepDefinition) (ctrl.Result, error) {
+	c := condition.Condition{
+		Type:               TypeDeprecated,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonSpecChangeRefused,
+		Message: fmt.Sprintf("the definition is deprecated by the annotation %s and frozen at the revision %s, the spec change is not revisioned until the annotation is removed",
+			oam.AnnotationDeprecated, def.Status.LatestRevision.Name),
+	}
+	if def.GetCondition(TypeDeprecated).Reason != reasonSpecChangeRefused {
+		reconcileLogger(ctx).Info("Refused the spec change of the deprecated WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def),
+			"latestRevision", def.Status.LatestRevision.Name)
+		r.recorder(ctx).Event(def, event.Warning("WorkflowStepDefinition is deprecated", fmt.Errorf("%s", c.Message)))
+	}
+	setReconcileResult(ctx, reconcileResultSkipped)
+	return ctrl.Result{}, util.PatchCondition(ctx, r, def, c)
+}
+
+// deprecationCondition returns the Deprecated condition of the definition whose spec is in line with its latest
+// revision, false if the definition has never been deprecated
+func deprecationCondition(def *v1beta1.WorkflowStepDefinition) (condition.Condition, bool) {
+	c := condition.Condition{
+		Type:               TypeDeprecated,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonDeprecated,
+		Message:            fmt.Sprintf("the definition is deprecated by the annotation %s, the spec changes are not revisioned", oam.AnnotationDeprecated),
+	}
+	if isDeprecated(def) {
+		return c, true
+	}
+	if def.GetCondition(TypeDeprecated).Status != corev1.ConditionTrue {
+		return condition.Condition{}, false
+	}
+	c.Status = corev1.ConditionFalse
+	c.Reason = reasonNotDeprecated
+	c.Message = fmt.Sprintf("the annotation %s is removed, the spec changes are revisioned", oam.AnnotationDeprecated)
+	return c, true
+}
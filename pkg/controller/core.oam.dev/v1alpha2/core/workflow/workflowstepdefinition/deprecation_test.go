@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition deprecation", func() {
+	It("Deprecated definition", func() {
+		ctx := context.Background()
+		def := newTestDefinition("sunset", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		recorder := &recordingRecorder{}
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("sunset-v1"))
+		Expect(got.Status.GetCondition(TypeDeprecated).Status).Should(Equal(corev1.ConditionUnknown))
+
+		got.SetAnnotations(map[string]string{oam.AnnotationDeprecated: "true"})
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		c := got.Status.GetCondition(TypeDeprecated)
+		Expect(c.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(c.Reason).Should(Equal(reasonDeprecated))
+
+		// the spec change of the deprecated definition is not revisioned
+		configMapRef := got.Status.ConfigMapRef
+		got.Spec.Schematic.CUE.Template = "parameter: {\n\tname: string\n\tage: int\n}\n"
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		for i := 0; i < 2; i++ {
+			got = reconcileTestDefinition(r, got)
+		}
+		Expect(got.Status.LatestRevision.Name).Should(Equal("sunset-v1"))
+		Expect(got.Status.ConfigMapRef).Should(Equal(configMapRef))
+		c = got.Status.GetCondition(TypeDeprecated)
+		Expect(c.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(c.Reason).Should(Equal(reasonSpecChangeRefused))
+		Expect(c.Message).Should(ContainSubstring("sunset-v1"))
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(HaveLen(1))
+		warnings := 0
+		for _, e := range recorder.events {
+			if e.Type == event.TypeWarning && e.Reason == "WorkflowStepDefinition is deprecated" {
+				warnings++
+			}
+		}
+		Expect(warnings).Should(Equal(1))
+
+		// removing the marker revisions the pending spec change
+		got.SetAnnotations(nil)
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("sunset-v2"))
+		c = got.Status.GetCondition(TypeDeprecated)
+		Expect(c.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(c.Reason).Should(Equal(reasonNotDeprecated))
+	})
+
+	It("Deprecated new definition", func() {
+		// the deprecated definition without any revision still gets the first one to be usable
+		def := newTestDefinition("born-deprecated", simpleTemplate)
+		def.SetAnnotations(map[string]string{oam.AnnotationDeprecated: "true"})
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("born-deprecated-v1"))
+		Expect(got.Status.GetCondition(TypeDeprecated).Reason).Should(Equal(reasonDeprecated))
+	})
+})
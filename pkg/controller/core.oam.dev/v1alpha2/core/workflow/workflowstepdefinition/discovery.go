@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+)
+
+// discoveryRefresher coalesces the DiscoveryMapper refreshes of the concurrent reconciliations, e.g. the ones meeting
+// the unknown kinds after the controller restarts, into one discovery call. The refresh in flight is waited for and its
+// result reused, and the refresh within the min interval since the last successful one is skipped.
+type discoveryRefresher struct {
+	mu       sync.Mutex
+	inflight *discoveryRefresh
+	last     time.Time
+}
+
+// discoveryRefresh is a refresh in flight, done is closed once err is set
+type discoveryRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// refresh refreshes the DiscoveryMapper unless another refresh is in flight or the last one is within the min interval
+func (d *discoveryRefresher) refresh(ctx context.Context, dm discoverymapper.DiscoveryMapper, minInterval time.Duration) error {
+	d.mu.Lock()
+	if call := d.inflight; call != nil {
+		d.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if !d.last.IsZero() && time.Since(d.last) < minInterval {
+		d.mu.Unlock()
+		return nil
+	}
+	call := &discoveryRefresh{done: make(chan struct{})}
+	d.inflight = call
+	d.mu.Unlock()
+
+	_, call.err = dm.Refresh()
+	d.mu.Lock()
+	d.inflight = nil
+	if call.err == nil {
+		d.last = time.Now()
+	}
+	d.mu.Unlock()
+	close(call.done)
+	return call.err
+}
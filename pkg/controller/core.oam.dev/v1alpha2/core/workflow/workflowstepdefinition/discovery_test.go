@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
+)
+
+func TestDiscoveryRefresherCoalesces(t *testing.T) {
+	var refreshes int32
+	release := make(chan struct{})
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockRefresh = func() (meta.RESTMapper, error) {
+		atomic.AddInt32(&refreshes, 1)
+		<-release
+		return nil, errors.New("boom")
+	}
+	d := &discoveryRefresher{}
+
+	// the refreshes arriving during the one in flight wait for and reuse its result
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- d.refresh(context.Background(), dm, 0)
+		}()
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&refreshes) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	require.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+	for err := range errs {
+		require.EqualError(t, err, "boom")
+	}
+
+	// the failed refresh doesn't count towards the min interval
+	dm.MockRefresh = func() (meta.RESTMapper, error) {
+		atomic.AddInt32(&refreshes, 1)
+		return nil, nil
+	}
+	require.NoError(t, d.refresh(context.Background(), dm, time.Hour))
+	require.Equal(t, int32(2), atomic.LoadInt32(&refreshes))
+}
+
+func TestDiscoveryRefresherMinInterval(t *testing.T) {
+	refreshes := 0
+	dm := countingDiscoveryMapper(&refreshes)
+	d := &discoveryRefresher{}
+	require.NoError(t, d.refresh(context.Background(), dm, time.Hour))
+	require.NoError(t, d.refresh(context.Background(), dm, time.Hour))
+	require.Equal(t, 1, refreshes)
+
+	// no min interval
+	require.NoError(t, d.refresh(context.Background(), dm, 0))
+	require.Equal(t, 2, refreshes)
+}
+
+func TestDiscoveryRefresherWaitCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockRefresh = func() (meta.RESTMapper, error) {
+		<-release
+		return nil, nil
+	}
+	d := &discoveryRefresher{}
+	go func() { _ = d.refresh(context.Background(), dm, 0) }()
+	require.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.inflight != nil
+	}, time.Second, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, d.refresh(ctx, dm, 0), context.Canceled)
+}
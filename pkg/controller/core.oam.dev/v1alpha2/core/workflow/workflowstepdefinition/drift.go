@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+// TypeSchemaDrift is the condition type telling the out-of-band edits of the schema ConfigMap are detected and reverted
+const TypeSchemaDrift condition.ConditionType = "SchemaDrift"
+
+const reasonDriftReverted condition.ConditionReason = "DriftReverted"
+
+// managedConfigMapData returns the data the reconciliation stores in the schema ConfigMap, i.e. the rendered schema and
+// the enabled artifacts. The data keys stored by the other options, e.g. the provenance, are not covered.
+func (r *Reconciler) managedConfigMapData(def *utils.CapabilityStepDefinition, actx *artifactContext) (map[string]string, error) {
+	jsonSchema, err := def.RenderOpenAPISchema(def.Name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.generateArtifacts(actx)
+	if err != nil {
+		return nil, err
+	}
+	data[types.OpenapiV3JSONSchema] = string(jsonSchema)
+	return data, nil
+}
+
+// configMapDataHash returns the sha256 hash of the ConfigMap data in the order of the keys
+func configMapDataHash(data map[string]string) string {
+	// the map keys are encoded in order
+	encoded, _ := json.Marshal(data)
+	return schemaFingerprint(encoded)
+}
+
+// detectDrift returns the data keys of the schema ConfigMap edited out of band, i.e. differing from the data the last
+// reconciliation stored while the data to store is unchanged. Nothing is reported if the ConfigMap is moved or the data
+// to store changes, since the ConfigMap is rewritten anyway.
+func (r *Reconciler) detectDrift(ctx context.Context, def *v1beta1.WorkflowStepDefinition, namespace, cmName string, data map[string]string) ([]string, error) {
+	status := def.Status
+	if status.ConfigMapHash == "" || status.ConfigMapHash != configMapDataHash(data) || status.ConfigMapRef != cmName ||
+		status.ConfigMapNamespace != r.configMapNamespaceRef(def) {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: cmName}, cm); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	var drifted []string
+	for key, content := range data {
+		if current, ok := cm.Data[key]; !ok || current != content {
+			drifted = append(drifted, key)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted, nil
+}
+
+// reportDrift sets the SchemaDrift condition and emits the event telling the drifted data keys are reverted, so that the
+// out-of-band edits are left with a trail
+func (r *Reconciler) reportDrift(ctx context.Context, def *v1beta1.WorkflowStepDefinition, status *v1beta1.WorkflowStepDefinitionStatus,
+	namespace, cmName string, drifted []string) {
+	message := fmt.Sprintf("the out-of-band edits of the data keys %s in ConfigMap %s/%s are detected and reverted",
+		strings.Join(drifted, ", "), namespace, cmName)
+	reconcileLogger(ctx).Info("Reverted the out-of-band edits of the schema ConfigMap of WorkflowStepDefinition", "workflowStepDefinition",
+		klog.KObj(def), "configMap", klog.KRef(namespace, cmName), "keys", drifted)
+	r.recorder(ctx).Event(def, event.Normal("WorkflowStepDefinition schema drift reverted", message))
+	status.SetConditions(condition.Condition{
+		Type:               TypeSchemaDrift,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonDriftReverted,
+		Message:            message,
+	})
+}
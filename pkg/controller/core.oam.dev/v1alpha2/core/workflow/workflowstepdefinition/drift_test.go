@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema drift", func() {
+	It("Schema drift", func() {
+		ctx := context.Background()
+		def := newTestDefinition("drifting", simpleTemplate)
+		r := newTestReconciler(options{usageSnippet: true}, def)
+		recorder := &recordingRecorder{}
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapHash).ShouldNot(BeEmpty())
+		cmKey := client.ObjectKey{Namespace: "default", Name: got.Status.ConfigMapRef}
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, cmKey, cm)).Should(Succeed())
+		stored := cm.DeepCopy()
+
+		// no drift, no write
+		recorder.events = nil
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, cmKey, cm)).Should(Succeed())
+		Expect(cm.ResourceVersion).Should(Equal(stored.ResourceVersion))
+		Expect(recorder.events).Should(BeEmpty())
+		Expect(got.Status.GetCondition(TypeSchemaDrift).Status).Should(Equal(corev1.ConditionUnknown))
+
+		// the hand edits are reverted and reported
+		cm.Data[types.OpenapiV3JSONSchema] = `{"type":"object"}`
+		cm.Data[usageSnippetKey] = "edited"
+		Expect(r.Update(ctx, cm)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, cmKey, cm)).Should(Succeed())
+		Expect(cm.Data).Should(Equal(stored.Data))
+		c := got.Status.GetCondition(TypeSchemaDrift)
+		Expect(c.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(c.Reason).Should(Equal(reasonDriftReverted))
+		Expect(c.Message).Should(ContainSubstring(types.OpenapiV3JSONSchema + ", " + usageSnippetKey))
+		Expect(recorder.events).Should(HaveLen(1))
+		Expect(recorder.events[0].Type).Should(Equal(event.TypeNormal))
+		Expect(recorder.events[0].Message).Should(Equal(c.Message))
+
+		// the spec change rewrites the ConfigMap without reporting a drift
+		recorder.events = nil
+		got.Spec.Schematic.CUE.Template = "parameter: {\n\tname: string\n\tage: int\n}\n"
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		hash := got.Status.ConfigMapHash
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapHash).ShouldNot(Equal(hash))
+		for _, e := range recorder.events {
+			Expect(e.Reason).ShouldNot(Equal(event.Reason("WorkflowStepDefinition schema drift reverted")))
+		}
+	})
+})
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+// dryRunReconcile computes the DefinitionRevision and the schema ConfigMaps the reconciliation would write, and
+// reports the intended changes in the log and the event without any Create, Update or status write
+func (r *Reconciler) dryRunReconcile(ctx context.Context, wfStepDefinition *v1beta1.WorkflowStepDefinition) (ctrl.Result, error) {
+	changes, err := r.dryRunChanges(ctx, wfStepDefinition)
+	if err != nil {
+		reconcileLogger(ctx).Error(err, "Could not dry run WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(wfStepDefinition))
+		r.recorder(ctx).Event(wfStepDefinition, event.Warning("Could not dry run WorkflowStepDefinition", err))
+		setReconcileResult(ctx, reconcileResultError)
+		return ctrl.Result{}, nil
+	}
+	message := "no change"
+	if len(changes) > 0 {
+		message = strings.Join(changes, "; ")
+	}
+	reconcileLogger(ctx).Info("Dry run of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(wfStepDefinition), "changes", changes)
+	r.recorder(ctx).Event(wfStepDefinition, event.Normal("WorkflowStepDefinition dry run", message))
+	return ctrl.Result{}, nil
+}
+
+// dryRunChanges returns the changes the reconciliation would make to the DefinitionRevision, the schema ConfigMaps and
+// the status of the definition
+func (r *Reconciler) dryRunChanges(ctx context.Context, wfStepDefinition *v1beta1.WorkflowStepDefinition) ([]string, error) {
+	def := utils.NewCapabilityStepDef(wfStepDefinition)
+	def.ConfigMapNamePrefix = r.configMapPrefixOf(wfStepDefinition)
+	def.ConfigMapNameTemplate = r.cmNameTemplate
+	def.NormalizeDefaults = r.normalizeDefaults
+	def.MaxSchemaSize = r.maxSchemaSize
+	if err := r.composeTemplate(ctx, wfStepDefinition, &def); err != nil {
+		return nil, err
+	}
+	var defRev *v1beta1.DefinitionRevision
+	var isNewRevision bool
+	var err error
+	if r.disableRevisions {
+		defRev, err = ephemeralRevision(wfStepDefinition)
+	} else {
+		defRev, isNewRevision, err = coredef.GenerateDefinitionRevision(ctx, r.Client, wfStepDefinition)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if idempotent, _ := declaredIdempotency(wfStepDefinition); idempotent != nil {
+		def.SchemaExtensions = map[string]interface{}{idempotentExtension: *idempotent}
+	}
+	if r.schemaIDBaseURL != "" {
+		def.SchemaID = schemaID(r.schemaIDBaseURL, def.Name, defRev.Spec.Revision)
+	}
+	// the same schema StoreOpenAPISchema would store in both ConfigMaps
+	jsonSchema, err := def.RenderOpenAPISchema(def.Name)
+	if err != nil {
+		return nil, err
+	}
+	if klog.V(4).Enabled() {
+		reconcileLogger(ctx).Info("Dry run rendered the schema of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(wfStepDefinition), "schema", string(jsonSchema))
+	}
+
+	var changes []string
+	if isNewRevision {
+		changes = append(changes, fmt.Sprintf("create DefinitionRevision %s", defRev.Name))
+	}
+	cmName, err := def.RenderSchemaConfigMapName(wfStepDefinition.Namespace, def.Name, "")
+	if err != nil {
+		return nil, err
+	}
+	revCMName, err := def.RenderSchemaConfigMapName(wfStepDefinition.Namespace, def.Name, defRev.Name)
+	if err != nil {
+		return nil, err
+	}
+	if r.immutableSchemas {
+		// the migration note isn't generated in the dry run, the name differs if the revision has one
+		schemaData, schema, err := renderParameterSchema(&def)
+		if err != nil {
+			return nil, err
+		}
+		if cmName, err = r.prepareImmutableSchema(&def, &artifactContext{ctx: ctx, def: wfStepDefinition, schema: schema, schemaData: schemaData}); err != nil {
+			return nil, err
+		}
+	}
+	if err := def.CheckSchemaSize(jsonSchema); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{cmName, revCMName} {
+		change, err := r.dryRunConfigMapChange(ctx, r.schemaNamespaceOf(wfStepDefinition), name, string(jsonSchema))
+		if err != nil {
+			return nil, err
+		}
+		if change != "" {
+			changes = append(changes, change)
+		}
+	}
+	if latest := wfStepDefinition.Status.LatestRevision; latest == nil || latest.Name != defRev.Name {
+		changes = append(changes, fmt.Sprintf("set status.latestRevision to %s", defRev.Name))
+	}
+	if wfStepDefinition.Status.ConfigMapRef != cmName {
+		changes = append(changes, fmt.Sprintf("set status.configMapRef to %s", cmName))
+	}
+	if namespace := r.configMapNamespaceRef(wfStepDefinition); wfStepDefinition.Status.ConfigMapNamespace != namespace {
+		changes = append(changes, fmt.Sprintf("set status.configMapNamespace to %s", namespace))
+	}
+	return changes, nil
+}
+
+// dryRunConfigMapChange returns the change storing the schema would make to the ConfigMap, empty if it's up to date
+func (r *Reconciler) dryRunConfigMapChange(ctx context.Context, namespace, name, jsonSchema string) (string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("create ConfigMap %s", name), nil
+		}
+		return "", err
+	}
+	if cm.Data[types.OpenapiV3JSONSchema] != jsonSchema {
+		return fmt.Sprintf("update the schema in ConfigMap %s", name), nil
+	}
+	return "", nil
+}
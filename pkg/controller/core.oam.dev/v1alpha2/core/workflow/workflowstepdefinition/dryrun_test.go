@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+var _ = Describe("Test WorkflowStepDefinition dry run", func() {
+	It("Dry run", func() {
+		ctx := context.Background()
+		def := newTestDefinition("dry-run", simpleTemplate)
+		recorder := &recordingRecorder{}
+		r := newTestReconciler(options{dryRun: true}, def)
+		r.record = recorder
+		before := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(def), before)).Should(Succeed())
+
+		got := reconcileTestDefinition(r, def)
+		Expect(got).Should(Equal(before))
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(BeEmpty())
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"))).Should(Succeed())
+		Expect(cmList.Items).Should(BeEmpty())
+		Expect(recorder.events).Should(HaveLen(1))
+		Expect(string(recorder.events[0].Reason)).Should(Equal("WorkflowStepDefinition dry run"))
+		Expect(recorder.events[0].Message).Should(Equal("create DefinitionRevision dry-run-v1; create ConfigMap workflowstep-schema-dry-run; " +
+			"create ConfigMap workflowstep-schema-dry-run-v1; set status.latestRevision to dry-run-v1; " +
+			"set status.configMapRef to workflowstep-schema-dry-run"))
+
+		// the reconciled definition has nothing to change
+		r.dryRun = false
+		got = reconcileTestDefinition(r, got)
+		r.dryRun = true
+		recorder.events = nil
+		got = reconcileTestDefinition(r, got)
+		Expect(recorder.events[0].Message).Should(Equal("no change"))
+
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:  string
+	image: string
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		recorder.events = nil
+		reconcileTestDefinition(r, got)
+		Expect(recorder.events[0].Message).Should(Equal("create DefinitionRevision dry-run-v2; update the schema in ConfigMap workflowstep-schema-dry-run; " +
+			"create ConfigMap workflowstep-schema-dry-run-v2; set status.latestRevision to dry-run-v2"))
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(HaveLen(1))
+	})
+})
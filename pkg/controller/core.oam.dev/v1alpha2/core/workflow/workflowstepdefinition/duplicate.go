@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// specFingerprint returns the fingerprint of the definition spec, which determines the rendered schema and the behavior of the step
+func specFingerprint(def *v1beta1.WorkflowStepDefinition) (string, error) {
+	data, err := json.Marshal(def.Spec)
+	if err != nil {
+		return "", err
+	}
+	return schemaFingerprint(data), nil
+}
+
+// specFingerprintIndex is the cache index of the WorkflowStepDefinitions by their spec fingerprints
+const specFingerprintIndex = "spec.fingerprint"
+
+// addSpecFingerprintIndex indexes the WorkflowStepDefinitions in the cache by their spec fingerprints, so that the
+// identical definitions are listed without going through all the definitions
+func addSpecFingerprintIndex(indexer client.FieldIndexer) error {
+	return indexer.IndexField(context.Background(), &v1beta1.WorkflowStepDefinition{}, specFingerprintIndex, func(obj client.Object) []string {
+		def, ok := obj.(*v1beta1.WorkflowStepDefinition)
+		if !ok {
+			return nil
+		}
+		fingerprint, err := specFingerprint(def)
+		if err != nil {
+			return nil
+		}
+		return []string{fingerprint}
+	})
+}
+
+// findDuplicates returns the keys of the definitions identical to the given one across all the namespaces, sorted in order
+func (r *Reconciler) findDuplicates(ctx context.Context, def *v1beta1.WorkflowStepDefinition) ([]string, error) {
+	fingerprint, err := specFingerprint(def)
+	if err != nil {
+		return nil, err
+	}
+	defList := &v1beta1.WorkflowStepDefinitionList{}
+	if err := r.List(ctx, defList, client.MatchingFields{specFingerprintIndex: fingerprint}); err != nil {
+		return nil, err
+	}
+	var duplicates []string
+	for i := range defList.Items {
+		item := &defList.Items[i]
+		if item.Namespace == def.Namespace && item.Name == def.Name {
+			continue
+		}
+		// the fingerprint is checked again in case the listed definition is stale
+		itemFingerprint, err := specFingerprint(item)
+		if err != nil {
+			return nil, err
+		}
+		if itemFingerprint == fingerprint {
+			duplicates = append(duplicates, client.ObjectKeyFromObject(item).String())
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates, nil
+}
+
+// reconcileDuplicates reports the definitions identical to the given one as a dedup opportunity once they change. If
+// aliasDuplicates is set, the definition is annotated with the canonical one, i.e. the first of the identical definitions
+// ordered by namespace/name.
+func (r *Reconciler) reconcileDuplicates(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	duplicates, err := r.findDuplicates(ctx, def)
+	if err != nil {
+		return err
+	}
+	key, reported := client.ObjectKeyFromObject(def), strings.Join(duplicates, ", ")
+	if previous, _ := r.reportedDuplicates.Load(key); reported != "" && previous != reported {
+		r.recorder(ctx).Event(def, event.Normal("WorkflowStepDefinition has identical definitions",
+			fmt.Sprintf("the definition is identical to %s and can be deduplicated", reported)))
+	}
+	r.reportedDuplicates.Store(key, reported)
+	if !r.aliasDuplicates {
+		return nil
+	}
+	aliasOf := ""
+	if key := client.ObjectKeyFromObject(def).String(); len(duplicates) > 0 && duplicates[0] < key {
+		aliasOf = duplicates[0]
+	}
+	if def.GetAnnotations()[oam.AnnotationAliasOf] == aliasOf {
+		return nil
+	}
+	patch := client.MergeFrom(def.DeepCopy())
+	annotations := def.GetAnnotations()
+	if aliasOf == "" {
+		delete(annotations, oam.AnnotationAliasOf)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[oam.AnnotationAliasOf] = aliasOf
+	}
+	def.SetAnnotations(annotations)
+	if err := r.Patch(ctx, def, patch); err != nil {
+		return err
+	}
+	reconcileLogger(ctx).Info("Successfully updated the alias of the WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "aliasOf", aliasOf)
+	return nil
+}
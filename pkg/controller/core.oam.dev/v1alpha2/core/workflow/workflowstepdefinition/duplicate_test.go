@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// fingerprintIndexedClient lists the WorkflowStepDefinitions from the cache indexed by the spec fingerprints, as the
+// API server doesn't support the custom field selectors
+type fingerprintIndexedClient struct {
+	client.Client
+	cache cache.Cache
+}
+
+func (c *fingerprintIndexedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*v1beta1.WorkflowStepDefinitionList); ok {
+		return c.cache.List(ctx, list, opts...)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+var _ = Describe("Test WorkflowStepDefinition duplicate detection", func() {
+	Context("Detect duplicates", func() {
+		canonical := newTestDefinition("apply-object", simpleTemplate)
+		duplicate := newTestDefinition("apply-object", simpleTemplate)
+		duplicate.SetNamespace("team-a")
+		other := newTestDefinition("other", `
+parameter: {
+	value: {...}
+}
+`)
+		var indexed *fingerprintIndexedClient
+		var stopCache context.CancelFunc
+
+		BeforeEach(func() {
+			informers, err := cache.New(cfg, cache.Options{Scheme: scheme.Scheme})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(addSpecFingerprintIndex(informers)).Should(Succeed())
+			var ctx context.Context
+			ctx, stopCache = context.WithCancel(context.Background())
+			go func() {
+				defer GinkgoRecover()
+				Expect(informers.Start(ctx)).Should(Succeed())
+			}()
+			Expect(informers.WaitForCacheSync(ctx)).Should(BeTrue())
+			indexed = &fingerprintIndexedClient{Client: k8sClient, cache: informers}
+		})
+
+		AfterEach(func() {
+			stopCache()
+		})
+
+		// waitForCache waits for the cache to see the definitions
+		waitForCache := func(defs ...client.Object) {
+			for _, def := range defs {
+				Eventually(func() error {
+					return indexed.cache.Get(context.Background(), client.ObjectKeyFromObject(def), &v1beta1.WorkflowStepDefinition{})
+				}, 10*time.Second).Should(Succeed())
+			}
+		}
+
+		It("report only by default", func() {
+			recorder := &recordingRecorder{}
+			r := newTestReconciler(options{detectDuplicates: true}, canonical.DeepCopy(), duplicate.DeepCopy(), other.DeepCopy())
+			r.Client = indexed
+			r.record = recorder
+			waitForCache(canonical, duplicate, other)
+			found := func() []event.Event {
+				var found []event.Event
+				for _, e := range recorder.events {
+					if e.Type == event.TypeNormal && e.Reason == "WorkflowStepDefinition has identical definitions" {
+						found = append(found, e)
+					}
+				}
+				return found
+			}
+			got := reconcileTestDefinition(r, duplicate)
+			Expect(got.Annotations).ShouldNot(HaveKey(oam.AnnotationAliasOf))
+			Expect(found()).Should(HaveLen(1))
+			Expect(found()[0].Message).Should(ContainSubstring("default/apply-object"))
+			Expect(found()[0].Message).ShouldNot(ContainSubstring("other"))
+
+			// the unchanged duplicates are not reported again
+			got = reconcileTestDefinition(r, got)
+			Expect(found()).Should(HaveLen(1))
+
+			// the changed duplicates are reported
+			another := duplicate.DeepCopy()
+			another.SetNamespace("team-b")
+			createTestObjects(another)
+			waitForCache(another)
+			reconcileTestDefinition(r, got)
+			Expect(found()).Should(HaveLen(2))
+			Expect(found()[1].Message).Should(Equal("the definition is identical to default/apply-object, team-b/apply-object and can be deduplicated"))
+		})
+
+		It("alias the duplicate to the canonical definition", func() {
+			r := newTestReconciler(options{detectDuplicates: true, aliasDuplicates: true}, canonical.DeepCopy(), duplicate.DeepCopy(), other.DeepCopy())
+			r.Client = indexed
+			waitForCache(canonical, duplicate, other)
+			got := reconcileTestDefinition(r, duplicate)
+			Expect(got.Annotations[oam.AnnotationAliasOf]).Should(Equal("default/apply-object"))
+			got = reconcileTestDefinition(r, canonical)
+			Expect(got.Annotations).ShouldNot(HaveKey(oam.AnnotationAliasOf))
+			got = reconcileTestDefinition(r, other)
+			Expect(got.Annotations).ShouldNot(HaveKey(oam.AnnotationAliasOf))
+		})
+	})
+})
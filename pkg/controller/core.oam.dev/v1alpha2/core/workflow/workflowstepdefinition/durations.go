@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+// durationAttr is the CUE attribute marking a top-level string parameter as a duration, e.g. @duration()
+const durationAttr = "duration"
+
+// lintDurations flags the string parameters meant as durations, by their names or the duration attribute, whose
+// default or example values are not valid Go durations, e.g. "5 minutes" instead of "5m"
+func lintDurations(lctx *lintContext) []lintFinding {
+	if lctx.schema == nil {
+		return nil
+	}
+	marked := sets.NewString()
+	if attrs, err := parameterAttributes(lctx.def, durationAttr); err == nil {
+		for _, attr := range attrs {
+			if attr.Err() == nil {
+				marked.Insert(attr.parameter)
+			}
+		}
+	}
+	examples, _ := declaredExamples(lctx.def)
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		s := field.Schema
+		if s.Type != openapi3.TypeString || (!marked.Has(field.Path) && !utils.IsDurationName(lastPathSegment(field.Path))) {
+			continue
+		}
+		if value, ok := s.Default.(string); ok && !isDuration(value) {
+			findings = append(findings, lintFinding{
+				Rule:     "duration",
+				Severity: lintSeverityWarning,
+				Path:     field.Path,
+				Message:  fmt.Sprintf("the default value %q is not a valid duration, e.g. 30s or 5m", value),
+			})
+		}
+		for i, example := range examples {
+			if value, ok := lookupExample(example, field.Path).(string); ok && !isDuration(value) {
+				findings = append(findings, lintFinding{
+					Rule:     "duration",
+					Severity: lintSeverityWarning,
+					Path:     field.Path,
+					Message:  fmt.Sprintf("the value %q of the example %d is not a valid duration, e.g. 30s or 5m", value, i+1),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// isDuration returns whether the value is a valid Go duration
+func isDuration(value string) bool {
+	_, err := time.ParseDuration(value)
+	return err == nil
+}
+
+// lastPathSegment returns the name of the parameter of the dotted path
+func lastPathSegment(path string) string {
+	path = strings.TrimSuffix(path, "[]")
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// lookupExample returns the value of the example at the dotted path of the parameter, the values in the arrays are
+// not looked up
+func lookupExample(example map[string]interface{}, path string) interface{} {
+	if strings.Contains(path, "[]") {
+		return nil
+	}
+	var value interface{} = example
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// envMapKey is the data key of the mapping from the parameters to the env vars in the schema ConfigMap
+const envMapKey = "env-map.json"
+
+// the naming conventions of the env vars mapped from the parameters
+const (
+	// envConventionSnake splits the camel case words, e.g. image.pullPolicy is mapped to IMAGE_PULL_POLICY
+	envConventionSnake = "snake"
+	// envConventionFlat keeps the camel case words together, e.g. image.pullPolicy is mapped to IMAGE_PULLPOLICY
+	envConventionFlat = "flat"
+	// envConventionPrefixed prefixes the snake case name with the step name, e.g. image.pullPolicy of the apply-job step is
+	// mapped to APPLY_JOB_IMAGE_PULL_POLICY
+	envConventionPrefixed = "prefixed"
+)
+
+// validateEnvConvention validates the naming convention of the env vars, empty means the mapping is disabled
+func validateEnvConvention(convention string) error {
+	switch convention {
+	case "", envConventionSnake, envConventionFlat, envConventionPrefixed:
+		return nil
+	}
+	return fmt.Errorf("unsupported env var convention %s, the supported ones are %s, %s and %s", convention, envConventionSnake, envConventionFlat, envConventionPrefixed)
+}
+
+// generateEnvMap generates the mapping from the paths of the parameters to the env var names following the convention,
+// the objects are mapped by their fields and the parameters in the arrays are left to the arrays
+func (r *Reconciler) generateEnvMap(actx *artifactContext) (string, error) {
+	envMap := map[string]string{}
+	for _, field := range flattenParameters(actx.schema) {
+		if strings.Contains(field.Path, "[]") {
+			continue
+		}
+		if s := field.Schema; s.Type == openapi3.TypeObject && len(s.Properties) > 0 {
+			continue
+		}
+		envMap[field.Path] = envVarName(r.envMapConvention, actx.def.Name, field.Path)
+	}
+	data, err := json.MarshalIndent(envMap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// envVarName returns the env var name of the parameter path following the convention
+func envVarName(convention, stepName, path string) string {
+	var words []string
+	if convention == envConventionPrefixed {
+		words = append(words, envWords(stepName, false)...)
+	}
+	for _, segment := range strings.Split(path, ".") {
+		words = append(words, envWords(segment, convention != envConventionFlat)...)
+	}
+	return strings.Join(words, "_")
+}
+
+// envWords splits the name into the upper case words by the non-alphanumeric characters, and by the camel case if split
+// is set. The acronyms are kept as one word, e.g. serviceURL is split into SERVICE and URL
+func envWords(name string, split bool) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToUpper(string(word)))
+			word = nil
+		}
+	}
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			flush()
+			continue
+		}
+		if split && unicode.IsUpper(c) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, c)
+	}
+	flush()
+	return words
+}
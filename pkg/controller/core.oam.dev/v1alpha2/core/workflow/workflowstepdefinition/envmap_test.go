@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Test WorkflowStepDefinition environment mapping", func() {
+	Context("Env map", func() {
+		template := `
+parameter: {
+	image: {
+		name:       string
+		pullPolicy: *"IfNotPresent" | string
+	}
+	serviceURL?: string
+	retries:     *3 | int
+	args?: [...{name: string}]
+	labels?: [string]: string
+}
+`
+		testCases := map[string]map[string]string{
+			envConventionSnake: {
+				"image.name":       "IMAGE_NAME",
+				"image.pullPolicy": "IMAGE_PULL_POLICY",
+				"serviceURL":       "SERVICE_URL",
+				"retries":          "RETRIES",
+				"args":             "ARGS",
+				"labels":           "LABELS",
+			},
+			envConventionFlat: {
+				"image.name":       "IMAGE_NAME",
+				"image.pullPolicy": "IMAGE_PULLPOLICY",
+				"serviceURL":       "SERVICEURL",
+				"retries":          "RETRIES",
+				"args":             "ARGS",
+				"labels":           "LABELS",
+			},
+			envConventionPrefixed: {
+				"image.name":       "APPLY_JOB_IMAGE_NAME",
+				"image.pullPolicy": "APPLY_JOB_IMAGE_PULL_POLICY",
+				"serviceURL":       "APPLY_JOB_SERVICE_URL",
+				"retries":          "APPLY_JOB_RETRIES",
+				"args":             "APPLY_JOB_ARGS",
+				"labels":           "APPLY_JOB_LABELS",
+			},
+		}
+		for convention, expected := range testCases {
+			convention, expected := convention, expected
+			It(convention, func() {
+				def := newTestDefinition("apply-job", template)
+				r := newTestReconciler(options{envMapConvention: convention}, def)
+				got := reconcileTestDefinition(r, def)
+
+				cm := &corev1.ConfigMap{}
+				Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+				envMap := map[string]string{}
+				Expect(json.Unmarshal([]byte(cm.Data[envMapKey]), &envMap)).Should(Succeed())
+				Expect(envMap).Should(Equal(expected))
+			})
+		}
+	})
+})
+
+func TestValidateEnvConvention(t *testing.T) {
+	for _, convention := range []string{envConventionSnake, envConventionFlat, envConventionPrefixed} {
+		require.NoError(t, validateEnvConvention(convention))
+	}
+	require.Error(t, validateEnvConvention("kebab"))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// ephemeralRevision returns the in-memory DefinitionRevision of the definition when the revisions are disabled. It's
+// the latest revision in the status if the spec is unchanged, or the next revision named as the persisted one would be.
+func ephemeralRevision(def *v1beta1.WorkflowStepDefinition) (*v1beta1.DefinitionRevision, error) {
+	defRev, latest, err := coredef.GatherRevisionInfo(def)
+	if err != nil {
+		return nil, err
+	}
+	defRev.Namespace = def.Namespace
+	var next int64 = 1
+	if latest != nil {
+		next = latest.Revision + 1
+	}
+	name := coredef.ConstructDefinitionRevisionName(def.Name, strconv.FormatInt(next, 10))
+	named := def.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
+	if named != "" {
+		name = coredef.ConstructDefinitionRevisionName(def.Name, named)
+	}
+	// the named revision is immutable as the persisted one
+	if latest != nil && ((named != "" && latest.Name == name) || (named == "" && latest.RevisionHash == defRev.Spec.RevisionHash)) {
+		defRev.Name = latest.Name
+		defRev.Spec.Revision = latest.Revision
+		return defRev, nil
+	}
+	defRev.Name = name
+	defRev.Spec.Revision = next
+	return defRev, nil
+}
+
+// reconcileEphemeralRevision updates the latest revision of the definition to its ephemeral revision without
+// persisting the DefinitionRevision. The ConfigMap of the previous revision goes away with it as there's no revision
+// history to roll back to.
+func (r *Reconciler) reconcileEphemeralRevision(ctx context.Context, def *v1beta1.WorkflowStepDefinition,
+	updateLatestRevision func(*common.Revision) error) (*v1beta1.DefinitionRevision, error) {
+	defRev, err := ephemeralRevision(def)
+	if err != nil {
+		return nil, err
+	}
+	previous := def.Status.LatestRevision
+	if previous != nil && previous.Name == defRev.Name {
+		return defRev, nil
+	}
+	if err := updateLatestRevision(&common.Revision{
+		Name:         defRev.Name,
+		Revision:     defRev.Spec.Revision,
+		RevisionHash: defRev.Spec.RevisionHash,
+	}); err != nil {
+		return nil, err
+	}
+	reconcileLogger(ctx).Info("Successfully updated the status.latestRevision of WorkflowStepDefinition to the ephemeral revision", "workflowStepDefinition",
+		klog.KObj(def), "revision", defRev.Name)
+	// the ConfigMaps in the centralized namespace are collected without the DefinitionRevisions
+	if previous == nil || r.centralized(def) {
+		return defRev, nil
+	}
+	capability := utils.CapabilityStepDefinition{}
+	capability.ConfigMapNamePrefix = r.configMapPrefixOf(def)
+	capability.ConfigMapNameTemplate = r.cmNameTemplate
+	cmName, err := capability.RenderSchemaConfigMapName(def.Namespace, def.Name, previous.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.deleteControlledConfigMap(ctx, def, def.Namespace, cmName); err != nil {
+		return nil, err
+	}
+	return defRev, nil
+}
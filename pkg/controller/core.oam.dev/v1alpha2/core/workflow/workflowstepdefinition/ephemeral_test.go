@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test ephemeral WorkflowStepDefinition", func() {
+	It("Disable revisions", func() {
+		ctx := context.Background()
+		def := newTestDefinition("ephemeral", simpleTemplate)
+		r := newTestReconciler(options{disableRevisions: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("ephemeral-v1"))
+		Expect(got.Status.LatestRevision.Revision).Should(Equal(int64(1)))
+		Expect(got.Status.ConfigMapRef).Should(Equal("workflowstep-schema-ephemeral"))
+		revCM := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-ephemeral-v1"}, revCM)).Should(Succeed())
+		Expect(metav1.IsControlledBy(revCM, got)).Should(BeTrue())
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(BeEmpty())
+
+		// the unchanged spec keeps the revision
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("ephemeral-v1"))
+
+		// the spec change moves to the next revision and drops the ConfigMap of the previous one
+		got.Spec.Schematic.CUE.Template = "parameter: {\n\tname: string\n\tage: int\n}\n"
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("ephemeral-v2"))
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-ephemeral-v2"}, revCM)).Should(Succeed())
+		err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-ephemeral-v1"}, revCM)
+		Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(BeEmpty())
+	})
+})
+
+func TestEphemeralRevision(t *testing.T) {
+	def := newTestDefinition("ephemeral", simpleTemplate)
+	rev, err := ephemeralRevision(def)
+	require.NoError(t, err)
+	require.Equal(t, "ephemeral-v1", rev.Name)
+	require.Equal(t, int64(1), rev.Spec.Revision)
+	require.NotEmpty(t, rev.Spec.RevisionHash)
+
+	// the named revision is kept even if the spec changes
+	def.SetAnnotations(map[string]string{oam.AnnotationDefinitionRevisionName: "1.0.0"})
+	rev, err = ephemeralRevision(def)
+	require.NoError(t, err)
+	require.Equal(t, "ephemeral-v1.0.0", rev.Name)
+	def.Status.LatestRevision = &common.Revision{Name: rev.Name, Revision: rev.Spec.Revision, RevisionHash: rev.Spec.RevisionHash}
+	def.Spec.Schematic.CUE.Template = "parameter: {\n\tname: string\n\tage: int\n}\n"
+	rev, err = ephemeralRevision(def)
+	require.NoError(t, err)
+	require.Equal(t, "ephemeral-v1.0.0", rev.Name)
+	require.Equal(t, int64(1), rev.Spec.Revision)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// exampleAppKey is the data key of the example Application using the step in the schema ConfigMap
+const exampleAppKey = "example-app.yaml"
+
+// exampleComponent is the component deployed by the example Application, the workflow steps of an Application run
+// against its components
+var exampleComponent = map[string]interface{}{
+	"name":       "example",
+	"type":       "webservice",
+	"properties": map[string]interface{}{"image": "nginx"},
+}
+
+// generateExampleApp generates a minimal Application whose workflow runs the step, the required parameters of the
+// step are filled with the example values satisfying the schema
+func generateExampleApp(actx *artifactContext) (string, error) {
+	step := map[string]interface{}{
+		"name": actx.def.Name,
+		"type": actx.def.Name,
+	}
+	if properties, ok := sampleValue(actx.schema).(map[string]interface{}); ok && len(properties) > 0 {
+		step["properties"] = properties
+	}
+	app := map[string]interface{}{
+		"apiVersion": v1beta1.SchemeGroupVersion.String(),
+		"kind":       v1beta1.ApplicationKind,
+		"metadata":   map[string]interface{}{"name": actx.def.Name + "-example"},
+		"spec": map[string]interface{}{
+			"components": []interface{}{exampleComponent},
+			"workflow":   map[string]interface{}{"steps": []interface{}{step}},
+		},
+	}
+	data, err := yaml.Marshal(app)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
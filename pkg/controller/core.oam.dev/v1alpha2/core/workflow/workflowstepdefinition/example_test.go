@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+var _ = Describe("Test WorkflowStepDefinition example validation", func() {
+	It("Example app", func() {
+		ctx := context.Background()
+		def := newTestDefinition("notify-slack", `
+parameter: {
+	url: string
+	message: {
+		text: string
+		blocks?: [...string]
+	}
+	retries: *3 | int
+	channel?: string
+}
+`)
+		r := newTestReconciler(options{exampleApp: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		app := &v1beta1.Application{}
+		Expect(yaml.UnmarshalStrict([]byte(cm.Data[exampleAppKey]), app)).Should(Succeed())
+		Expect(app.GroupVersionKind()).Should(Equal(v1beta1.ApplicationKindVersionKind))
+		Expect(app.Spec.Components).Should(HaveLen(1))
+		Expect(app.Spec.Workflow.Steps).Should(HaveLen(1))
+		step := app.Spec.Workflow.Steps[0]
+		Expect(step.Type).Should(Equal("notify-slack"))
+		properties := map[string]interface{}{}
+		Expect(json.Unmarshal(step.Properties.Raw, &properties)).Should(Succeed())
+		Expect(properties).Should(Equal(map[string]interface{}{
+			"url":     "a",
+			"message": map[string]interface{}{"text": "a"},
+			"retries": float64(3),
+		}))
+	})
+})
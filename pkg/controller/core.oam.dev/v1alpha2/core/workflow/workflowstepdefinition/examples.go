@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// declaredExamples parses the examples declared by the annotation of the definition
+func declaredExamples(def *v1beta1.WorkflowStepDefinition) ([]map[string]interface{}, error) {
+	value, ok := def.GetAnnotations()[oam.AnnotationExamples]
+	if !ok {
+		return nil, nil
+	}
+	var examples []map[string]interface{}
+	if err := yaml.Unmarshal([]byte(value), &examples); err != nil {
+		return nil, fmt.Errorf("invalid examples in the annotation %s, must be a list of the properties objects: %w", oam.AnnotationExamples, err)
+	}
+	return examples, nil
+}
+
+// lintExamples flags the declared examples referencing the parameters not in the schema, e.g. the renamed or the
+// removed ones
+func lintExamples(lctx *lintContext) []lintFinding {
+	examples, err := declaredExamples(lctx.def)
+	if err != nil {
+		return []lintFinding{{Rule: "examples", Severity: lintSeverityWarning, Message: err.Error()}}
+	}
+	if lctx.schema == nil {
+		return nil
+	}
+	var findings []lintFinding
+	for i, example := range examples {
+		for _, path := range unknownParameters("", example, lctx.schema) {
+			findings = append(findings, lintFinding{
+				Rule:     "examples",
+				Severity: lintSeverityWarning,
+				Path:     path,
+				Message:  fmt.Sprintf("the example %d references the parameter not declared in the schema", i+1),
+			})
+		}
+	}
+	return findings
+}
+
+// unknownParameters returns the paths of the keys of the value not declared in the object schema, the free-form
+// objects accept any key
+func unknownParameters(prefix string, value interface{}, schema *openapi3.Schema) []string {
+	var unknown []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(schema.Properties) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			ref, ok := schema.Properties[key]
+			if !ok || ref == nil || ref.Value == nil {
+				unknown = append(unknown, path)
+				continue
+			}
+			unknown = append(unknown, unknownParameters(path, v[key], ref.Value)...)
+		}
+	case []interface{}:
+		if schema.Items == nil || schema.Items.Value == nil {
+			return nil
+		}
+		for _, item := range v {
+			unknown = append(unknown, unknownParameters(prefix+"[]", item, schema.Items.Value)...)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition examples lint", func() {
+	It("Lint examples", func() {
+		def := newTestDefinition("documented", `
+parameter: {
+	name: string
+	ports?: [...{
+		port: int
+	}]
+	labels?: {...}
+}
+`)
+		def.SetAnnotations(map[string]string{oam.AnnotationExamples: `
+- name: web
+  ports:
+  - port: 80
+    protocol: TCP
+  labels:
+    app: web
+- name: db
+  replicas: 2
+`})
+		r := newTestReconciler(options{checkExamples: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[examples] ports[].protocol: the example 1 references the parameter not declared in the schema",
+			"[examples] replicas: the example 2 references the parameter not declared in the schema",
+		}))
+
+		def = newTestDefinition("invalid-examples", simpleTemplate)
+		def.SetAnnotations(map[string]string{oam.AnnotationExamples: "name: web"})
+		r = newTestReconciler(options{checkExamples: true}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(HaveLen(1))
+		Expect(got.Status.Warnings[0]).Should(ContainSubstring("[examples] invalid examples in the annotation " + oam.AnnotationExamples))
+	})
+})
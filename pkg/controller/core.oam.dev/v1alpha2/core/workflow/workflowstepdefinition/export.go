@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+const (
+	exportFormatYAML       = "yaml"
+	exportFormatTypeScript = "typescript"
+	exportFormatMarkdown   = "markdown"
+	exportFormatUsage      = "usage"
+)
+
+// exportFormatGenerators are the generators of the extra formats which can be requested by the definition
+var exportFormatGenerators = map[string]artifactGenerator{
+	exportFormatYAML:       {key: "openapi-v3-json-schema.yaml", generate: generateSchemaYAML},
+	exportFormatTypeScript: {key: "types.d.ts", generate: generateTypeScript},
+	exportFormatMarkdown:   {key: "README.md", generate: generateMarkdown},
+	exportFormatUsage:      {key: usageSnippetKey, generate: generateUsageSnippet},
+}
+
+// requestedExportFormats parses the export formats annotation of the definition, the duplicated and empty ones are dropped
+func requestedExportFormats(def *v1beta1.WorkflowStepDefinition) []string {
+	value, ok := def.GetAnnotations()[types.AnnoDefinitionExportFormats]
+	if !ok {
+		return nil
+	}
+	return parseNameList(value)
+}
+
+// parseNameList parses the lowercase names separated by comma, the duplicated and empty ones are dropped
+func parseNameList(value string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// lintExportFormats flags the export formats requested by the definition but not supported, they are not generated
+func lintExportFormats(lctx *lintContext) []lintFinding {
+	var unsupported []string
+	for _, format := range requestedExportFormats(lctx.def) {
+		if _, ok := exportFormatGenerators[format]; !ok {
+			unsupported = append(unsupported, format)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	supported := make([]string, 0, len(exportFormatGenerators))
+	for format := range exportFormatGenerators {
+		supported = append(supported, format)
+	}
+	sort.Strings(supported)
+	return []lintFinding{{
+		Rule:     "export-formats",
+		Severity: lintSeverityWarning,
+		Message: fmt.Sprintf("the export formats %s are not supported, the supported ones are %s",
+			strings.Join(unsupported, ", "), strings.Join(supported, ", ")),
+	}}
+}
+
+// generateSchemaYAML converts the schema to YAML
+func generateSchemaYAML(actx *artifactContext) (string, error) {
+	data, err := yaml.JSONToYAML(actx.schemaData)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// generateTypeScript generates the TypeScript declaration of the parameter, e.g. ApplyObjectProperties for apply-object
+func generateTypeScript(actx *artifactContext) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("export interface %sProperties ", pascalCase(actx.def.Name)))
+	writeTypeScriptObject(&sb, actx.schema, 0)
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+func writeTypeScriptObject(sb *strings.Builder, schema *openapi3.Schema, indent int) {
+	if schema == nil || len(schema.Properties) == 0 {
+		sb.WriteString("{ [key: string]: any }")
+		return
+	}
+	sb.WriteString("{\n")
+	for _, field := range flattenParameters(schema) {
+		if field.Depth != 1 {
+			continue
+		}
+		sb.WriteString(strings.Repeat("  ", indent+1))
+		sb.WriteString(field.Path)
+		if !isRequiredParameter(field) {
+			sb.WriteString("?")
+		}
+		sb.WriteString(": ")
+		writeTypeScriptType(sb, field.Schema, indent+1)
+		sb.WriteString(";\n")
+	}
+	sb.WriteString(strings.Repeat("  ", indent))
+	sb.WriteString("}")
+}
+
+func writeTypeScriptType(sb *strings.Builder, schema *openapi3.Schema, indent int) {
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			if s, ok := v.(string); ok {
+				values = append(values, fmt.Sprintf("%q", s))
+			} else {
+				values = append(values, fmt.Sprintf("%v", v))
+			}
+		}
+		sb.WriteString(strings.Join(values, " | "))
+		return
+	}
+	switch schema.Type {
+	case openapi3.TypeString:
+		sb.WriteString("string")
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		sb.WriteString("number")
+	case openapi3.TypeBoolean:
+		sb.WriteString("boolean")
+	case openapi3.TypeArray:
+		if schema.Items == nil || schema.Items.Value == nil {
+			sb.WriteString("any[]")
+			return
+		}
+		sb.WriteString("Array<")
+		writeTypeScriptType(sb, schema.Items.Value, indent)
+		sb.WriteString(">")
+	case openapi3.TypeObject:
+		writeTypeScriptObject(sb, schema, indent)
+	default:
+		sb.WriteString("any")
+	}
+}
+
+// generateMarkdown generates the Markdown reference of the parameters
+func generateMarkdown(actx *artifactContext) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", actx.def.Name))
+	if desc := actx.def.GetAnnotations()[types.AnnoDefinitionDescription]; desc != "" {
+		sb.WriteString(desc + "\n\n")
+	}
+	sb.WriteString("| Name | Description | Type | Required | Default |\n")
+	sb.WriteString("| ---- | ----------- | ---- | -------- | ------- |\n")
+	for _, field := range flattenParameters(actx.schema) {
+		var def string
+		if field.Schema.Default != nil {
+			def = fmt.Sprintf("%v", field.Schema.Default)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %t | %s |\n", field.Path, field.Schema.Description,
+			markdownType(field.Schema), isRequiredParameter(field), def))
+	}
+	return sb.String(), nil
+}
+
+func markdownType(schema *openapi3.Schema) string {
+	if schema.Type == openapi3.TypeArray && schema.Items != nil && schema.Items.Value != nil {
+		return "[]" + markdownType(schema.Items.Value)
+	}
+	return schema.Type
+}
+
+// pascalCase converts the name like apply-object to ApplyObject
+func pascalCase(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
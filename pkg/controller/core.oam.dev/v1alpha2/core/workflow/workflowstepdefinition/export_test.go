@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema export formats", func() {
+	It("Export formats", func() {
+		def := newTestDefinition("apply-object", `
+parameter: {
+	// +usage=Specify the value of the object
+	value: {...}
+	cluster: *"" | string
+	protocol: "TCP" | "UDP"
+	ports: [...int]
+}
+`)
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionExportFormats: "TypeScript, markdown,pdf"})
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{"[export-formats] the export formats pdf are not supported, the supported ones are markdown, typescript, usage, yaml"}))
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data["types.d.ts"]).Should(Equal(`export interface ApplyObjectProperties {
+  cluster?: string;
+  ports: Array<number>;
+  protocol: "TCP" | "UDP";
+  value: { [key: string]: any };
+}
+`))
+		Expect(cm.Data["README.md"]).Should(ContainSubstring("| value | Specify the value of the object | object | true |  |"))
+		Expect(cm.Data["README.md"]).Should(ContainSubstring("| cluster |  | string | false |  |"))
+		Expect(cm.Data).ShouldNot(HaveKey("openapi-v3-json-schema.yaml"))
+		Expect(cm.Data).ShouldNot(HaveKey(usageSnippetKey))
+	})
+})
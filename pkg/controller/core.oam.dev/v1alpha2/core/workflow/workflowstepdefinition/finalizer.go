@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// definitionFinalizer is to delete the schema ConfigMaps and the DefinitionRevisions of the WorkflowStepDefinition
+const definitionFinalizer = "workflowstepdefinition.finalizer.core.oam.dev"
+
+// ensureFinalizer registers the finalizer before any resource of the definition is created
+func (r *Reconciler) ensureFinalizer(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	if meta.FinalizerExists(def, definitionFinalizer) {
+		return nil
+	}
+	patch := client.MergeFrom(def.DeepCopy())
+	meta.AddFinalizer(def, definitionFinalizer)
+	if err := r.Patch(ctx, def, patch); err != nil {
+		return err
+	}
+	reconcileLogger(ctx).Info("Register new finalizer for WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "finalizer", definitionFinalizer)
+	return nil
+}
+
+// finalize deletes the schema ConfigMaps and the DefinitionRevisions of the deleted definition and then removes the
+// finalizer. The deleted resources are skipped so the partially finalized definition can be finalized again. In the
+// dry run the deletions are only reported.
+func (r *Reconciler) finalize(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	if !meta.FinalizerExists(def, definitionFinalizer) {
+		return nil
+	}
+	capability := utils.NewCapabilityStepDef(def)
+	capability.ConfigMapNamePrefix = r.configMapPrefixOf(def)
+	capability.ConfigMapNameTemplate = r.cmNameTemplate
+	var deletedConfigMaps, deletedRevisions int
+	var changes []string
+	remove := func(obj client.Object, kind string) (bool, error) {
+		if r.dryRun {
+			changes = append(changes, fmt.Sprintf("delete %s %s", kind, obj.GetName()))
+			return true, nil
+		}
+		if err := r.Delete(ctx, obj); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return true, nil
+	}
+	removeControlled := func(owner metav1.Object, name string) error {
+		cm, err := r.controlledConfigMap(ctx, owner, def.Namespace, name)
+		if err != nil || cm == nil {
+			return err
+		}
+		deleted, err := remove(cm, "ConfigMap")
+		if deleted {
+			deletedConfigMaps++
+		}
+		return err
+	}
+	if r.centralized(def) {
+		cms, err := r.listCentralizedConfigMaps(ctx, def)
+		if err != nil {
+			return err
+		}
+		for i := range cms {
+			deleted, err := remove(&cms[i], "ConfigMap")
+			if err != nil {
+				return err
+			}
+			if deleted {
+				deletedConfigMaps++
+			}
+		}
+	}
+	names := []string{def.Status.ConfigMapRef}
+	// the name failing to render can't have been stored
+	if name, err := capability.RenderSchemaConfigMapName(def.Namespace, def.Name, ""); err == nil && name != def.Status.ConfigMapRef {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if err := removeControlled(def, name); err != nil {
+			return err
+		}
+	}
+
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := r.List(ctx, revList, client.InNamespace(def.Namespace),
+		client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: def.Name}); err != nil {
+		return err
+	}
+	for i := range revList.Items {
+		rev := &revList.Items[i]
+		if name, err := capability.RenderSchemaConfigMapName(def.Namespace, def.Name, rev.Name); err == nil {
+			if err := removeControlled(rev, name); err != nil {
+				return err
+			}
+		}
+		deleted, err := remove(rev, "DefinitionRevision")
+		if err != nil {
+			return err
+		}
+		if deleted {
+			deletedRevisions++
+		}
+	}
+	if r.schemaNotifyConfigMap != "" {
+		if r.dryRun {
+			changes = append(changes, fmt.Sprintf("withdraw the schema from ConfigMap %s", r.schemaNotifyConfigMap))
+		} else if err := r.withdrawSchemaNotification(ctx, def); err != nil {
+			return err
+		}
+	}
+	if r.dryRun {
+		changes = append(changes, fmt.Sprintf("remove the finalizer %s", definitionFinalizer))
+		reconcileLogger(ctx).Info("Dry run of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "changes", changes)
+		r.recorder(ctx).Event(def, event.Normal("WorkflowStepDefinition dry run", strings.Join(changes, "; ")))
+		return nil
+	}
+	if deletedConfigMaps > 0 || deletedRevisions > 0 {
+		reconcileLogger(ctx).Info("Cleaned up the resources of the deleted WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def),
+			"configMaps", deletedConfigMaps, "definitionRevisions", deletedRevisions)
+		r.recorder(ctx).Event(def, event.Normal("WorkflowStepDefinition resources cleaned up",
+			fmt.Sprintf("deleted %d schema ConfigMaps and %d DefinitionRevisions", deletedConfigMaps, deletedRevisions)))
+	}
+
+	r.indexedFingerprints.Delete(client.ObjectKeyFromObject(def))
+	r.revisionParams.forget(client.ObjectKeyFromObject(def))
+	patch := client.MergeFrom(def.DeepCopy())
+	meta.RemoveFinalizer(def, definitionFinalizer)
+	return client.IgnoreNotFound(r.Patch(ctx, def, patch))
+}
+
+// deleteControlledConfigMap deletes the ConfigMap of the name if it's controlled by the owner, it returns whether the
+// ConfigMap is deleted
+func (r *Reconciler) deleteControlledConfigMap(ctx context.Context, owner metav1.Object, namespace, name string) (bool, error) {
+	cm, err := r.controlledConfigMap(ctx, owner, namespace, name)
+	if err != nil || cm == nil {
+		return false, err
+	}
+	if err := r.Delete(ctx, cm); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return true, nil
+}
+
+// controlledConfigMap returns the ConfigMap of the name if it's controlled by the owner, nil if there is none
+func (r *Reconciler) controlledConfigMap(ctx context.Context, owner metav1.Object, namespace, name string) (*corev1.ConfigMap, error) {
+	if name == "" {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(cm, owner) {
+		return nil, nil
+	}
+	return cm, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition finalizer", func() {
+	It("Finalizer", func() {
+		ctx := context.Background()
+		def := newTestDefinition("finalized", simpleTemplate)
+		recorder := &recordingRecorder{}
+		r := newTestReconciler(options{}, def)
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		Expect(meta.FinalizerExists(got, definitionFinalizer)).Should(BeTrue())
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: *1 | int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		cmName := got.Status.ConfigMapRef
+
+		revList := &v1beta1.DefinitionRevisionList{}
+		listRevisions := func() []v1beta1.DefinitionRevision {
+			Expect(r.List(ctx, revList, client.InNamespace(got.Namespace),
+				client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: got.Name})).Should(Succeed())
+			return revList.Items
+		}
+		Expect(listRevisions()).Should(HaveLen(2))
+		// the partially cleaned up resources don't block the finalization
+		Expect(r.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: got.Namespace, Name: cmName}})).Should(Succeed())
+
+		Expect(r.Delete(ctx, got)).Should(Succeed())
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(got)})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(listRevisions()).Should(BeEmpty())
+		for _, name := range []string{cmName, "workflowstep-schema-finalized-v1", "workflowstep-schema-finalized-v2"} {
+			err = r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: name}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).Should(BeTrue(), name)
+		}
+		err = r.Get(ctx, client.ObjectKeyFromObject(got), &v1beta1.WorkflowStepDefinition{})
+		Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+		Expect(string(recorder.events[len(recorder.events)-1].Reason)).Should(Equal("WorkflowStepDefinition resources cleaned up"))
+	})
+
+	It("Finalizer dry run", func() {
+		ctx := context.Background()
+		def := newTestDefinition("finalized", simpleTemplate)
+		recorder := &recordingRecorder{}
+		r := newTestReconciler(options{}, def)
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		cmName := got.Status.ConfigMapRef
+
+		r.dryRun = true
+		recorder.events = nil
+		Expect(r.Delete(ctx, got)).Should(Succeed())
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(got)})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// nothing is deleted and the finalizer is kept
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace(got.Namespace))).Should(Succeed())
+		Expect(revList.Items).Should(HaveLen(1))
+		for _, name := range []string{cmName, "workflowstep-schema-finalized-v1"} {
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: name}, &corev1.ConfigMap{})).Should(Succeed())
+		}
+		latest := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(got), latest)).Should(Succeed())
+		Expect(meta.FinalizerExists(latest, definitionFinalizer)).Should(BeTrue())
+		Expect(recorder.events).Should(HaveLen(1))
+		Expect(string(recorder.events[0].Reason)).Should(Equal("WorkflowStepDefinition dry run"))
+		Expect(recorder.events[0].Message).Should(Equal("delete ConfigMap workflowstep-schema-finalized; delete ConfigMap workflowstep-schema-finalized-v1; " +
+			"delete DefinitionRevision finalized-v1; remove the finalizer " + definitionFinalizer))
+	})
+})
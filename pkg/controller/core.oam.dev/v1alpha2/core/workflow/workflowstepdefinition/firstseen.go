@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// resolveFirstSeen returns the first-seen timestamp of the definition, it's the one in the status if set. The status
+// lost, e.g. by restoring the definitions from a backup without status, is recovered from the annotation of the schema
+// ConfigMap rather than reset. It must be called before the schema is stored, which replaces the annotations.
+func (r *Reconciler) resolveFirstSeen(ctx context.Context, def *v1beta1.WorkflowStepDefinition, cmName string) (*metav1.Time, error) {
+	if def.Status.FirstSeen != nil {
+		return def.Status.FirstSeen, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.schemaNamespaceOf(def), Name: cmName}, cm); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if value, ok := cm.GetAnnotations()[oam.AnnotationFirstSeen]; ok {
+		firstSeen, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return &metav1.Time{Time: firstSeen}, nil
+		}
+		reconcileLogger(ctx).Info("Ignore the invalid first-seen annotation of the schema ConfigMap", "configMap", klog.KObj(cm), "value", value)
+	}
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	return &now, nil
+}
+
+// storeFirstSeen propagates the first-seen timestamp to the annotation of the schema ConfigMap
+func (r *Reconciler) storeFirstSeen(ctx context.Context, namespace, cmName string, firstSeen *metav1.Time) error {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: cmName}, cm); err != nil {
+		return err
+	}
+	return r.patchAnnotations(ctx, cm, map[string]string{oam.AnnotationFirstSeen: firstSeen.UTC().Format(time.RFC3339)})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition first seen time", func() {
+	It("First seen", func() {
+		ctx := context.Background()
+		def := newTestDefinition("first-seen", simpleTemplate)
+		r := newTestReconciler(options{firstSeen: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.FirstSeen).ShouldNot(BeNil())
+		firstSeen := got.Status.FirstSeen.DeepCopy()
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Annotations[oam.AnnotationFirstSeen]).Should(Equal(firstSeen.UTC().Format(time.RFC3339)))
+
+		// the timestamp stays stable across the reconciles and the schema changes
+		time.Sleep(time.Second)
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: *1 | int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(firstSeen.Equal(got.Status.FirstSeen)).Should(BeTrue())
+
+		// the lost status is recovered from the ConfigMap annotation by the restarted controller
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(cm), cm)).Should(Succeed())
+		cm.Annotations[oam.AnnotationFirstSeen] = "2022-06-01T00:00:00Z"
+		Expect(r.Update(ctx, cm)).Should(Succeed())
+		got.Status = v1beta1.WorkflowStepDefinitionStatus{}
+		Expect(r.Status().Update(ctx, got)).Should(Succeed())
+		restarted := newTestReconciler(options{firstSeen: true})
+		restarted.Client = r.Client
+		got = reconcileTestDefinition(restarted, got)
+		Expect(got.Status.FirstSeen.UTC().Format(time.RFC3339)).Should(Equal("2022-06-01T00:00:00Z"))
+	})
+})
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// fuzzCorpusKey is the data key of the parameter fuzz corpus in the schema ConfigMap
+const fuzzCorpusKey = "fuzz-corpus.json"
+
+// fuzzCase is a parameter set of the fuzz corpus, the invalid ones violate exactly one constraint of the schema
+type fuzzCase struct {
+	Name       string                 `json:"name"`
+	Valid      bool                   `json:"valid"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// fuzzCorpus is the set of the parameter sets for fuzzing the consumers of the step
+type fuzzCorpus struct {
+	Cases []fuzzCase `json:"cases"`
+}
+
+// generateFuzzCorpus generates the valid and the boundary-invalid parameter sets from the schema. All the cases
+// derive from a minimal valid parameter set, the nested parameters under an optional parent are not covered.
+func generateFuzzCorpus(actx *artifactContext) (string, error) {
+	base, _ := sampleValue(actx.schema).(map[string]interface{})
+	corpus := []fuzzCase{{Name: "minimal", Valid: true, Parameters: base}}
+	for _, field := range flattenParameters(actx.schema) {
+		if strings.Contains(field.Path, "[]") || !hasParentIn(base, field.Path) {
+			continue
+		}
+		variant := func(name string, valid bool, mutate func(parent map[string]interface{}, key string)) {
+			params := copyParameters(base)
+			parent, key := lookupParent(params, field.Path)
+			mutate(parent, key)
+			corpus = append(corpus, fuzzCase{Name: fmt.Sprintf("%s %s", field.Path, name), Valid: valid, Parameters: params})
+		}
+		set := func(v interface{}) func(map[string]interface{}, string) {
+			return func(parent map[string]interface{}, key string) { parent[key] = v }
+		}
+		s := field.Schema
+		if isRequiredParameter(field) {
+			variant("missing", false, func(parent map[string]interface{}, key string) { delete(parent, key) })
+		}
+		switch s.Type {
+		case openapi3.TypeInteger, openapi3.TypeNumber:
+			if s.Min != nil {
+				variant("at minimum", !s.ExclusiveMin, set(*s.Min))
+				variant("below minimum", false, set(*s.Min-1))
+			}
+			if s.Max != nil {
+				variant("at maximum", !s.ExclusiveMax, set(*s.Max))
+				variant("above maximum", false, set(*s.Max+1))
+			}
+		case openapi3.TypeString:
+			if s.MinLength > 0 {
+				variant("below minLength", false, set(strings.Repeat("a", int(s.MinLength)-1)))
+			}
+			if s.MaxLength != nil {
+				variant("at maxLength", true, set(strings.Repeat("a", int(*s.MaxLength))))
+				variant("above maxLength", false, set(strings.Repeat("a", int(*s.MaxLength)+1)))
+			}
+			if len(s.Enum) > 0 && !containsValue(s.Enum, "not-in-enum") {
+				variant("not in enum", false, set("not-in-enum"))
+			}
+		}
+	}
+	data, err := json.MarshalIndent(fuzzCorpus{Cases: corpus}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sampleValue returns a value satisfying the schema, the objects contain only the required parameters
+func sampleValue(s *openapi3.Schema) interface{} {
+	switch {
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+	switch s.Type {
+	case openapi3.TypeString:
+		length := int(s.MinLength)
+		if length == 0 && (s.MaxLength == nil || *s.MaxLength > 0) {
+			length = 1
+		}
+		return strings.Repeat("a", length)
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		switch {
+		case s.Min != nil && s.ExclusiveMin:
+			return *s.Min + 1
+		case s.Min != nil:
+			return *s.Min
+		case s.Max != nil && s.ExclusiveMax:
+			return *s.Max - 1
+		case s.Max != nil:
+			return *s.Max
+		}
+		return 0
+	case openapi3.TypeBoolean:
+		return false
+	case openapi3.TypeArray:
+		items := []interface{}{}
+		if s.Items != nil && s.Items.Value != nil {
+			for i := uint64(0); i < s.MinItems; i++ {
+				items = append(items, sampleValue(s.Items.Value))
+			}
+		}
+		return items
+	}
+	obj := map[string]interface{}{}
+	for _, name := range s.Required {
+		if ref := s.Properties[name]; ref != nil && ref.Value != nil {
+			obj[name] = sampleValue(ref.Value)
+		}
+	}
+	return obj
+}
+
+// copyParameters deep copies the parameter set
+func copyParameters(params map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = copyParameters(nested)
+		}
+		copied[k] = v
+	}
+	return copied
+}
+
+// lookupParent returns the object containing the parameter of the dotted path and the key of the parameter in it
+func lookupParent(params map[string]interface{}, path string) (map[string]interface{}, string) {
+	segments := strings.Split(path, ".")
+	parent := params
+	for _, segment := range segments[:len(segments)-1] {
+		parent, _ = parent[segment].(map[string]interface{})
+	}
+	return parent, segments[len(segments)-1]
+}
+
+// hasParentIn checks whether the object containing the parameter of the dotted path is in the parameter set
+func hasParentIn(params map[string]interface{}, path string) bool {
+	parent, _ := lookupParent(params, path)
+	return parent != nil
+}
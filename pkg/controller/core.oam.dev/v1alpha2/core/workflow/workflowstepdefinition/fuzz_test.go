@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Test WorkflowStepDefinition fuzz corpus", func() {
+	It("Fuzz corpus", func() {
+		ctx := context.Background()
+		def := newTestDefinition("scale", `
+parameter: {
+	name:     string
+	replicas: int & >=1 & <=10
+	policy?: "Always" | "Never"
+}
+`)
+		r := newTestReconciler(options{fuzzCorpus: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		corpus := fuzzCorpus{}
+		Expect(json.Unmarshal([]byte(cm.Data[fuzzCorpusKey]), &corpus)).Should(Succeed())
+		cases := map[string]fuzzCase{}
+		for _, c := range corpus.Cases {
+			cases[c.Name] = c
+		}
+		Expect(cases["minimal"]).Should(Equal(fuzzCase{Name: "minimal", Valid: true, Parameters: map[string]interface{}{"name": "a", "replicas": float64(1)}}))
+		Expect(cases["name missing"]).Should(Equal(fuzzCase{Name: "name missing", Parameters: map[string]interface{}{"replicas": float64(1)}}))
+		Expect(cases["replicas below minimum"]).Should(Equal(fuzzCase{Name: "replicas below minimum", Parameters: map[string]interface{}{"name": "a", "replicas": float64(0)}}))
+		Expect(cases["replicas above maximum"]).Should(Equal(fuzzCase{Name: "replicas above maximum", Parameters: map[string]interface{}{"name": "a", "replicas": float64(11)}}))
+		Expect(cases["replicas at maximum"].Valid).Should(BeTrue())
+		Expect(cases["replicas missing"].Valid).Should(BeFalse())
+		Expect(cases["policy not in enum"].Valid).Should(BeFalse())
+		Expect(cases).ShouldNot(HaveKey("policy missing"))
+	})
+})
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// definitionManifest returns the canonicalized manifest of the definition to be reconciled against Git, only the
+// identity and the spec are kept, the fields are ordered by name
+func definitionManifest(def *v1beta1.WorkflowStepDefinition) ([]byte, error) {
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": v1beta1.SchemeGroupVersion.String(),
+		"kind":       v1beta1.WorkflowStepDefinitionKind,
+		"metadata":   map[string]interface{}{"name": def.Name, "namespace": def.Namespace},
+		"spec":       def.Spec,
+	})
+}
+
+// definitionExportKey is the data key of the definition manifest in the export ConfigMap
+func definitionExportKey(def *v1beta1.WorkflowStepDefinition) string {
+	return def.Namespace + "." + def.Name + ".yaml"
+}
+
+// exportDefinition writes the canonicalized manifest of the definition to the export ConfigMap in the system definition
+// namespace and the export directory, whichever is enabled. They're only written when the manifest changes.
+func (r *Reconciler) exportDefinition(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	manifest, err := definitionManifest(def)
+	if err != nil {
+		return err
+	}
+	if r.exportConfigMap != "" {
+		if err := r.exportToConfigMap(ctx, def, string(manifest)); err != nil {
+			return err
+		}
+	}
+	if r.exportDir != "" {
+		return exportToDir(ctx, r.exportDir, def, manifest)
+	}
+	return nil
+}
+
+func (r *Reconciler) exportToConfigMap(ctx context.Context, def *v1beta1.WorkflowStepDefinition, manifest string) error {
+	key := client.ObjectKey{Namespace: oam.SystemDefinitionNamespace, Name: r.exportConfigMap}
+	dataKey := definitionExportKey(def)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm := &corev1.ConfigMap{}
+		err := r.Get(ctx, key, cm)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Data:       map[string]string{dataKey: manifest},
+			}
+			if err := r.Create(ctx, cm); err != nil {
+				return err
+			}
+			reconcileLogger(ctx).Info("Exported WorkflowStepDefinition to ConfigMap", "workflowStepDefinition", klog.KObj(def), "configMap", klog.KObj(cm))
+			return nil
+		}
+		if current, ok := cm.Data[dataKey]; ok && current == manifest {
+			return nil
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[dataKey] = manifest
+		if err := r.Update(ctx, cm); err != nil {
+			return err
+		}
+		reconcileLogger(ctx).Info("Exported WorkflowStepDefinition to ConfigMap", "workflowStepDefinition", klog.KObj(def), "configMap", klog.KObj(cm))
+		return nil
+	})
+}
+
+// exportToDir writes the manifest to <dir>/<namespace>/<name>.yaml, e.g. in the volume synced with Git. The file is
+// replaced by renaming so the readers never see a partial one.
+func exportToDir(ctx context.Context, dir string, def *v1beta1.WorkflowStepDefinition, manifest []byte) error {
+	path := filepath.Join(dir, def.Namespace, def.Name+".yaml")
+	if current, err := os.ReadFile(filepath.Clean(path)); err == nil && bytes.Equal(current, manifest) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+def.Name+"-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(manifest); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	reconcileLogger(ctx).Info("Exported WorkflowStepDefinition to file", "workflowStepDefinition", klog.KObj(def), "path", path)
+	return nil
+}
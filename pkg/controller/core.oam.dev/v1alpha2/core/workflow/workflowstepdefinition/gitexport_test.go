@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition definition export", func() {
+	It("Export definition", func() {
+		ctx := context.Background()
+		dir, err := os.MkdirTemp("", "definition-export")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		def := newTestDefinition("exported", simpleTemplate)
+		def.SetLabels(map[string]string{"team": "platform"})
+		r := newTestReconciler(options{exportConfigMap: "definition-export", exportDir: dir}, def)
+
+		expectExported := func(template string) {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: oam.SystemDefinitionNamespace, Name: "definition-export"}, cm)).Should(Succeed())
+			fromFile, err := os.ReadFile(filepath.Join(dir, def.Namespace, def.Name+".yaml"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(fromFile)).Should(Equal(cm.Data[definitionExportKey(def)]))
+
+			exported := &v1beta1.WorkflowStepDefinition{}
+			Expect(yaml.Unmarshal(fromFile, exported)).Should(Succeed())
+			Expect(exported.Kind).Should(Equal(v1beta1.WorkflowStepDefinitionKind))
+			Expect(exported.Name).Should(Equal(def.Name))
+			Expect(exported.Labels).Should(BeEmpty())
+			Expect(exported.ResourceVersion).Should(BeEmpty())
+			Expect(exported.Spec.Schematic.CUE.Template).Should(Equal(template))
+		}
+		got := reconcileTestDefinition(r, def)
+		expectExported(simpleTemplate)
+
+		// the export follows the spec change
+		changed := `
+parameter: {
+	name:     string
+	replicas: *1 | int
+}
+`
+		got.Spec.Schematic.CUE.Template = changed
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		reconcileTestDefinition(r, got)
+		expectExported(changed)
+	})
+})
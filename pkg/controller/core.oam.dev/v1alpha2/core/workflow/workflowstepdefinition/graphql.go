@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// graphQLKey is the data key of the GraphQL input types of the parameter in the schema ConfigMap
+const graphQLKey = "parameters.graphql"
+
+// graphQLScalarJSON is the custom scalar standing for the free-form objects and the untyped values
+const graphQLScalarJSON = "JSON"
+
+var graphQLInvalidNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+// graphQLWriter collects the input types generated from the parameter schema, the nested objects become their own
+// input types named after the path, e.g. ApplyObjectResourcesInput for the resources of apply-object
+type graphQLWriter struct {
+	types    []string
+	usesJSON bool
+}
+
+// generateGraphQL generates the GraphQL input types of the parameter, e.g. ApplyObjectInput for apply-object
+func generateGraphQL(actx *artifactContext) (string, error) {
+	w := &graphQLWriter{}
+	w.writeInput(pascalCase(actx.def.Name), actx.def.GetAnnotations()[types.AnnoDefinitionDescription], actx.schema)
+	var sb strings.Builder
+	if w.usesJSON {
+		sb.WriteString(fmt.Sprintf("scalar %s\n\n", graphQLScalarJSON))
+	}
+	sb.WriteString(strings.Join(w.types, "\n"))
+	return sb.String(), nil
+}
+
+// writeInput writes the input type of the object schema and returns its name, the type comes before its nested types
+func (w *graphQLWriter) writeInput(prefix, description string, schema *openapi3.Schema) string {
+	name := prefix + "Input"
+	index := len(w.types)
+	w.types = append(w.types, "")
+
+	var sb strings.Builder
+	writeGraphQLDescription(&sb, description, "")
+	sb.WriteString(fmt.Sprintf("input %s {\n", name))
+	required := sets.NewString(schema.Required...)
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	for _, prop := range props {
+		ref := schema.Properties[prop]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		s := ref.Value
+		writeGraphQLDescription(&sb, s.Description, "  ")
+		fieldType := w.fieldType(prefix+pascalCase(graphQLName(prop)), s)
+		if isRequiredParameter(parameterField{Required: required.Has(prop), Schema: s}) {
+			fieldType += "!"
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s%s\n", graphQLName(prop), fieldType, graphQLDefault(s)))
+	}
+	sb.WriteString("}\n")
+	w.types[index] = sb.String()
+	return name
+}
+
+func (w *graphQLWriter) fieldType(prefix string, schema *openapi3.Schema) string {
+	switch schema.Type {
+	case openapi3.TypeString:
+		return "String"
+	case openapi3.TypeInteger:
+		return "Int"
+	case openapi3.TypeNumber:
+		return "Float"
+	case openapi3.TypeBoolean:
+		return "Boolean"
+	case openapi3.TypeArray:
+		if schema.Items != nil && schema.Items.Value != nil {
+			return "[" + w.fieldType(prefix+"Item", schema.Items.Value) + "]"
+		}
+	case openapi3.TypeObject:
+		if len(schema.Properties) > 0 {
+			return w.writeInput(prefix, "", schema)
+		}
+	}
+	w.usesJSON = true
+	return graphQLScalarJSON
+}
+
+// graphQLDefault returns the default value clause of the scalar field, the defaults of lists and objects are omitted
+func graphQLDefault(schema *openapi3.Schema) string {
+	switch schema.Default.(type) {
+	case string, bool, float64:
+		data, err := json.Marshal(schema.Default)
+		if err != nil {
+			return ""
+		}
+		return " = " + string(data)
+	}
+	return ""
+}
+
+func writeGraphQLDescription(sb *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	// the JSON string escapes are valid in the GraphQL strings
+	data, err := json.Marshal(description)
+	if err != nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s%s\n", indent, data))
+}
+
+// graphQLName converts the parameter name to a valid GraphQL name, the invalid characters are replaced by underscores
+func graphQLName(name string) string {
+	name = graphQLInvalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Test WorkflowStepDefinition GraphQL schema", func() {
+	It("Graph QL", func() {
+		def := newTestDefinition("apply-job", `
+parameter: {
+	// +usage=The image of the job
+	image: string
+	replicas: *1 | int
+	ratio?: number
+	debug: *false | bool
+	args?: [...string]
+	labels?: [string]: string
+	resources?: {
+		cpu:     string
+		memory?: string
+	}
+}
+`)
+		r := newTestReconciler(options{graphQL: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data[graphQLKey]).Should(Equal(`scalar JSON
+
+input ApplyJobInput {
+  args: [String]
+  debug: Boolean = false
+  "The image of the job"
+  image: String!
+  labels: JSON
+  ratio: Float
+  replicas: Int = 1
+  resources: ApplyJobResourcesInput
+}
+
+input ApplyJobResourcesInput {
+  cpu: String!
+  memory: String
+}
+`))
+	})
+})
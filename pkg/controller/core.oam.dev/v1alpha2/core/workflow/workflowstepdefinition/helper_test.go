@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	oamctrl "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// testNamespaces are the namespaces the specs create their objects in, they're cleaned up after every spec
+var testNamespaces = sets.NewString()
+
+// newTestDefinition creates a WorkflowStepDefinition with the cue template in the default namespace
+func newTestDefinition(name, template string) *v1beta1.WorkflowStepDefinition {
+	def := &v1beta1.WorkflowStepDefinition{}
+	def.SetGroupVersionKind(v1beta1.WorkflowStepDefinitionGroupVersionKind)
+	def.SetName(name)
+	def.SetNamespace("default")
+	def.Spec.Schematic = &common.Schematic{CUE: &common.CUE{Template: template}}
+	return def
+}
+
+// newTestReconciler creates a Reconciler with the options against the test environment, the given objects are created
+// in it beforehand. The system namespace is created along as some of the options write to it.
+func newTestReconciler(opts options, objs ...client.Object) *Reconciler {
+	if opts.defRevLimit == 0 {
+		opts.defRevLimit = defRevisionLimit
+	}
+	if opts.catalogIndexer == nil {
+		opts.catalogIndexer = oamctrl.NopCatalogIndexer{}
+	}
+	ensureTestNamespace(oam.SystemDefinitionNamespace)
+	if opts.schemaNamespace != "" {
+		ensureTestNamespace(opts.schemaNamespace)
+	}
+	createTestObjects(objs...)
+	return &Reconciler{
+		Client:  k8sClient,
+		Scheme:  scheme.Scheme,
+		record:  event.NewNopRecorder(),
+		options: opts,
+	}
+}
+
+// createTestObjects creates the objects along with the status of the WorkflowStepDefinitions, which the creation
+// ignores, their namespaces are created on demand
+func createTestObjects(objs ...client.Object) {
+	ctx := context.Background()
+	for _, obj := range objs {
+		if obj.GetNamespace() != "" {
+			ensureTestNamespace(obj.GetNamespace())
+		}
+		def, ok := obj.(*v1beta1.WorkflowStepDefinition)
+		var status v1beta1.WorkflowStepDefinitionStatus
+		if ok {
+			status = *def.Status.DeepCopy()
+		}
+		Expect(k8sClient.Create(ctx, obj)).Should(Succeed())
+		if ok && !reflect.DeepEqual(status, v1beta1.WorkflowStepDefinitionStatus{}) {
+			def.Status = status
+			Expect(k8sClient.Status().Update(ctx, def)).Should(Succeed())
+		}
+	}
+}
+
+// ensureTestNamespace creates the namespace if it doesn't exist and cleans it up after the spec
+func ensureTestNamespace(namespace string) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	Expect(k8sClient.Create(context.Background(), ns)).Should(SatisfyAny(Succeed(), &util.AlreadyExistMatcher{}))
+	testNamespaces.Insert(namespace)
+}
+
+// cleanupTestNamespaces deletes the WorkflowStepDefinitions along with their finalizers, the DefinitionRevisions and
+// the ConfigMaps the spec left in the test namespaces, as the test environment doesn't delete the namespaces
+func cleanupTestNamespaces() {
+	ctx := context.Background()
+	for _, namespace := range testNamespaces.List() {
+		defList := &v1beta1.WorkflowStepDefinitionList{}
+		Expect(k8sClient.List(ctx, defList, client.InNamespace(namespace))).Should(Succeed())
+		for i := range defList.Items {
+			def := &defList.Items[i]
+			if len(def.Finalizers) > 0 {
+				def.Finalizers = nil
+				Expect(client.IgnoreNotFound(k8sClient.Update(ctx, def))).Should(Succeed())
+			}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, def))).Should(Succeed())
+		}
+		Expect(k8sClient.DeleteAllOf(ctx, &v1beta1.DefinitionRevision{}, client.InNamespace(namespace))).Should(Succeed())
+		Expect(k8sClient.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace(namespace))).Should(Succeed())
+	}
+}
+
+// reconcileTestDefinition reconciles the definition and returns its latest state
+func reconcileTestDefinition(r *Reconciler, def *v1beta1.WorkflowStepDefinition) *v1beta1.WorkflowStepDefinition {
+	ctx := context.Background()
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)})
+	Expect(err).ShouldNot(HaveOccurred())
+	latest := &v1beta1.WorkflowStepDefinition{}
+	Expect(r.Get(ctx, client.ObjectKeyFromObject(def), latest)).Should(Succeed())
+	return latest
+}
+
+// renderTestSchema renders the parameter schema of the cue template
+func renderTestSchema(t require.TestingT, template string) *openapi3.Schema {
+	def := utils.NewCapabilityStepDef(newTestDefinition("test", template))
+	_, schema, err := renderParameterSchema(&def)
+	require.NoError(t, err)
+	return schema
+}
+
+// recordingRecorder records the events and the objects they are recorded on in memory
+type recordingRecorder struct {
+	events  []event.Event
+	objects []runtime.Object
+}
+
+func (r *recordingRecorder) Event(obj runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+	r.objects = append(r.objects, obj)
+}
+
+func (r *recordingRecorder) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return &annotatingRecorder{recorder: r, annotations: keysAndValues}
+}
+
+// annotatingRecorder sets the annotations on the events before recording them
+type annotatingRecorder struct {
+	recorder    *recordingRecorder
+	annotations []string
+}
+
+func (r *annotatingRecorder) Event(obj runtime.Object, e event.Event) {
+	annotations := map[string]string{}
+	for k, v := range e.Annotations {
+		annotations[k] = v
+	}
+	for i := 0; i+1 < len(r.annotations); i += 2 {
+		annotations[r.annotations[i]] = r.annotations[i+1]
+	}
+	e.Annotations = annotations
+	r.recorder.Event(obj, e)
+}
+
+func (r *annotatingRecorder) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return &annotatingRecorder{recorder: r.recorder, annotations: append(append([]string{}, r.annotations...), keysAndValues...)}
+}
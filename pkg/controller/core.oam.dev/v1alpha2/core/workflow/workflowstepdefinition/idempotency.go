@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// idempotentExtension is the extension of the stored schema surfacing the idempotency declared by the step
+const idempotentExtension = "x-idempotent"
+
+// sideEffectOperations matches the workflow operations changing the resources or the outside world in the template
+var sideEffectOperations = regexp.MustCompile(`\.#(Apply\w*|Delete|Patch\w*|HTTPDo|HTTPPost|HTTPPut|HTTPDelete|SendEmail)\b`)
+
+// declaredIdempotency returns the idempotency declared by the annotation of the definition, nil if it's not declared
+func declaredIdempotency(def *v1beta1.WorkflowStepDefinition) (*bool, error) {
+	value, ok := def.GetAnnotations()[oam.AnnotationIdempotent]
+	if !ok {
+		return nil, nil
+	}
+	idempotent, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of the annotation %s, must be true or false", value, oam.AnnotationIdempotent)
+	}
+	return &idempotent, nil
+}
+
+// lintIdempotency flags the invalid idempotency declaration and the step running the side-effecting operations without
+// declaring its idempotency
+func lintIdempotency(lctx *lintContext) []lintFinding {
+	idempotent, err := declaredIdempotency(lctx.def)
+	if err != nil {
+		return []lintFinding{{Rule: "idempotency", Severity: lintSeverityWarning, Message: err.Error()}}
+	}
+	schematic := lctx.def.Spec.Schematic
+	if idempotent != nil || schematic == nil || schematic.CUE == nil {
+		return nil
+	}
+	operations := sets.NewString()
+	for _, match := range sideEffectOperations.FindAllStringSubmatch(schematic.CUE.Template, -1) {
+		operations.Insert("#" + match[1])
+	}
+	if operations.Len() == 0 {
+		return nil
+	}
+	return []lintFinding{{
+		Rule:     "idempotency",
+		Severity: lintSeverityWarning,
+		Message: fmt.Sprintf("the step runs the side-effecting operations %s without declaring the idempotency by the annotation %s",
+			strings.Join(operations.List(), ", "), oam.AnnotationIdempotent),
+	}}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const applyTemplate = `
+import "vela/op"
+
+apply: op.#Apply & {
+	value: parameter.value
+}
+parameter: {
+	value: {...}
+}
+`
+
+var _ = Describe("Test WorkflowStepDefinition idempotent reconcile", func() {
+	It("Idempotency", func() {
+		ctx := context.Background()
+		def := newTestDefinition("apply-idempotent", applyTemplate)
+		def.SetAnnotations(map[string]string{oam.AnnotationIdempotent: "true"})
+		r := newTestReconciler(options{checkIdempotency: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Idempotent).ShouldNot(BeNil())
+		Expect(*got.Status.Idempotent).Should(BeTrue())
+		Expect(got.Status.Warnings).Should(BeEmpty())
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		schema := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(cm.Data[types.OpenapiV3JSONSchema]), &schema)).Should(Succeed())
+		Expect(schema[idempotentExtension]).Should(Equal(true))
+
+		undeclared := newTestDefinition("apply-undeclared", applyTemplate)
+		r = newTestReconciler(options{checkIdempotency: true}, undeclared)
+		got = reconcileTestDefinition(r, undeclared)
+		Expect(got.Status.Idempotent).Should(BeNil())
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[idempotency] the step runs the side-effecting operations #Apply without declaring the idempotency by the annotation " + oam.AnnotationIdempotent,
+		}))
+	})
+})
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+// prepareImmutableSchema generates the artifacts up front to be stored along with the schema, since the immutable
+// ConfigMap can't be updated afterwards, and returns the name of the ConfigMap storing them
+func (r *Reconciler) prepareImmutableSchema(def *utils.CapabilityStepDefinition, actx *artifactContext) (string, error) {
+	artifacts, err := r.generateArtifacts(actx)
+	if err != nil {
+		return "", err
+	}
+	jsonSchema, err := def.RenderOpenAPISchema(def.Name)
+	if err != nil {
+		return "", err
+	}
+	if r.checksums {
+		data := map[string]string{types.OpenapiV3JSONSchema: string(jsonSchema)}
+		for key, content := range artifacts {
+			data[key] = content
+		}
+		artifacts[checksumsKey] = schemaChecksums(data)
+	}
+	def.Data = artifacts
+	return def.ImmutableSchemaConfigMapName(jsonSchema)
+}
+
+// collectImmutableConfigMaps deletes the previous immutable schema ConfigMaps of the definition beyond the revision
+// limit, the newest ones are retained for the consumers still reading them. The mutable ConfigMap stored before the
+// upgrade counts as a previous one.
+func (r *Reconciler) collectImmutableConfigMaps(ctx context.Context, def *v1beta1.WorkflowStepDefinition, cmName string) error {
+	cmList := &corev1.ConfigMapList{}
+	if err := r.List(ctx, cmList, client.InNamespace(r.schemaNamespaceOf(def)),
+		client.MatchingLabels{types.LabelDefinition: "schema", types.LabelDefinitionName: def.Name}); err != nil {
+		return err
+	}
+	var previous []corev1.ConfigMap
+	for i := range cmList.Items {
+		if cm := &cmList.Items[i]; cm.Name != cmName && linkedConfigMap(cm, def) {
+			previous = append(previous, *cm)
+		}
+	}
+	limit := r.defRevLimit
+	if limit < 0 {
+		limit = 0
+	}
+	if len(previous) <= limit {
+		return nil
+	}
+	sort.Slice(previous, func(i, j int) bool {
+		if !previous[i].CreationTimestamp.Equal(&previous[j].CreationTimestamp) {
+			return previous[j].CreationTimestamp.Before(&previous[i].CreationTimestamp)
+		}
+		return previous[i].Name > previous[j].Name
+	})
+	var collected []string
+	for i := range previous[limit:] {
+		cm := &previous[limit+i]
+		if err := r.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		collected = append(collected, cm.Name)
+	}
+	reconcileLogger(ctx).Info("Collected the previous schema ConfigMaps of WorkflowStepDefinition beyond the revision limit", "workflowStepDefinition", klog.KObj(def),
+		"namespace", r.schemaNamespaceOf(def), "configMaps", collected)
+	return nil
+}
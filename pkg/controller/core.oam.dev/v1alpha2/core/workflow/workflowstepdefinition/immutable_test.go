@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition immutable schema ConfigMaps", func() {
+	It("Immutable schema config maps", func() {
+		ctx := context.Background()
+		def := newTestDefinition("frozen", simpleTemplate)
+		r := newTestReconciler(options{immutableSchemas: true, usageSnippet: true, checksums: true, defRevLimit: 1}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(strings.HasPrefix(got.Status.ConfigMapRef, "workflowstep-schema-frozen-")).Should(BeTrue())
+
+		// the artifacts are stored along with the schema at once
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Immutable).Should(Equal(pointer.BoolPtr(true)))
+		Expect(cm.Data[types.OpenapiV3JSONSchema]).ShouldNot(BeEmpty())
+		Expect(cm.Data[usageSnippetKey]).ShouldNot(BeEmpty())
+		Expect(cm.Data[checksumsKey]).Should(Equal(schemaChecksums(cm.Data)))
+		revCM := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-frozen-v1"}, revCM)).Should(Succeed())
+		Expect(revCM.Immutable).Should(Equal(pointer.BoolPtr(true)))
+
+		// the unchanged schema is not written again
+		got = reconcileTestDefinition(r, got)
+		stored := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(cm), stored)).Should(Succeed())
+		Expect(stored.ResourceVersion).Should(Equal(cm.ResourceVersion))
+
+		// a changed schema is stored in a new ConfigMap, the previous ones are retained up to the revision limit
+		refs := []string{got.Status.ConfigMapRef}
+		for i := 1; i <= 2; i++ {
+			got.Spec.Schematic.CUE.Template = fmt.Sprintf("parameter: {\n\tname: string\n\tfield%d: string\n}\n", i)
+			Expect(r.Update(ctx, got)).Should(Succeed())
+			got = reconcileTestDefinition(r, got)
+			Expect(refs).ShouldNot(ContainElement(got.Status.ConfigMapRef))
+			refs = append(refs, got.Status.ConfigMapRef)
+		}
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"), client.MatchingLabels{types.LabelDefinitionName: "frozen"})).Should(Succeed())
+		var names []string
+		for _, cm := range cmList.Items {
+			Expect(cm.Immutable).Should(Equal(pointer.BoolPtr(true)))
+			names = append(names, cm.Name)
+		}
+		Expect(names).Should(HaveLen(2))
+		Expect(names).Should(ContainElement(got.Status.ConfigMapRef))
+	})
+
+	It("Immutable schema config maps upgrade", func() {
+		ctx := context.Background()
+		def := newTestDefinition("upgraded", simpleTemplate)
+		r := newTestReconciler(options{defRevLimit: 1}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(Equal("workflowstep-schema-upgraded"))
+
+		// the mutable ConfigMaps stored before the upgrade keep working: the one of the revision is turned immutable and
+		// the one of the definition is retained as a previous one
+		r.immutableSchemas = true
+		got = reconcileTestDefinition(r, got)
+		Expect(strings.HasPrefix(got.Status.ConfigMapRef, "workflowstep-schema-upgraded-")).Should(BeTrue())
+		for _, name := range []string{"workflowstep-schema-upgraded", "workflowstep-schema-upgraded-v1"} {
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, &corev1.ConfigMap{})).Should(Succeed())
+		}
+		revCM := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-upgraded-v1"}, revCM)).Should(Succeed())
+		Expect(revCM.Immutable).Should(Equal(pointer.BoolPtr(true)))
+
+		// the immutable ConfigMap of the revision storing a different schema is replaced
+		r.schemaIDBaseURL = "https://schemas.example.com"
+		got = reconcileTestDefinition(r, got)
+		replaced := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(revCM), replaced)).Should(Succeed())
+		Expect(replaced.Immutable).Should(Equal(pointer.BoolPtr(true)))
+		Expect(replaced.Data[types.OpenapiV3JSONSchema]).Should(ContainSubstring("https://schemas.example.com/upgraded/v1"))
+
+		// the mutable ConfigMap is collected beyond the revision limit
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"), client.MatchingLabels{types.LabelDefinitionName: "upgraded"})).Should(Succeed())
+		Expect(cmList.Items).Should(HaveLen(2))
+		for _, cm := range cmList.Items {
+			Expect(cm.Name).ShouldNot(Equal("workflowstep-schema-upgraded"))
+		}
+	})
+
+	It("Collect immutable config maps", func() {
+		ctx := context.Background()
+		def := newTestDefinition("ordered", simpleTemplate)
+		r := newTestReconciler(options{defRevLimit: 1}, def)
+		previous := func(name string) *corev1.ConfigMap {
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+				Labels: map[string]string{types.LabelDefinition: "schema", types.LabelDefinitionName: "ordered"}}}
+			cm.OwnerReferences = []metav1.OwnerReference{{APIVersion: "core.oam.dev/v1beta1", Kind: "WorkflowStepDefinition",
+				Name: "ordered", UID: def.UID, Controller: pointer.BoolPtr(true)}}
+			return cm
+		}
+		// the ConfigMaps of another definition are left alone
+		foreign := previous("workflowstep-schema-ordered-foreign")
+		foreign.OwnerReferences[0].UID = "another-uid"
+		createTestObjects(foreign)
+		// the creation timestamps ordering the ConfigMaps are in seconds
+		for _, name := range []string{"older", "newer", "current"} {
+			time.Sleep(time.Second)
+			createTestObjects(previous(name))
+		}
+		Expect(r.collectImmutableConfigMaps(ctx, def, "current")).Should(Succeed())
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"))).Should(Succeed())
+		var names []string
+		for _, cm := range cmList.Items {
+			names = append(names, cm.Name)
+		}
+		Expect(names).Should(ConsistOf([]string{"current", "newer", "workflowstep-schema-ordered-foreign"}))
+	})
+})
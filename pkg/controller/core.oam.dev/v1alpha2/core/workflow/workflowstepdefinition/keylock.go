@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// keyedMutex serializes the reconciliations of the same definition while the different definitions proceed in
+// parallel, the zero value is ready to use
+type keyedMutex struct {
+	mu sync.Mutex
+	// locks are the per-definition locks, they're removed once nobody holds or waits for them
+	locks map[types.NamespacedName]*refMutex
+}
+
+// refMutex is the lock of a single definition counting its holder and waiters, it's held while its channel is full
+type refMutex struct {
+	held chan struct{}
+	refs int
+}
+
+// lock blocks until the lock of the definition is acquired and returns the func releasing it, the waiting is given up
+// with the error of the context once it's done
+func (k *keyedMutex) lock(ctx context.Context, key types.NamespacedName) (unlock func(), err error) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[types.NamespacedName]*refMutex{}
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &refMutex{held: make(chan struct{}, 1)}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	release := func() {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+	}
+	select {
+	case l.held <- struct{}{}:
+		return func() {
+			<-l.held
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	ctx := context.Background()
+	var k keyedMutex
+	a := types.NamespacedName{Namespace: "default", Name: "a"}
+	b := types.NamespacedName{Namespace: "default", Name: "b"}
+
+	// the same definition is serialized
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := k.lock(ctx, a)
+			require.NoError(t, err)
+			defer unlock()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), maxRunning)
+
+	// the different definitions proceed in parallel
+	unlockA, err := k.lock(ctx, a)
+	require.NoError(t, err)
+	acquired := make(chan struct{})
+	go func() {
+		if unlockB, err := k.lock(ctx, b); err == nil {
+			unlockB()
+			close(acquired)
+		}
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the lock of another definition is blocked")
+	}
+
+	// the waiting is given up once the context is done
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = k.lock(timeoutCtx, a)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	unlockA()
+
+	// the released locks don't pile up
+	require.Empty(t, k.locks)
+}
+
+var _ = Describe("Test WorkflowStepDefinition reconcile lock", func() {
+	It("Reconcile releases lock", func() {
+		def := newTestDefinition("locked", "parameter: {")
+		r := newTestReconciler(options{}, def)
+		// the lock is released on the error return as well as the successful one
+		reconcileTestDefinition(r, def)
+		Expect(r.locks.locks).Should(BeEmpty())
+
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "missing"}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(r.locks.locks).Should(BeEmpty())
+	})
+
+	It("Reconcile lock timeout", func() {
+		def := newTestDefinition("locked", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		r.reconcileTimeout = 10 * time.Millisecond
+		unlock, err := r.locks.lock(context.Background(), client.ObjectKeyFromObject(def))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer unlock()
+		// the reconciliation waiting for the lock is bounded by the reconcile timeout
+		_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)})
+		Expect(err).Should(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// paramLifecycleKey is the data key of the parameter lifecycle summary in the schema ConfigMap
+const paramLifecycleKey = "param-lifecycle.json"
+
+// paramLifecycle summarizes how the parameters change across the retained revisions
+type paramLifecycle struct {
+	// Revisions are the retained revisions taken into account, in ascending order
+	Revisions []int64 `json:"revisions"`
+	// Stable are the parameters present in all the retained revisions
+	Stable []string `json:"stable"`
+	// New are the parameters present in the latest revision but missing in some earlier ones
+	New []string `json:"new"`
+	// Removed are the parameters present in some earlier revisions but missing in the latest one
+	Removed []string `json:"removed"`
+}
+
+// generateParamLifecycle computes the parameter lifecycle across the retained revisions of the definition,
+// the revisions beyond the revision limit are ignored even if they have not been cleaned up yet
+func (r *Reconciler) generateParamLifecycle(actx *artifactContext) (string, error) {
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := r.List(actx.ctx, revList, client.InNamespace(actx.def.Namespace),
+		client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: actx.def.Name}); err != nil {
+		return "", err
+	}
+	revisions := revList.Items
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Spec.Revision < revisions[j].Spec.Revision })
+	if limit := r.defRevLimit + 1; len(revisions) > limit {
+		revisions = revisions[len(revisions)-limit:]
+	}
+
+	lifecycle := paramLifecycle{Revisions: []int64{}, Stable: []string{}, New: []string{}, Removed: []string{}}
+	var latest sets.String
+	seen := map[string]int{}
+	cached := r.revisionParams.get(client.ObjectKeyFromObject(actx.def))
+	current := make(map[string]revisionParams, len(revisions))
+	for i := range revisions {
+		rev := &revisions[i]
+		entry, ok := cached[rev.Name]
+		if !ok || entry.hash != rev.Spec.RevisionHash {
+			params, err := revisionParameters(rev)
+			if err != nil {
+				return "", err
+			}
+			entry = revisionParams{hash: rev.Spec.RevisionHash, paths: params}
+		}
+		current[rev.Name] = entry
+		for param := range entry.paths {
+			seen[param]++
+		}
+		lifecycle.Revisions = append(lifecycle.Revisions, rev.Spec.Revision)
+		latest = entry.paths
+	}
+	for param, count := range seen {
+		switch {
+		case !latest.Has(param):
+			lifecycle.Removed = append(lifecycle.Removed, param)
+		case count == len(revisions):
+			lifecycle.Stable = append(lifecycle.Stable, param)
+		default:
+			lifecycle.New = append(lifecycle.New, param)
+		}
+	}
+	r.revisionParams.set(client.ObjectKeyFromObject(actx.def), current)
+	sort.Strings(lifecycle.Stable)
+	sort.Strings(lifecycle.New)
+	sort.Strings(lifecycle.Removed)
+	data, err := json.MarshalIndent(lifecycle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// revisionParams are the parameters of the revision of the hash
+type revisionParams struct {
+	hash  string
+	paths sets.String
+}
+
+// revisionParamsCache caches the parameters of the retained revisions by the definition and the revision name, so that
+// the revisions are not rendered again on every reconciliation. The entries of a definition are replaced by its
+// retained revisions on every generation, so the collected revisions are dropped along.
+type revisionParamsCache struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]map[string]revisionParams
+}
+
+func (c *revisionParamsCache) get(key types.NamespacedName) map[string]revisionParams {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key]
+}
+
+func (c *revisionParamsCache) set(key types.NamespacedName, params map[string]revisionParams) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[types.NamespacedName]map[string]revisionParams{}
+	}
+	c.entries[key] = params
+}
+
+// forget drops the cached parameters of the deleted definition
+func (c *revisionParamsCache) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// revisionParameters returns the paths of all the parameters in the definition snapshot of the revision
+func revisionParameters(rev *v1beta1.DefinitionRevision) (sets.String, error) {
+	schema, err := revisionSchema(rev)
+	if err != nil {
+		return nil, err
+	}
+	params := sets.NewString()
+	for _, field := range flattenParameters(schema) {
+		params.Insert(field.Path)
+	}
+	return params, nil
+}
+
+// revisionSchema renders the parameter schema of the definition snapshot of the revision
+func revisionSchema(rev *v1beta1.DefinitionRevision) (*openapi3.Schema, error) {
+	def := utils.NewCapabilityStepDef(&rev.Spec.WorkflowStepDefinition)
+	def.Name = rev.Spec.WorkflowStepDefinition.Name
+	_, schema, err := renderParameterSchema(&def)
+	return schema, err
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Test WorkflowStepDefinition parameter lifecycle", func() {
+	It("Param lifecycle", func() {
+		ctx := context.Background()
+		def := newTestDefinition("lifecycle", `
+parameter: {
+	value: {...}
+	cluster: *"" | string
+}
+`)
+		r := newTestReconciler(options{paramLifecycle: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	value: {...}
+	namespace?: string
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Revision).Should(Equal(int64(2)))
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		lifecycle := paramLifecycle{}
+		Expect(json.Unmarshal([]byte(cm.Data[paramLifecycleKey]), &lifecycle)).Should(Succeed())
+		Expect(lifecycle).Should(Equal(paramLifecycle{
+			Revisions: []int64{1, 2},
+			Stable:    []string{"value"},
+			New:       []string{"namespace"},
+			Removed:   []string{"cluster"},
+		}))
+
+		// the retained revisions are rendered once
+		cached := r.revisionParams.get(client.ObjectKeyFromObject(got))
+		Expect(cached).Should(HaveLen(2))
+		Expect(cached["lifecycle-v2"].hash).Should(Equal(got.Status.LatestRevision.RevisionHash))
+		got = reconcileTestDefinition(r, got)
+		for name, entry := range r.revisionParams.get(client.ObjectKeyFromObject(got)) {
+			Expect(cached[name].paths.Equal(entry.paths)).Should(BeTrue())
+		}
+
+		// the deleted definition is forgotten
+		Expect(r.Delete(ctx, got)).Should(Succeed())
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(got)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(r.revisionParams.get(client.ObjectKeyFromObject(got))).Should(BeNil())
+	})
+})
@@ -0,0 +1,706 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kubevela/workflow/pkg/cue/model/value"
+	wfTypes "github.com/kubevela/workflow/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	velacue "github.com/oam-dev/kubevela/pkg/cue"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+// lintSeverity is the severity of a lint finding
+type lintSeverity string
+
+const (
+	// lintSeverityWarning findings are recorded in the status.warnings of the definition
+	lintSeverityWarning lintSeverity = "Warning"
+	// lintSeverityError findings block the reconciliation of the definition
+	lintSeverityError lintSeverity = "Error"
+)
+
+// DefaultReservedStepNames are the built-in workflow step types a WorkflowStepDefinition should not shadow
+var DefaultReservedStepNames = []string{
+	wfTypes.WorkflowStepTypeSuspend,
+	wfTypes.WorkflowStepTypeApplyComponent,
+	wfTypes.WorkflowStepTypeBuiltinApplyComponent,
+	wfTypes.WorkflowStepTypeStepGroup,
+}
+
+// DefaultBooleanTrueKeywords are the words clarifying the effect of setting a boolean parameter to true
+var DefaultBooleanTrueKeywords = []string{"true", "enable", "enables", "enabled", "if set", "when set", "yes"}
+
+// DefaultBooleanFalseKeywords are the words clarifying the effect of setting a boolean parameter to false
+var DefaultBooleanFalseKeywords = []string{"false", "disable", "disables", "disabled", "otherwise", "not set", "unset"}
+
+// integerNameHints are the last words of the parameter names intended as integers, the names are split into words by
+// the camel case and the non-alphanumeric characters, e.g. httpPort and max_retries are hinted but report is not
+var integerNameHints = []string{"count", "replicas", "port", "retries", "attempts", "size", "limit", "seconds", "index"}
+
+// lintFinding is a problem of the WorkflowStepDefinition found by a lint rule
+type lintFinding struct {
+	Rule     string
+	Severity lintSeverity
+	// Path is the field path of the parameter, it's empty for the findings of the definition itself
+	Path    string
+	Message string
+}
+
+func (f lintFinding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("[%s] %s", f.Rule, f.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Rule, f.Path, f.Message)
+}
+
+// lintContext is the input of the lint rules
+type lintContext struct {
+	ctx context.Context
+	def *v1beta1.WorkflowStepDefinition
+	// schema is the rendered parameter schema, it's nil if the schema cannot be rendered
+	schema *openapi3.Schema
+}
+
+// lintRule checks the WorkflowStepDefinition and reports the findings
+type lintRule func(lctx *lintContext) []lintFinding
+
+// lintRules returns the lint rules enabled by the options
+func (r *Reconciler) lintRules() []lintRule {
+	rules := []lintRule{lintExportFormats, lintSchemaProfiles, lintStability}
+	if r.reservedNames.Len() > 0 {
+		rules = append(rules, r.lintReservedName)
+	}
+	if r.strictCUE {
+		rules = append(rules, lintStrictCUE)
+	}
+	if len(r.requiredAnnotations) > 0 {
+		rules = append(rules, r.lintRequiredAnnotations)
+	}
+	if r.checkParameterBounds {
+		rules = append(rules, lintParameterBounds)
+	}
+	if r.maxDescriptionLength > 0 {
+		rules = append(rules, r.lintDescriptionLength)
+	}
+	if r.checkEnumDefaults {
+		rules = append(rules, lintEnumDefaults)
+	}
+	if r.maxEnumValues > 0 {
+		rules = append(rules, r.lintEnumSize)
+	}
+	if r.requireDescription {
+		rules = append(rules, r.lintDescription)
+	}
+	if len(r.forbiddenPatterns) > 0 {
+		rules = append(rules, r.lintForbiddenDescriptions)
+	}
+	if r.checkNameCollisions {
+		rules = append(rules, lintNameCollisions)
+	}
+	if r.maxGroupParameters > 0 {
+		rules = append(rules, r.lintGroupBudget)
+	}
+	if r.checkArrayItems {
+		rules = append(rules, lintArrayItems)
+	}
+	if r.paramDependencies {
+		rules = append(rules, lintParamDependencies)
+	}
+	if r.checkReferences {
+		rules = append(rules, r.lintReferences)
+	}
+	if r.checkTraitOverlap {
+		rules = append(rules, r.lintTraitOverlap)
+	}
+	if r.provenance {
+		rules = append(rules, lintProvenance)
+	}
+	if r.checkIdempotency {
+		rules = append(rules, lintIdempotency)
+	}
+	if r.checkResources {
+		rules = append(rules, lintResourceRequirements)
+	}
+	if r.checkExamples {
+		rules = append(rules, lintExamples)
+	}
+	if r.checkIntegerTypes {
+		rules = append(rules, lintIntegerTypes)
+	}
+	if r.checkDurations {
+		rules = append(rules, lintDurations)
+	}
+	if r.checkSecretDefaults {
+		rules = append(rules, lintSecretDefaults)
+	}
+	if r.checkUnusedParams || r.rejectUnusedParams {
+		rules = append(rules, r.lintUnusedParameters)
+	}
+	if len(r.booleanTrueKeywords) > 0 && len(r.booleanFalseKeywords) > 0 {
+		rules = append(rules, r.lintBooleanDescriptions)
+	}
+	return rules
+}
+
+// lint runs the enabled lint rules and splits the findings by severity
+func (r *Reconciler) lint(lctx *lintContext) (warnings []lintFinding, errs []lintFinding) {
+	for _, rule := range r.lintRules() {
+		for _, finding := range rule(lctx) {
+			if finding.Severity == lintSeverityError {
+				errs = append(errs, finding)
+			} else {
+				warnings = append(warnings, finding)
+			}
+		}
+	}
+	return warnings, errs
+}
+
+// recordLintWarnings sets the warnings in the status and emits them as events when they change
+func (r *Reconciler) recordLintWarnings(ctx context.Context, def *v1beta1.WorkflowStepDefinition, warnings []lintFinding, status *v1beta1.WorkflowStepDefinitionStatus) {
+	var messages []string
+	for _, w := range warnings {
+		messages = append(messages, w.String())
+	}
+	if !sets.NewString(status.Warnings...).Equal(sets.NewString(messages...)) {
+		for _, msg := range messages {
+			r.recorder(ctx).Event(def, event.Warning("WorkflowStepDefinition lint warning", fmt.Errorf("%s", msg)))
+		}
+	}
+	status.Warnings = messages
+}
+
+// lintError joins the error findings into one error
+func lintError(errs []lintFinding) error {
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.String())
+	}
+	return fmt.Errorf("lint failed: %s", strings.Join(messages, "; "))
+}
+
+// TypeLintHealthy is the condition aggregating the lint findings of the WorkflowStepDefinition, it's True only when
+// no error-severity finding exists. The individual findings are still recorded in the status.warnings
+const TypeLintHealthy condition.ConditionType = "LintHealthy"
+
+const (
+	reasonLintPassed condition.ConditionReason = "LintPassed"
+	reasonLintFailed condition.ConditionReason = "LintFailed"
+)
+
+// lintHealthyCondition summarizes the counts of the lint findings by severity in the LintHealthy condition
+func lintHealthyCondition(warnings, errs []lintFinding) condition.Condition {
+	c := condition.Condition{
+		Type:               TypeLintHealthy,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonLintPassed,
+		Message:            fmt.Sprintf("%d %s, %d %s findings", len(errs), lintSeverityError, len(warnings), lintSeverityWarning),
+	}
+	if len(errs) > 0 {
+		c.Status = corev1.ConditionFalse
+		c.Reason = reasonLintFailed
+	}
+	return c
+}
+
+// lintReservedName flags the definition whose name collides with the reserved built-in step types
+func (r *Reconciler) lintReservedName(lctx *lintContext) []lintFinding {
+	if !r.reservedNames.Has(lctx.def.Name) {
+		return nil
+	}
+	severity := lintSeverityWarning
+	if r.rejectReservedNames {
+		severity = lintSeverityError
+	}
+	return []lintFinding{{
+		Rule:     "reserved-name",
+		Severity: severity,
+		Message:  fmt.Sprintf("the name %s shadows the built-in workflow step type", lctx.def.Name),
+	}}
+}
+
+// lintStrictCUE compiles the template in strict mode, in which every field of the template is evaluated, so the incomplete
+// values used where concrete ones are expected, e.g. an operand of arithmetic or comparison, are reported as errors
+func lintStrictCUE(lctx *lintContext) []lintFinding {
+	schematic := lctx.def.Spec.Schematic
+	if schematic == nil || schematic.CUE == nil {
+		return nil
+	}
+	v, err := value.NewValue(schematic.CUE.Template+"\n"+velacue.BaseTemplate, nil, "")
+	if err == nil {
+		err = v.Error()
+	}
+	if err == nil {
+		return nil
+	}
+	return []lintFinding{{
+		Rule:     "strict-cue",
+		Severity: lintSeverityError,
+		Message:  fmt.Sprintf("the template cannot be compiled in strict mode: %s", err.Error()),
+	}}
+}
+
+// lintRequiredAnnotations flags the definition missing any of the required annotations, e.g. the license or ownership header
+func (r *Reconciler) lintRequiredAnnotations(lctx *lintContext) []lintFinding {
+	var missing []string
+	for _, key := range r.requiredAnnotations {
+		if _, ok := lctx.def.GetAnnotations()[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []lintFinding{{
+		Rule:     "required-annotations",
+		Severity: lintSeverityError,
+		Message:  fmt.Sprintf("missing the required annotations %s", strings.Join(missing, ", ")),
+	}}
+}
+
+// lintParameterBounds flags the unbounded parameters, i.e. the strings without maxLength and the numbers without minimum or maximum,
+// the enum parameters are bounded by their values
+func lintParameterBounds(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		s := field.Schema
+		if len(s.Enum) > 0 {
+			continue
+		}
+		var missing []string
+		switch s.Type {
+		case openapi3.TypeString:
+			if s.MaxLength == nil {
+				missing = append(missing, "maxLength")
+			}
+		case openapi3.TypeInteger, openapi3.TypeNumber:
+			if s.Min == nil {
+				missing = append(missing, "minimum")
+			}
+			if s.Max == nil {
+				missing = append(missing, "maximum")
+			}
+		}
+		if len(missing) > 0 {
+			findings = append(findings, lintFinding{
+				Rule:     "parameter-bounds",
+				Severity: lintSeverityWarning,
+				Path:     field.Path,
+				Message:  fmt.Sprintf("the %s parameter is unbounded, missing %s", s.Type, strings.Join(missing, " and ")),
+			})
+		}
+	}
+	return findings
+}
+
+// lintDescriptionLength flags the parameters whose description exceeds the max length
+func (r *Reconciler) lintDescriptionLength(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		if length := len([]rune(field.Schema.Description)); length > r.maxDescriptionLength {
+			findings = append(findings, lintFinding{
+				Rule:     "description-length",
+				Severity: lintSeverityWarning,
+				Path:     field.Path,
+				Message:  fmt.Sprintf("the description has %d characters, exceeding the max length %d", length, r.maxDescriptionLength),
+			})
+		}
+	}
+	return findings
+}
+
+// lintEnumSize flags the parameters whose enum has more values than the max count
+func (r *Reconciler) lintEnumSize(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		if size := len(field.Schema.Enum); size > r.maxEnumValues {
+			findings = append(findings, lintFinding{
+				Rule:     "enum-size",
+				Severity: lintSeverityWarning,
+				Path:     field.Path,
+				Message:  fmt.Sprintf("the enum has %d values, exceeding the max count %d, consider a free-form parameter validated by a dynamic source instead", size, r.maxEnumValues),
+			})
+		}
+	}
+	return findings
+}
+
+// lintEnumDefaults flags the enum parameters without a default value or whose default value is not one of the enum values
+func lintEnumDefaults(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		s := field.Schema
+		if len(s.Enum) == 0 {
+			continue
+		}
+		var message string
+		switch {
+		case s.Default == nil:
+			message = "the enum parameter has no default value"
+		case !containsValue(s.Enum, s.Default):
+			message = fmt.Sprintf("the default value %v is not one of the enum values", s.Default)
+		default:
+			continue
+		}
+		findings = append(findings, lintFinding{Rule: "enum-default", Severity: lintSeverityWarning, Path: field.Path, Message: message})
+	}
+	return findings
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintDescription flags the definition which doesn't declare the description
+func (r *Reconciler) lintDescription(lctx *lintContext) []lintFinding {
+	if strings.TrimSpace(lctx.def.GetAnnotations()[types.AnnoDefinitionDescription]) != "" {
+		return nil
+	}
+	severity := lintSeverityWarning
+	if r.rejectNoDescription {
+		severity = lintSeverityError
+	}
+	return []lintFinding{{
+		Rule:     "description",
+		Severity: severity,
+		Message:  fmt.Sprintf("the definition has no description, set it by the annotation %s", types.AnnoDefinitionDescription),
+	}}
+}
+
+// compileForbiddenPatterns compiles the patterns the descriptions must not match
+func compileForbiddenPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid forbidden description pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// lintForbiddenDescriptions flags the descriptions of the definition and its parameters matching any of the forbidden
+// patterns, e.g. the secrets or the internal hostnames. The matched content is not repeated in the finding.
+func (r *Reconciler) lintForbiddenDescriptions(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	check := func(path, description string) {
+		for _, re := range r.forbiddenPatterns {
+			if re.MatchString(description) {
+				findings = append(findings, lintFinding{
+					Rule:     "forbidden-description",
+					Severity: lintSeverityWarning,
+					Path:     path,
+					Message:  fmt.Sprintf("the description matches the forbidden pattern %s", re.String()),
+				})
+			}
+		}
+	}
+	check("", lctx.def.GetAnnotations()[types.AnnoDefinitionDescription])
+	for _, field := range flattenParameters(lctx.schema) {
+		check(field.Path, field.Schema.Description)
+	}
+	return findings
+}
+
+// lintNameCollisions flags the parameters whose paths collide case-insensitively, e.g. myParam and myparam
+func lintNameCollisions(lctx *lintContext) []lintFinding {
+	var order []string
+	collisions := map[string][]string{}
+	for _, field := range flattenParameters(lctx.schema) {
+		key := strings.ToLower(field.Path)
+		if _, ok := collisions[key]; !ok {
+			order = append(order, key)
+		}
+		collisions[key] = append(collisions[key], field.Path)
+	}
+	var findings []lintFinding
+	for _, key := range order {
+		if paths := collisions[key]; len(paths) > 1 {
+			findings = append(findings, lintFinding{
+				Rule:     "name-collision",
+				Severity: lintSeverityWarning,
+				Path:     paths[0],
+				Message:  fmt.Sprintf("the parameters %s collide case-insensitively", strings.Join(paths, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+// groupAttr is the CUE attribute declaring the group of a top-level parameter, e.g. @group("network")
+const groupAttr = "group"
+
+// parameterAttribute is the attribute of a top-level parameter of the template, the attribute is missing if Err is not nil
+type parameterAttribute struct {
+	parameter string
+	cue.Attribute
+}
+
+// parameterAttributes returns the attribute of the given name of all the top-level parameters of the template in
+// their declaration order
+func parameterAttributes(def *v1beta1.WorkflowStepDefinition, name string) ([]parameterAttribute, error) {
+	schematic := def.Spec.Schematic
+	if schematic == nil || schematic.CUE == nil {
+		return nil, nil
+	}
+	v, err := value.NewValue(schematic.CUE.Template, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	parameter, err := v.LookupValue(process.ParameterFieldName)
+	if err != nil {
+		// the template without parameter has no attribute
+		return nil, nil
+	}
+	iter, err := parameter.CueValue().Fields(cue.Optional(true))
+	if err != nil {
+		return nil, err
+	}
+	var attrs []parameterAttribute
+	for iter.Next() {
+		attrs = append(attrs, parameterAttribute{parameter: iter.Label(), Attribute: iter.Value().Attribute(name)})
+	}
+	return attrs, nil
+}
+
+// parameterGroups returns the top-level parameters of the template by the groups declared in their attributes
+func parameterGroups(def *v1beta1.WorkflowStepDefinition) (map[string][]string, error) {
+	attrs, err := parameterAttributes(def, groupAttr)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]string{}
+	for _, attr := range attrs {
+		if attr.Err() != nil {
+			continue
+		}
+		if group, err := attr.String(0); err == nil && group != "" {
+			groups[group] = append(groups[group], attr.parameter)
+		}
+	}
+	return groups, nil
+}
+
+// secretAttr is the CUE attribute marking a top-level parameter as sensitive, e.g. @secret()
+const secretAttr = "secret"
+
+// lintSecretDefaults flags the sensitive parameters given default values, which would leak the secrets baked into the
+// schema. The default values are not repeated in the findings
+func lintSecretDefaults(lctx *lintContext) []lintFinding {
+	if lctx.schema == nil {
+		return nil
+	}
+	attrs, err := parameterAttributes(lctx.def, secretAttr)
+	if err != nil {
+		// the template error is left to the schema rendering to report
+		return nil
+	}
+	var findings []lintFinding
+	for _, attr := range attrs {
+		if attr.Err() != nil {
+			continue
+		}
+		if ref := lctx.schema.Properties[attr.parameter]; ref != nil && ref.Value != nil && ref.Value.Default != nil {
+			findings = append(findings, lintFinding{
+				Rule:     "secret-default",
+				Severity: lintSeverityError,
+				Path:     attr.parameter,
+				Message:  "the sensitive parameter must not have a default value",
+			})
+		}
+	}
+	return findings
+}
+
+// lintGroupBudget flags the parameter groups containing more parameters than the budget
+func (r *Reconciler) lintGroupBudget(lctx *lintContext) []lintFinding {
+	groups, err := parameterGroups(lctx.def)
+	if err != nil {
+		// the template error is left to the schema rendering to report
+		return nil
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var findings []lintFinding
+	for _, name := range names {
+		if params := groups[name]; len(params) > r.maxGroupParameters {
+			findings = append(findings, lintFinding{
+				Rule:     "group-budget",
+				Severity: lintSeverityWarning,
+				Message: fmt.Sprintf("the group %s has %d parameters %s, exceeding the budget %d",
+					name, len(params), strings.Join(params, ", "), r.maxGroupParameters),
+			})
+		}
+	}
+	return findings
+}
+
+// lintArrayItems flags the array parameters without a concrete item schema, e.g. [...] or [..._]
+func lintArrayItems(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		s := field.Schema
+		if s.Type != openapi3.TypeArray {
+			continue
+		}
+		if items := s.Items; items != nil && items.Value != nil && (items.Value.Type != "" || len(items.Value.Enum) > 0 ||
+			len(items.Value.OneOf) > 0 || len(items.Value.AnyOf) > 0 || len(items.Value.AllOf) > 0) {
+			continue
+		}
+		findings = append(findings, lintFinding{
+			Rule:     "array-items",
+			Severity: lintSeverityWarning,
+			Path:     field.Path,
+			Message:  "the array parameter has no concrete item schema",
+		})
+	}
+	return findings
+}
+
+// lintBooleanDescriptions flags the boolean parameters whose description doesn't mention the effect of both true and
+// false, the states are recognized by the configured keywords as whole words, the parameters without description are
+// left to the description rule
+func (r *Reconciler) lintBooleanDescriptions(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		s := field.Schema
+		if s.Type != openapi3.TypeBoolean || s.Description == "" {
+			continue
+		}
+		description := normalizeWords(s.Description)
+		var missing []string
+		if !containsAnyWord(description, r.booleanTrueKeywords) {
+			missing = append(missing, "true")
+		}
+		if !containsAnyWord(description, r.booleanFalseKeywords) {
+			missing = append(missing, "false")
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		findings = append(findings, lintFinding{
+			Rule:     "boolean-description",
+			Severity: lintSeverityWarning,
+			Path:     field.Path,
+			Message:  fmt.Sprintf("the description doesn't clarify the effect of %s", strings.Join(missing, " and ")),
+		})
+	}
+	return findings
+}
+
+// normalizeWords lowercases the text and joins its words by single spaces with a leading and a trailing one
+func normalizeWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool { return !unicode.IsLetter(c) && !unicode.IsDigit(c) })
+	return " " + strings.Join(words, " ") + " "
+}
+
+// containsAnyWord checks whether the normalized text contains any of the keywords as whole words
+func containsAnyWord(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if kw := normalizeWords(keyword); kw != "  " && strings.Contains(text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintIntegerTypes flags the number parameters intended as integers, the intent is told by the whole number default
+// value, the whole number enum values or the name hinting a count
+func lintIntegerTypes(lctx *lintContext) []lintFinding {
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		s := field.Schema
+		if s.Type != openapi3.TypeNumber {
+			continue
+		}
+		var reason string
+		switch {
+		case s.Default != nil && isWholeNumber(s.Default):
+			reason = fmt.Sprintf("the default value %v is a whole number", s.Default)
+		case len(s.Enum) > 0 && allWholeNumbers(s.Enum):
+			reason = "the enum values are whole numbers"
+		case hasIntegerNameHint(field.Path):
+			reason = "the name suggests a whole number"
+		default:
+			continue
+		}
+		findings = append(findings, lintFinding{
+			Rule:     "integer-type",
+			Severity: lintSeverityWarning,
+			Path:     field.Path,
+			Message:  fmt.Sprintf("the parameter is typed as number but %s, declare it as int", reason),
+		})
+	}
+	return findings
+}
+
+func isWholeNumber(v interface{}) bool {
+	f, ok := v.(float64)
+	return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+func allWholeNumbers(values []interface{}) bool {
+	for _, v := range values {
+		if !isWholeNumber(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasIntegerNameHint(path string) bool {
+	words := envWords(path[strings.LastIndex(path, ".")+1:], true)
+	if len(words) == 0 {
+		return false
+	}
+	last := words[len(words)-1]
+	for _, hint := range integerNameHints {
+		if strings.EqualFold(last, hint) {
+			return true
+		}
+	}
+	return false
+}
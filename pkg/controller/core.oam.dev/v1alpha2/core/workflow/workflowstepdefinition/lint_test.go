@@ -0,0 +1,478 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/types"
+	oamctrl "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const simpleTemplate = `
+parameter: {
+	name: string
+}
+`
+
+func TestParseReservedNamesOptions(t *testing.T) {
+	opts := parseOptions(oamctrl.Args{})
+	require.Equal(t, 0, opts.reservedNames.Len())
+	opts = parseOptions(oamctrl.Args{StepDefinitionCheckReservedNames: true})
+	require.True(t, opts.reservedNames.HasAll(DefaultReservedStepNames...))
+	opts = parseOptions(oamctrl.Args{StepDefinitionCheckReservedNames: true, StepDefinitionReservedNames: []string{"deploy"}})
+	require.Equal(t, []string{"deploy"}, opts.reservedNames.List())
+}
+
+var _ = Describe("Test WorkflowStepDefinition lint rules", func() {
+	Context("Lint reserved name", func() {
+		It("warn on the built-in name by default", func() {
+			def := newTestDefinition("suspend", simpleTemplate)
+			r := newTestReconciler(options{reservedNames: sets.NewString(DefaultReservedStepNames...)}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.Warnings).Should(HaveLen(1))
+			Expect(got.Status.Warnings[0]).Should(ContainSubstring("reserved-name"))
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+
+		It("reject the built-in name", func() {
+			def := newTestDefinition("step-group", simpleTemplate)
+			r := newTestReconciler(options{reservedNames: sets.NewString(DefaultReservedStepNames...), rejectReservedNames: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+			Expect(got.Status.LatestRevision).Should(BeNil())
+			cond := got.GetCondition(condition.TypeSynced)
+			Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+			Expect(cond.Message).Should(ContainSubstring("shadows the built-in workflow step type"))
+		})
+
+		It("ignore the other names", func() {
+			def := newTestDefinition("my-step", simpleTemplate)
+			r := newTestReconciler(options{reservedNames: sets.NewString(DefaultReservedStepNames...), rejectReservedNames: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.Warnings).Should(BeEmpty())
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+	})
+
+	Context("Lint strict CUE", func() {
+		template := `
+import (
+	"vela/op"
+)
+
+apply: op.#Apply & {
+	value: {
+		replicas: parameter.replicas + 1
+	}
+}
+parameter: {
+	replicas: int
+}
+`
+		It("lenient mode accepts the incomplete value", func() {
+			def := newTestDefinition("incomplete", template)
+			r := newTestReconciler(options{}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+
+		It("strict mode flags the incomplete value", func() {
+			def := newTestDefinition("incomplete", template)
+			r := newTestReconciler(options{strictCUE: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+			cond := got.GetCondition(condition.TypeSynced)
+			Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+			Expect(cond.Message).Should(ContainSubstring("strict-cue"))
+		})
+
+		It("strict mode accepts the complete template", func() {
+			def := newTestDefinition("complete", `
+import (
+	"vela/op"
+)
+
+apply: op.#Apply & {
+	value:   parameter.value
+	cluster: parameter.cluster
+}
+parameter: {
+	value: {...}
+	cluster: *"" | string
+}
+`)
+			r := newTestReconciler(options{strictCUE: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+	})
+
+	Context("Lint required annotations", func() {
+		required := []string{"definition.oam.dev/license", "definition.oam.dev/owner"}
+
+		It("block the definition missing a required annotation", func() {
+			def := newTestDefinition("unlicensed", simpleTemplate)
+			def.SetAnnotations(map[string]string{"definition.oam.dev/owner": "team-a"})
+			r := newTestReconciler(options{requiredAnnotations: required}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+			Expect(got.Status.LatestRevision).Should(BeNil())
+			cond := got.GetCondition(condition.TypeSynced)
+			Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+			Expect(cond.Message).Should(ContainSubstring("missing the required annotations definition.oam.dev/license"))
+			Expect(cond.Message).ShouldNot(ContainSubstring("definition.oam.dev/owner"))
+		})
+
+		It("accept the definition carrying all the required annotations", func() {
+			def := newTestDefinition("licensed", simpleTemplate)
+			def.SetAnnotations(map[string]string{"definition.oam.dev/license": "Apache-2.0", "definition.oam.dev/owner": "team-a"})
+			r := newTestReconciler(options{requiredAnnotations: required}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+	})
+
+	It("Lint parameter bounds", func() {
+		def := newTestDefinition("bounds", `
+parameter: {
+	name:     string
+	protocol: *"TCP" | "UDP"
+	replicas: int & >=1 & <=10
+	port:     int & >=1
+	labels: [string]: string
+}
+`)
+		r := newTestReconciler(options{checkParameterBounds: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[parameter-bounds] name: the string parameter is unbounded, missing maxLength",
+			"[parameter-bounds] port: the integer parameter is unbounded, missing maximum",
+		}))
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+	})
+
+	It("Lint description length", func() {
+		def := newTestDefinition("descriptions", `
+parameter: {
+	// +usage=The name
+	name: string
+	config: {
+		// +usage=The very long description of the nested parameter
+		value: string
+	}
+}
+`)
+		r := newTestReconciler(options{maxDescriptionLength: 20}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[description-length] config.value: the description has 49 characters, exceeding the max length 20",
+		}))
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+	})
+
+	It("Lint enum defaults", func() {
+		def := newTestDefinition("enums", `
+parameter: {
+	protocol: "TCP" | "UDP"
+	mode:     *"fast" | "slow"
+	name:     string
+}
+`)
+		r := newTestReconciler(options{checkEnumDefaults: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{"[enum-default] protocol: the enum parameter has no default value"}))
+
+		// the default value outside the enum
+		warnings, errs := r.lint(&lintContext{def: def, schema: &openapi3.Schema{Properties: openapi3.Schemas{
+			"mode": openapi3.NewStringSchema().WithEnum("fast", "slow").WithDefault("medium").NewRef(),
+		}}})
+		Expect(errs).Should(BeEmpty())
+		Expect(warnings).Should(HaveLen(1))
+		Expect(warnings[0].String()).Should(Equal("[enum-default] mode: the default value medium is not one of the enum values"))
+	})
+
+	It("Lint forbidden descriptions", func() {
+		_, err := compileForbiddenPatterns([]string{"("})
+		Expect(err).Should(HaveOccurred())
+
+		patterns, err := compileForbiddenPatterns([]string{`\.corp\.internal\b`, `(?i)password=\S+`})
+		Expect(err).ShouldNot(HaveOccurred())
+		def := newTestDefinition("notify", `
+parameter: {
+	// +usage=The webhook, e.g. https://hooks.corp.internal/notify
+	url: string
+	// +usage=The message to send
+	message: string
+}
+`)
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionDescription: "Notify with PASSWORD=hunter2"})
+		r := newTestReconciler(options{forbiddenPatterns: patterns}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[forbidden-description] the description matches the forbidden pattern (?i)password=\\S+",
+			"[forbidden-description] url: the description matches the forbidden pattern \\.corp\\.internal\\b",
+		}))
+	})
+
+	It("Lint enum size", func() {
+		def := newTestDefinition("regions", `
+parameter: {
+	region:   *"us-east-1" | "us-west-1" | "eu-west-1" | "ap-south-1"
+	protocol: *"TCP" | "UDP"
+}
+`)
+		r := newTestReconciler(options{maxEnumValues: 3}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[enum-size] region: the enum has 4 values, exceeding the max count 3, consider a free-form parameter validated by a dynamic source instead",
+		}))
+	})
+
+	Context("Lint description", func() {
+		It("warn on the definition without description", func() {
+			def := newTestDefinition("undocumented", simpleTemplate)
+			r := newTestReconciler(options{requireDescription: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.Warnings).Should(Equal([]string{"[description] the definition has no description, set it by the annotation definition.oam.dev/description"}))
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+
+		It("reject the definition without description", func() {
+			def := newTestDefinition("undocumented", simpleTemplate)
+			r := newTestReconciler(options{requireDescription: true, rejectNoDescription: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+			cond := got.GetCondition(condition.TypeSynced)
+			Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+			Expect(cond.Message).Should(ContainSubstring("the definition has no description"))
+		})
+
+		It("accept the definition with description", func() {
+			def := newTestDefinition("documented", simpleTemplate)
+			def.SetAnnotations(map[string]string{types.AnnoDefinitionDescription: "Apply raw kubernetes objects"})
+			r := newTestReconciler(options{requireDescription: true, rejectNoDescription: true}, def)
+			got := reconcileTestDefinition(r, def)
+			Expect(got.Status.Warnings).Should(BeEmpty())
+			Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		})
+	})
+
+	It("Lint name collisions", func() {
+		def := newTestDefinition("collisions", `
+parameter: {
+	myParam: string
+	myparam: string
+	config: {
+		Value: string
+		value: string
+	}
+	name: string
+}
+`)
+		r := newTestReconciler(options{checkNameCollisions: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[name-collision] config.Value: the parameters config.Value, config.value collide case-insensitively",
+			"[name-collision] myParam: the parameters myParam, myparam collide case-insensitively",
+		}))
+	})
+
+	It("Lint group budget", func() {
+		def := newTestDefinition("groups", `
+parameter: {
+	host:     string @group("network")
+	port:     *80 | int @group("network")
+	protocol: *"TCP" | "UDP" @group("network")
+	image:    string @group("container")
+	name:     string
+}
+`)
+		r := newTestReconciler(options{maxGroupParameters: 2}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[group-budget] the group network has 3 parameters host, port, protocol, exceeding the budget 2",
+		}))
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+	})
+
+	It("Lint array items", func() {
+		def := newTestDefinition("arrays", `
+parameter: {
+	args: [...]
+	anything: [..._]
+	ports: [...int]
+	volumes: [...{
+		name: string
+		paths: [...]
+	}]
+}
+`)
+		r := newTestReconciler(options{checkArrayItems: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[array-items] anything: the array parameter has no concrete item schema",
+			"[array-items] args: the array parameter has no concrete item schema",
+			"[array-items] volumes[].paths: the array parameter has no concrete item schema",
+		}))
+	})
+
+	It("Lint boolean descriptions", func() {
+		def := newTestDefinition("booleans", `
+parameter: {
+	// +usage=Whether to wait for the resources
+	wait: *false | bool
+	// +usage=If true, the step fails on the first error, otherwise the errors are collected
+	failFast: *true | bool
+	// +usage=Enables the dry run
+	dryRun?: bool
+	debug?: bool
+}
+`)
+		r := newTestReconciler(options{booleanTrueKeywords: DefaultBooleanTrueKeywords, booleanFalseKeywords: DefaultBooleanFalseKeywords}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[boolean-description] dryRun: the description doesn't clarify the effect of false",
+			"[boolean-description] wait: the description doesn't clarify the effect of true and false",
+		}))
+
+		// the keywords are configurable
+		def = newTestDefinition("booleans-custom", def.Spec.Schematic.CUE.Template)
+		r = newTestReconciler(options{booleanTrueKeywords: []string{"whether"}, booleanFalseKeywords: []string{"otherwise", "the dry run"}}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[boolean-description] dryRun: the description doesn't clarify the effect of true",
+			"[boolean-description] failFast: the description doesn't clarify the effect of true",
+			"[boolean-description] wait: the description doesn't clarify the effect of false",
+		}))
+	})
+
+	It("Lint integer types", func() {
+		def := newTestDefinition("numbers", `
+parameter: {
+	replicas: *3 | number
+	ratio: *0.5 | number
+	mode?: 1.0 | 2.0 | 3.0
+	weight?: number
+	timeoutSeconds?: float
+	port?: int
+	http_port?: number
+	report?: number
+	export?: number
+	support?: number
+	reindex?: number
+	viewportRatio?: number
+}
+`)
+		r := newTestReconciler(options{checkIntegerTypes: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[integer-type] http_port: the parameter is typed as number but the name suggests a whole number, declare it as int",
+			"[integer-type] mode: the parameter is typed as number but the enum values are whole numbers, declare it as int",
+			"[integer-type] replicas: the parameter is typed as number but the default value 3 is a whole number, declare it as int",
+			"[integer-type] timeoutSeconds: the parameter is typed as number but the name suggests a whole number, declare it as int",
+		}))
+	})
+
+	It("Lint durations", func() {
+		def := newTestDefinition("durations", `
+parameter: {
+	timeout: *"5 minutes" | string
+	retryInterval: *"30s" | string
+	window: *"an hour" | string @duration()
+	ttl?: string
+	mode: *"fast" | string
+	timeoutSeconds: *30 | int
+}
+`)
+		def.SetAnnotations(map[string]string{oam.AnnotationExamples: `[{"ttl": "1h"}, {"ttl": "1 day", "mode": "slow"}]`})
+		r := newTestReconciler(options{checkDurations: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			`[duration] timeout: the default value "5 minutes" is not a valid duration, e.g. 30s or 5m`,
+			`[duration] ttl: the value "1 day" of the example 2 is not a valid duration, e.g. 30s or 5m`,
+			`[duration] window: the default value "an hour" is not a valid duration, e.g. 30s or 5m`,
+		}))
+
+		r = newTestReconciler(options{})
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.Warnings).Should(BeEmpty())
+	})
+
+	It("Lint secret defaults", func() {
+		def := newTestDefinition("secrets", `
+parameter: {
+	token: *"s3cr3t" | string @secret()
+	password?: string @secret()
+	user: *"admin" | string
+}
+`)
+		r := newTestReconciler(options{checkSecretDefaults: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+		cond := got.GetCondition(condition.TypeSynced)
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Message).Should(ContainSubstring("[secret-default] token: the sensitive parameter must not have a default value"))
+		Expect(cond.Message).ShouldNot(ContainSubstring("password"))
+		Expect(cond.Message).ShouldNot(ContainSubstring("s3cr3t"))
+
+		r = newTestReconciler(options{})
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+	})
+
+	It("Lint healthy condition", func() {
+		def := newTestDefinition("lint-healthy", `
+parameter: {
+	name:  string
+	debug: *false | bool
+}
+apply: value: name: parameter.name
+`)
+		r := newTestReconciler(options{checkUnusedParams: true}, def)
+		got := reconcileTestDefinition(r, def)
+		cond := got.GetCondition(TypeLintHealthy)
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Message).Should(Equal("0 Error, 1 Warning findings"))
+		Expect(got.Status.Warnings).Should(HaveLen(1))
+
+		// the error-severity finding flips the aggregate condition
+		r.rejectUnusedParams = true
+		got = reconcileTestDefinition(r, got)
+		cond = got.GetCondition(TypeLintHealthy)
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Reason).Should(Equal(condition.ConditionReason("LintFailed")))
+		Expect(cond.Message).Should(Equal("1 Error, 0 Warning findings"))
+		Expect(got.GetCondition(condition.TypeSynced).Status).Should(Equal(corev1.ConditionFalse))
+
+		r.checkUnusedParams, r.rejectUnusedParams = false, false
+		got = reconcileTestDefinition(r, got)
+		cond = got.GetCondition(TypeLintHealthy)
+		Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(cond.Message).Should(Equal("0 Error, 0 Warning findings"))
+	})
+})
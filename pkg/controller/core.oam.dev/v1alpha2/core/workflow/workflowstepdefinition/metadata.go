@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// propagatedKey checks whether the label or annotation key is in the allow-list, the entries ending with `/` match the
+// keys by prefix, e.g. workflow.oam.dev/
+func propagatedKey(allowList []string, key string) bool {
+	for _, allowed := range allowList {
+		if key == allowed || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(key, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncPropagated returns the target with the allow-listed entries of the source set and the allow-listed ones absent
+// from the source deleted, the others of the target are left untouched. changed is false if the target is kept as is
+func syncPropagated(allowList []string, source, target map[string]string) (synced map[string]string, changed bool) {
+	synced = make(map[string]string, len(target))
+	for k, v := range target {
+		if _, ok := source[k]; !ok && propagatedKey(allowList, k) {
+			changed = true
+			continue
+		}
+		synced[k] = v
+	}
+	for k, v := range source {
+		if !propagatedKey(allowList, k) {
+			continue
+		}
+		if current, ok := synced[k]; !ok || current != v {
+			changed = true
+		}
+		synced[k] = v
+	}
+	return synced, changed
+}
+
+// propagateMetadata copies the allow-listed labels and annotations of the WorkflowStepDefinition onto its schema
+// ConfigMap, e.g. the team labels selected by the GitOps tools. The ConfigMap is patched only if any of them changes
+func (r *Reconciler) propagateMetadata(ctx context.Context, def *v1beta1.WorkflowStepDefinition, cmName string) error {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.schemaNamespaceOf(def), Name: cmName}, cm); err != nil {
+		return err
+	}
+	patch := client.MergeFrom(cm.DeepCopy())
+	labels, labelsChanged := syncPropagated(r.propagatedMetadata, def.GetLabels(), cm.GetLabels())
+	annotations, annotationsChanged := syncPropagated(r.propagatedMetadata, def.GetAnnotations(), cm.GetAnnotations())
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+	cm.SetLabels(labels)
+	cm.SetAnnotations(annotations)
+	return r.Patch(ctx, cm, patch)
+}
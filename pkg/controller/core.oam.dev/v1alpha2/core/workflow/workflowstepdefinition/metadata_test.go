@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Test WorkflowStepDefinition metadata propagation", func() {
+	It("Propagate metadata", func() {
+		ctx := context.Background()
+		def := newTestDefinition("propagated", simpleTemplate)
+		def.SetLabels(map[string]string{"team": "a", "tier": "backend"})
+		def.SetAnnotations(map[string]string{"workflow.oam.dev/owner": "alice", "workflow.oam.dev/docs": "https://example.com", "note": "internal"})
+		r := newTestReconciler(options{propagatedMetadata: []string{"team", "workflow.oam.dev/"}}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+
+		getConfigMap := func() *corev1.ConfigMap {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+			return cm
+		}
+		cm := getConfigMap()
+		Expect(cm.Labels["team"]).Should(Equal("a"))
+		Expect(cm.Annotations["workflow.oam.dev/owner"]).Should(Equal("alice"))
+		Expect(cm.Annotations["workflow.oam.dev/docs"]).Should(Equal("https://example.com"))
+		Expect(cm.Annotations).ShouldNot(HaveKey("note"))
+
+		// the entries not in the allow-list are left untouched
+		cm.Annotations["gitops.example.com/sync"] = "enabled"
+		Expect(r.Update(ctx, cm)).Should(Succeed())
+
+		// the edits of the allow-listed annotations are reflected on the next reconcile
+		got.SetAnnotations(map[string]string{"workflow.oam.dev/owner": "bob", "note": "internal"})
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		cm = getConfigMap()
+		Expect(cm.Annotations["workflow.oam.dev/owner"]).Should(Equal("bob"))
+		Expect(cm.Annotations).ShouldNot(HaveKey("workflow.oam.dev/docs"))
+		Expect(cm.Annotations["gitops.example.com/sync"]).Should(Equal("enabled"))
+		Expect(cm.Annotations).ShouldNot(HaveKey("note"))
+
+		got.Labels["team"] = "b"
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		cm = getConfigMap()
+		Expect(cm.Labels["team"]).Should(Equal("b"))
+		Expect(cm.Annotations["workflow.oam.dev/owner"]).Should(Equal("bob"))
+	})
+})
+
+func TestSyncPropagated(t *testing.T) {
+	allowList := []string{"team", "workflow.oam.dev/"}
+	synced, changed := syncPropagated(allowList, map[string]string{"team": "a", "workflow.oam.dev/owner": "alice", "other": "x"}, nil)
+	require.True(t, changed)
+	require.Equal(t, map[string]string{"team": "a", "workflow.oam.dev/owner": "alice"}, synced)
+
+	synced, changed = syncPropagated(allowList, map[string]string{"team": "a"}, synced)
+	require.True(t, changed)
+	require.Equal(t, map[string]string{"team": "a"}, synced)
+
+	_, changed = syncPropagated(allowList, map[string]string{"team": "a", "other": "y"}, map[string]string{"team": "a", "kept": "z"})
+	require.False(t, changed)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	velametrics "github.com/kubevela/pkg/monitor/metrics"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// the results of the reconciliation reported by the metrics
+const (
+	reconcileResultSuccess = "success"
+	reconcileResultError   = "error"
+	// reconcileResultSkipped is the reconciliation of the definition not matching the controller requirement, paused
+	// or frozen by the deprecation
+	reconcileResultSkipped = "skipped"
+	// reconcileResultRequeue is the reconciliation requeued for the kind unknown to the DiscoveryMapper
+	reconcileResultRequeue = "requeue"
+)
+
+var (
+	// reconcileTotalCounter reports the count of the reconciliations of the WorkflowStepDefinitions by the result
+	reconcileTotalCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "workflowstepdefinition_reconcile_total",
+		Help: "workflowStepDefinition reconciliation count by the result.",
+	}, []string{"result"})
+
+	// reconcileDurationHistogram reports the duration of the reconciliations of the WorkflowStepDefinitions
+	reconcileDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        "workflowstepdefinition_reconcile_time_seconds",
+		Help:        "workflowStepDefinition reconciliation duration distributions.",
+		Buckets:     velametrics.FineGrainedBuckets,
+		ConstLabels: prometheus.Labels{},
+	}, []string{"result"})
+
+	// definitionRevisionsGauge reports the count of the DefinitionRevisions of each WorkflowStepDefinition
+	definitionRevisionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workflowstepdefinition_revisions",
+		Help: "workflowStepDefinition DefinitionRevision count.",
+	}, []string{"namespace", "name"})
+)
+
+var registerMetricsOnce sync.Once
+
+// registerMetrics registers the metrics of the controller to the controller-runtime registry served by the /metrics endpoint
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		for _, collector := range []prometheus.Collector{reconcileTotalCounter, reconcileDurationHistogram, definitionRevisionsGauge} {
+			if err := metrics.Registry.Register(collector); err != nil {
+				klog.Error(err)
+			}
+		}
+	})
+}
+
+type reconcileOutcomeKey struct{}
+
+// reconcileOutcome is the result of the reconciliation, the error result is set where the error is recorded in the
+// condition rather than returned
+type reconcileOutcome struct {
+	result string
+}
+
+// withReconcileOutcome returns the context carrying the outcome of the reconciliation
+func withReconcileOutcome(ctx context.Context) (context.Context, *reconcileOutcome) {
+	outcome := &reconcileOutcome{result: reconcileResultSuccess}
+	return context.WithValue(ctx, reconcileOutcomeKey{}, outcome), outcome
+}
+
+// setReconcileResult sets the result of the reconciliation carried by the context, it's a no-op if none is carried
+func setReconcileResult(ctx context.Context, result string) {
+	if outcome, ok := ctx.Value(reconcileOutcomeKey{}).(*reconcileOutcome); ok {
+		outcome.result = result
+	}
+}
+
+// reportRevisions reports the count of the DefinitionRevisions of the definition
+func (r *Reconciler) reportRevisions(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := r.List(ctx, revList, client.InNamespace(def.Namespace),
+		client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: def.Name}); err != nil {
+		return err
+	}
+	definitionRevisionsGauge.WithLabelValues(def.Namespace, def.Name).Set(float64(len(revList.Items)))
+	return nil
+}
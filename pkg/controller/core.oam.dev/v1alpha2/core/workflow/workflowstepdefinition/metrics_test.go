@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition metrics", func() {
+	It("Reconcile metrics", func() {
+		ctx := context.Background()
+		count := func(result string) float64 {
+			return testutil.ToFloat64(reconcileTotalCounter.WithLabelValues(result))
+		}
+		success, failure, skipped := count(reconcileResultSuccess), count(reconcileResultError), count(reconcileResultSkipped)
+
+		def := newTestDefinition("metered", simpleTemplate)
+		r := newTestReconciler(options{controllerVersion: "v1.9.0"}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(count(reconcileResultSuccess)).Should(Equal(success + 1))
+		Expect(testutil.ToFloat64(definitionRevisionsGauge.WithLabelValues(got.Namespace, got.Name))).Should(Equal(float64(1)))
+
+		got.Spec.Schematic.CUE.Template = simpleTemplate + `
+output: parameter.name
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(testutil.ToFloat64(definitionRevisionsGauge.WithLabelValues(got.Namespace, got.Name))).Should(Equal(float64(2)))
+
+		// the definition not matching the controller requirement is skipped
+		got.SetAnnotations(map[string]string{oam.AnnotationControllerRequirement: ">=2.0.0"})
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(count(reconcileResultSkipped)).Should(Equal(skipped + 1))
+
+		// the error recorded in the condition is counted though it's not returned
+		got.SetAnnotations(nil)
+		got.Spec.Schematic.CUE.Template = `parameter: { token: *"s3cr3t" | string @secret() }`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		r.checkSecretDefaults = true
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(got)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count(reconcileResultError)).Should(Equal(failure + 1))
+		Expect(count(reconcileResultSuccess)).Should(Equal(success + 2))
+	})
+})
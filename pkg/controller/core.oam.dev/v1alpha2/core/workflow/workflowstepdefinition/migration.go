@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// migrationNoteKey is the data key of the migration note for the breaking changes in the schema ConfigMap
+const migrationNoteKey = "migration.md"
+
+// generateMigrationNote generates the human-readable note guiding the users to migrate their workflow steps to
+// the revision with breaking changes
+func generateMigrationNote(actx *artifactContext) (string, error) {
+	report := actx.compatibility
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Migrating to %s revision %d\n\n", actx.def.Name, report.Revision))
+	sb.WriteString(fmt.Sprintf("Revision %d of the workflow step `%s` contains breaking changes of the parameters since revision %d, "+
+		"update the properties of the steps using it as follows.\n", report.Revision, actx.def.Name, report.PreviousRevision))
+	sections := []struct {
+		kind  string
+		title string
+		line  func(change breakingChange) string
+	}{{
+		kind:  breakingChangeRemoved,
+		title: "Removed parameters",
+		line: func(change breakingChange) string {
+			return fmt.Sprintf("- `%s`: remove it from the properties.", change.Path)
+		},
+	}, {
+		kind:  breakingChangeRenamed,
+		title: "Renamed parameters",
+		line: func(change breakingChange) string {
+			return fmt.Sprintf("- `%s`: it seems to be renamed to `%s`, move the value there.", change.Path, change.NewPath)
+		},
+	}, {
+		kind:  breakingChangeTypeChanged,
+		title: "Changed parameter types",
+		line: func(change breakingChange) string {
+			return fmt.Sprintf("- `%s`: change the value from %s to %s.", change.Path, change.OldType, change.NewType)
+		},
+	}, {
+		kind:  breakingChangeRequired,
+		title: "Newly required parameters",
+		line: func(change breakingChange) string {
+			return fmt.Sprintf("- `%s`: set it in the properties.", change.Path)
+		},
+	}}
+	for _, section := range sections {
+		var lines []string
+		for _, change := range report.Changes {
+			if change.Kind == section.kind {
+				lines = append(lines, section.line(change))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n%s\n", section.title, strings.Join(lines, "\n")))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	previous := renderTestSchema(t, `
+parameter: {
+	url: string
+	timeout: *"10s" | string
+	retries?: int
+	auth?: {
+		user:     string
+		password: string
+	}
+}
+`)
+	current := renderTestSchema(t, `
+parameter: {
+	endpoint: string
+	timeout: *10 | int
+	retries: int
+	verbose?: bool
+}
+`)
+	require.Equal(t, []breakingChange{
+		{Kind: breakingChangeRemoved, Path: "auth", OldType: "object"},
+		{Kind: breakingChangeRequired, Path: "retries"},
+		{Kind: breakingChangeTypeChanged, Path: "timeout", OldType: "string", NewType: "integer"},
+		{Kind: breakingChangeRenamed, Path: "url", NewPath: "endpoint", OldType: "string"},
+	}, checkCompatibility(previous, current))
+	require.Empty(t, checkCompatibility(current, current))
+}
+
+var _ = Describe("Test WorkflowStepDefinition migration notes", func() {
+	It("Migration note", func() {
+		ctx := context.Background()
+		def := newTestDefinition("webhook", `
+parameter: {
+	url: string
+	auth?: {
+		token: string
+	}
+}
+`)
+		r := newTestReconciler(options{migrationNote: true}, def)
+		got := reconcileTestDefinition(r, def)
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data).ShouldNot(HaveKey(migrationNoteKey))
+
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	endpoint: string
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data[migrationNoteKey]).Should(Equal("# Migrating to webhook revision 2\n\n" +
+			"Revision 2 of the workflow step `webhook` contains breaking changes of the parameters since revision 1, " +
+			"update the properties of the steps using it as follows.\n" +
+			"\n## Removed parameters\n\n- `auth`: remove it from the properties.\n" +
+			"\n## Renamed parameters\n\n- `url`: it seems to be renamed to `endpoint`, move the value there.\n"))
+	})
+})
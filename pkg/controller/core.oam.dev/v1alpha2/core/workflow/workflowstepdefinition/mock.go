@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// mockOutputKey is the data key of the mock output of the step in the schema ConfigMap
+const mockOutputKey = "mock-output.json"
+
+// outputSchema parses the output schema declared by the annotation of the definition, it's nil if not declared
+func outputSchema(def *v1beta1.WorkflowStepDefinition) (*openapi3.Schema, error) {
+	value, ok := def.GetAnnotations()[oam.AnnotationOutputSchema]
+	if !ok {
+		return nil, nil
+	}
+	data, err := yaml.YAMLToJSON([]byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid output schema in the annotation %s: %w", oam.AnnotationOutputSchema, err)
+	}
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid output schema in the annotation %s: %w", oam.AnnotationOutputSchema, err)
+	}
+	return schema, nil
+}
+
+// generateMockOutput generates a canned successful output of the step conforming to its output schema for the
+// testing frameworks, the values are taken from the examples and the defaults of the schema
+func generateMockOutput(actx *artifactContext) (string, error) {
+	schema, err := outputSchema(actx.def)
+	if err != nil {
+		return "", err
+	}
+	mock := mockValue(schema)
+	if err := schema.VisitJSON(mock); err != nil {
+		return "", fmt.Errorf("the mock output doesn't conform to the output schema: %w", err)
+	}
+	data, err := json.MarshalIndent(mock, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// mockValue returns the value of the schema preferring its example and default, unlike the sample value all the
+// properties of the objects are filled to mock a complete output
+func mockValue(s *openapi3.Schema) interface{} {
+	if s.Example != nil {
+		return s.Example
+	}
+	if s.Default != nil || len(s.Enum) > 0 {
+		return sampleValue(s)
+	}
+	switch s.Type {
+	case openapi3.TypeArray:
+		items := []interface{}{}
+		if s.Items != nil && s.Items.Value != nil {
+			count := s.MinItems
+			if count == 0 && (s.MaxItems == nil || *s.MaxItems > 0) {
+				count = 1
+			}
+			for i := uint64(0); i < count; i++ {
+				items = append(items, mockValue(s.Items.Value))
+			}
+		}
+		return items
+	case openapi3.TypeObject, "":
+		obj := map[string]interface{}{}
+		for name, ref := range s.Properties {
+			if ref != nil && ref.Value != nil {
+				obj[name] = mockValue(ref.Value)
+			}
+		}
+		return obj
+	}
+	return sampleValue(s)
+}
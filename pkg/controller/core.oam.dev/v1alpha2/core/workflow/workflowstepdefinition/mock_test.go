@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const testOutputSchema = `
+type: object
+required: [jobName, status, succeeded, pods]
+properties:
+  jobName:
+    type: string
+    example: apply-job-x7k2p
+  status:
+    type: string
+    enum: [Complete, Failed]
+  succeeded:
+    type: integer
+    minimum: 1
+  duration:
+    type: string
+    default: 30s
+  pods:
+    type: array
+    minItems: 2
+    items:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          minLength: 3
+        ready:
+          type: boolean
+`
+
+var _ = Describe("Test WorkflowStepDefinition mock outputs", func() {
+	It("Mock output", func() {
+		def := newTestDefinition("apply-job", simpleTemplate)
+		def.SetAnnotations(map[string]string{oam.AnnotationOutputSchema: testOutputSchema})
+		r := newTestReconciler(options{mockOutput: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		var mock map[string]interface{}
+		Expect(json.Unmarshal([]byte(cm.Data[mockOutputKey]), &mock)).Should(Succeed())
+		Expect(mock["jobName"]).Should(Equal("apply-job-x7k2p"))
+		Expect(mock["status"]).Should(Equal("Complete"))
+		Expect(mock["duration"]).Should(Equal("30s"))
+		Expect(mock["pods"]).Should(HaveLen(2))
+
+		data, err := yaml.YAMLToJSON([]byte(testOutputSchema))
+		Expect(err).ShouldNot(HaveOccurred())
+		schema := &openapi3.Schema{}
+		Expect(schema.UnmarshalJSON(data)).Should(Succeed())
+		Expect(schema.VisitJSON(mock)).Should(Succeed())
+
+		// the definition without the output schema has no mock output
+		def = newTestDefinition("apply-plain", simpleTemplate)
+		r = newTestReconciler(options{mockOutput: true}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data).ShouldNot(HaveKey(mockOutputKey))
+	})
+})
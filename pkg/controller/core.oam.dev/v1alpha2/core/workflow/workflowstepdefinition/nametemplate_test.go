@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+func TestParseConfigMapNameTemplate(t *testing.T) {
+	tmpl, err := parseConfigMapNameTemplate("")
+	require.NoError(t, err)
+	require.Nil(t, tmpl)
+	tmpl, err = parseConfigMapNameTemplate("team-{{.Namespace}}-{{.Name}}{{with .Revision}}-{{.}}{{end}}")
+	require.NoError(t, err)
+	require.NotNil(t, tmpl)
+
+	for _, text := range []string{
+		"team-{{.Name",
+		"team-{{.Owner}}",
+		"Team_{{.Name}}{{.Revision}}",
+		"team-{{.Name}}",
+	} {
+		_, err = parseConfigMapNameTemplate(text)
+		require.Error(t, err, text)
+	}
+}
+
+var _ = Describe("Test WorkflowStepDefinition ConfigMap name template", func() {
+	It("Config map name template", func() {
+		ctx := context.Background()
+		tmpl, err := parseConfigMapNameTemplate("team-{{.Namespace}}-{{.Name}}{{with .Revision}}-{{.}}{{end}}")
+		Expect(err).ShouldNot(HaveOccurred())
+		def := newTestDefinition("notify", simpleTemplate)
+		r := newTestReconciler(options{cmNameTemplate: tmpl}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(Equal("team-default-notify"))
+		for _, name := range []string{"team-default-notify", "team-default-notify-notify-v1"} {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, cm)).Should(Succeed(), name)
+		}
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "workflowstep-schema-notify"}, cm)).Should(HaveOccurred())
+
+		// the schema server looks up the revision ConfigMap by the template as well
+		h := &schemaHandler{client: r.Client, cmNameTemplate: tmpl}
+		data, err := h.schema(ctx, "default", "notify", "v1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(data).ShouldNot(BeEmpty())
+
+		// the overlong rendered name fails the definition instead of the ConfigMap creation
+		tmpl, err = parseConfigMapNameTemplate("{{.Name}}-{{.Name}}-{{.Name}}-{{.Name}}{{with .Revision}}-{{.}}{{end}}")
+		Expect(err).ShouldNot(HaveOccurred())
+		long := newTestDefinition("a-long-step-definition-name-repeated-four-times-in-the-configmap-name", simpleTemplate)
+		r = newTestReconciler(options{cmNameTemplate: tmpl}, long)
+		reconcileTestDefinition(r, long)
+		got = &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(long), got)).Should(Succeed())
+		c := got.Status.GetCondition(condition.TypeSynced)
+		Expect(c.Reason).Should(Equal(condition.ReasonReconcileError))
+		Expect(c.Message).Should(ContainSubstring("invalid ConfigMap name"))
+	})
+})
+
+func TestRenderSchemaConfigMapName(t *testing.T) {
+	// the default names without the template
+	def := utils.CapabilityStepDefinition{}
+	name, err := def.RenderSchemaConfigMapName("default", "notify", "")
+	require.NoError(t, err)
+	require.Equal(t, def.SchemaConfigMapName("notify"), name)
+	name, err = def.RenderSchemaConfigMapName("default", "notify", "notify-v1")
+	require.NoError(t, err)
+	require.Equal(t, def.SchemaConfigMapName("notify-v1"), name)
+
+	// the prefix is prepended to the rendered names
+	tmpl, err := parseConfigMapNameTemplate("{{.Name}}{{with .Revision}}-{{.}}{{end}}")
+	require.NoError(t, err)
+	def.ConfigMapNameTemplate = tmpl
+	def.ConfigMapNamePrefix = "team-"
+	name, err = def.RenderSchemaConfigMapName("default", "notify", "notify-v1")
+	require.NoError(t, err)
+	require.Equal(t, "team-notify-notify-v1", name)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+const defaultsTemplate = `
+parameter: {
+	memory:  *"1024Mi" | string
+	cpu:     *"1000m" | string
+	timeout: *"90s" | string
+	image:   *"nginx" | string
+}
+`
+
+var _ = Describe("Test WorkflowStepDefinition template normalization", func() {
+	Context("Normalize defaults", func() {
+		for name, tc := range map[string]struct {
+			normalize bool
+			expected  map[string]string
+		}{
+			"disabled": {
+				expected: map[string]string{"memory": "1024Mi", "cpu": "1000m", "timeout": "90s", "image": "nginx"},
+			},
+			"enabled": {
+				normalize: true,
+				expected:  map[string]string{"memory": "1Gi", "cpu": "1", "timeout": "1m30s", "image": "nginx"},
+			},
+		} {
+			tc := tc
+			It(name, func() {
+				def := newTestDefinition("defaults", defaultsTemplate)
+				r := newTestReconciler(options{normalizeDefaults: tc.normalize}, def)
+				got := reconcileTestDefinition(r, def)
+
+				cm := &corev1.ConfigMap{}
+				Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+				schema := struct {
+					Properties map[string]struct {
+						Default string `json:"default"`
+					} `json:"properties"`
+				}{}
+				Expect(json.Unmarshal([]byte(cm.Data[types.OpenapiV3JSONSchema]), &schema)).Should(Succeed())
+				defaults := map[string]string{}
+				for key, property := range schema.Properties {
+					defaults[key] = property.Default
+				}
+				Expect(defaults).Should(Equal(tc.expected))
+			})
+		}
+	})
+})
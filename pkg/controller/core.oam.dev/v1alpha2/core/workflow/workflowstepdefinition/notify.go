@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// schemaNotifyKey is the data key of the WorkflowStepDefinition in the schema notify ConfigMap
+func schemaNotifyKey(def *v1beta1.WorkflowStepDefinition) string {
+	return def.Namespace + "." + def.Name
+}
+
+// notifySchemaChange records the schema fingerprint of the WorkflowStepDefinition in the well-known notify ConfigMap
+// and bumps its generation annotation when the fingerprint changes. Downstream controllers consuming the schemas
+// can watch the ConfigMap to pre-populate their caches, the data key tells them which definition changed.
+func (r *Reconciler) notifySchemaChange(ctx context.Context, def *v1beta1.WorkflowStepDefinition, fingerprint string) error {
+	key := client.ObjectKey{Namespace: oam.SystemDefinitionNamespace, Name: r.schemaNotifyConfigMap}
+	dataKey := schemaNotifyKey(def)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm := &corev1.ConfigMap{}
+		err := r.Get(ctx, key, cm)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        key.Name,
+					Namespace:   key.Namespace,
+					Annotations: map[string]string{oam.AnnotationSchemaGeneration: "1"},
+				},
+				Data: map[string]string{dataKey: fingerprint},
+			}
+			if err := r.Create(ctx, cm); err != nil {
+				return err
+			}
+			reconcileLogger(ctx).Info("Notified the schema change of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "generation", 1)
+			return nil
+		}
+		if cm.Data[dataKey] == fingerprint {
+			return nil
+		}
+		generation, _ := strconv.ParseInt(cm.Annotations[oam.AnnotationSchemaGeneration], 10, 64)
+		generation++
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Data[dataKey] = fingerprint
+		cm.Annotations[oam.AnnotationSchemaGeneration] = strconv.FormatInt(generation, 10)
+		if err := r.Update(ctx, cm); err != nil {
+			return err
+		}
+		reconcileLogger(ctx).Info("Notified the schema change of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "generation", generation)
+		return nil
+	})
+}
+
+// withdrawSchemaNotification deletes the data key of the deleted WorkflowStepDefinition from the notify ConfigMap and
+// bumps its generation, so that the downstream controllers drop the definition from their caches
+func (r *Reconciler) withdrawSchemaNotification(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	key := client.ObjectKey{Namespace: oam.SystemDefinitionNamespace, Name: r.schemaNotifyConfigMap}
+	dataKey := schemaNotifyKey(def)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, key, cm); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if _, ok := cm.Data[dataKey]; !ok {
+			return nil
+		}
+		generation, _ := strconv.ParseInt(cm.Annotations[oam.AnnotationSchemaGeneration], 10, 64)
+		generation++
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		delete(cm.Data, dataKey)
+		cm.Annotations[oam.AnnotationSchemaGeneration] = strconv.FormatInt(generation, 10)
+		if err := r.Update(ctx, cm); err != nil {
+			return err
+		}
+		reconcileLogger(ctx).Info("Withdrew the schema of the deleted WorkflowStepDefinition from the notify ConfigMap", "workflowStepDefinition", klog.KObj(def), "generation", generation)
+		return nil
+	})
+}
+
+// recordSchemaChange records the schema change event on the definition, or on the configured target ConfigMap in the
+// namespace of the definition to keep the events of the definition uncluttered
+func (r *Reconciler) recordSchemaChange(ctx context.Context, def *v1beta1.WorkflowStepDefinition, oldFingerprint, newFingerprint string) error {
+	var target runtime.Object = def
+	if r.schemaEventTarget != "" {
+		cm, err := r.ensureSchemaEventTarget(ctx, def.Namespace)
+		if err != nil {
+			return err
+		}
+		target = cm
+	}
+	r.recorder(ctx).Event(target, event.Normal("WorkflowStepDefinition schema changed",
+		fmt.Sprintf("the parameter schema of WorkflowStepDefinition %s changed from %s to %s", klog.KObj(def), oldFingerprint, newFingerprint)))
+	return nil
+}
+
+// ensureSchemaEventTarget gets the target ConfigMap of the schema change events, it's created if missing
+func (r *Reconciler) ensureSchemaEventTarget(ctx context.Context, namespace string) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: namespace, Name: r.schemaEventTarget}
+	err := r.Get(ctx, key, cm)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return cm, err
+	}
+	cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Create(ctx, cm); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		return cm, r.Get(ctx, key, cm)
+	}
+	return cm, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema change notification", func() {
+	It("Notify schema change", func() {
+		ctx := context.Background()
+		def := newTestDefinition("notify", `
+parameter: {
+	name: string
+}
+`)
+		r := newTestReconciler(options{schemaNotifyConfigMap: "schema-notify"}, def)
+		notifyKey := client.ObjectKey{Namespace: oam.SystemDefinitionNamespace, Name: "schema-notify"}
+
+		def = reconcileTestDefinition(r, def)
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, notifyKey, cm)).Should(Succeed())
+		Expect(cm.Annotations[oam.AnnotationSchemaGeneration]).Should(Equal("1"))
+		fingerprint := cm.Data["default.notify"]
+		Expect(fingerprint).ShouldNot(BeEmpty())
+
+		// reconcile without schema change should not bump the notify object
+		def = reconcileTestDefinition(r, def)
+		Expect(r.Get(ctx, notifyKey, cm)).Should(Succeed())
+		Expect(cm.Annotations[oam.AnnotationSchemaGeneration]).Should(Equal("1"))
+
+		// changing the schema should bump the notify object
+		def.Spec.Schematic.CUE.Template = `
+parameter: {
+	name: string
+	replicas: int
+}
+`
+		Expect(r.Update(ctx, def)).Should(Succeed())
+		reconcileTestDefinition(r, def)
+		Expect(r.Get(ctx, notifyKey, cm)).Should(Succeed())
+		Expect(cm.Annotations[oam.AnnotationSchemaGeneration]).Should(Equal("2"))
+		Expect(cm.Data["default.notify"]).ShouldNot(Equal(fingerprint))
+
+		// deleting the definition should withdraw it from the notify object
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(def), def)).Should(Succeed())
+		Expect(r.Delete(ctx, def)).Should(Succeed())
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(r.Get(ctx, notifyKey, cm)).Should(Succeed())
+		Expect(cm.Annotations[oam.AnnotationSchemaGeneration]).Should(Equal("3"))
+		Expect(cm.Data).ShouldNot(HaveKey("default.notify"))
+	})
+
+	Context("Schema change event target", func() {
+		ctx := context.Background()
+		updateSchema := func(r *Reconciler, def *v1beta1.WorkflowStepDefinition) {
+			got := reconcileTestDefinition(r, def)
+			got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: int
+}
+`
+			Expect(r.Update(ctx, got)).Should(Succeed())
+			reconcileTestDefinition(r, got)
+		}
+		schemaChanges := func(recorder *recordingRecorder) []client.Object {
+			var objs []client.Object
+			for i, e := range recorder.events {
+				if e.Reason == "WorkflowStepDefinition schema changed" {
+					Expect(e.Message).Should(ContainSubstring("default/changing"))
+					objs = append(objs, recorder.objects[i].(client.Object))
+				}
+			}
+			return objs
+		}
+
+		It("record on the definition by default", func() {
+			def := newTestDefinition("changing", simpleTemplate)
+			recorder := &recordingRecorder{}
+			r := newTestReconciler(options{}, def)
+			r.record = recorder
+			updateSchema(r, def)
+			objs := schemaChanges(recorder)
+			Expect(objs).Should(HaveLen(1))
+			Expect(objs[0]).Should(BeAssignableToTypeOf(&v1beta1.WorkflowStepDefinition{}))
+		})
+
+		It("record on the target", func() {
+			def := newTestDefinition("changing", simpleTemplate)
+			recorder := &recordingRecorder{}
+			r := newTestReconciler(options{schemaEventTarget: "schema-changes"}, def)
+			r.record = recorder
+			updateSchema(r, def)
+			objs := schemaChanges(recorder)
+			Expect(objs).Should(HaveLen(1))
+			Expect(objs[0]).Should(BeAssignableToTypeOf(&corev1.ConfigMap{}))
+			Expect(client.ObjectKeyFromObject(objs[0])).Should(Equal(client.ObjectKey{Namespace: "default", Name: "schema-changes"}))
+			Expect(r.Get(ctx, client.ObjectKeyFromObject(objs[0]), &corev1.ConfigMap{})).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// sweepOrphanRevisions deletes the DefinitionRevisions labeled with the name of the definition which it no longer
+// references, e.g. the ones left behind by a previous incarnation of the definition or by a migration stripping the
+// ownerReferences. The newest defRevLimit+1 revisions and the latest revision are always retained, so only the orphans
+// beyond the revision limit are reclaimed.
+func (r *Reconciler) sweepOrphanRevisions(ctx context.Context, def *v1beta1.WorkflowStepDefinition) error {
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := r.List(ctx, revList, client.InNamespace(def.Namespace),
+		client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: def.Name}); err != nil {
+		return err
+	}
+	revisions := revList.Items
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Spec.Revision > revisions[j].Spec.Revision })
+	var reclaimed []string
+	for i := range revisions {
+		rev := &revisions[i]
+		if i <= r.defRevLimit || !orphanRevision(def, rev) {
+			continue
+		}
+		if err := r.Delete(ctx, rev); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		reclaimed = append(reclaimed, rev.Name)
+	}
+	if len(reclaimed) > 0 {
+		reconcileLogger(ctx).Info("Reclaimed the orphan DefinitionRevisions", "workflowStepDefinition", klog.KObj(def),
+			"namespace", def.Namespace, "definitionRevisions", reclaimed)
+	}
+	return nil
+}
+
+// orphanRevision checks whether the DefinitionRevision labeled with the name of the definition lost its owner
+func orphanRevision(owner *v1beta1.WorkflowStepDefinition, rev *v1beta1.DefinitionRevision) bool {
+	if !ownsRevision(owner, rev) {
+		return true
+	}
+	if latest := owner.Status.LatestRevision; latest != nil && latest.Name == rev.Name {
+		return false
+	}
+	for _, ref := range rev.GetOwnerReferences() {
+		if ref.Kind == v1beta1.WorkflowStepDefinitionKind && ref.Name == owner.Name && ref.UID != owner.UID {
+			// the revision belongs to a previous incarnation of the definition
+			return true
+		}
+	}
+	return false
+}
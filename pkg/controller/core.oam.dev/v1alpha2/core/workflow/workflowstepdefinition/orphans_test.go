@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// newTestRevision returns the revision of the definition snapshot, labeled with the definition name
+func newTestRevision(def *v1beta1.WorkflowStepDefinition, revision int64) *v1beta1.DefinitionRevision {
+	return &v1beta1.DefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-v%d", def.Name, revision),
+			Namespace: def.Namespace,
+			Labels:    map[string]string{oam.LabelWorkflowStepDefinitionName: def.Name},
+		},
+		Spec: v1beta1.DefinitionRevisionSpec{
+			Revision:               revision,
+			DefinitionType:         common.WorkflowStepType,
+			WorkflowStepDefinition: *def,
+		},
+	}
+}
+
+var _ = Describe("Test WorkflowStepDefinition orphaned schema ConfigMaps", func() {
+	It("Sweep orphan revisions", func() {
+		ctx := context.Background()
+		live := newTestDefinition("live", simpleTemplate)
+		live.Status.LatestRevision = &common.Revision{Name: "live-v3", Revision: 3}
+		other := newTestDefinition("other", simpleTemplate)
+		gone := newTestDefinition("gone", simpleTemplate)
+
+		var objs []client.Object
+		objs = append(objs, live, other)
+		for i := int64(1); i <= 3; i++ {
+			objs = append(objs, newTestRevision(live, i), newTestRevision(other, i), newTestRevision(gone, i))
+		}
+		// the oldest revision of live belongs to the previous incarnation of the definition
+		stale := objs[2].(*v1beta1.DefinitionRevision)
+		stale.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.WorkflowStepDefinitionKind, Name: "live", UID: "previous-uid",
+		}})
+		// the revision labeled by live snapshots a different definition
+		mislabeled := newTestRevision(gone, 4)
+		mislabeled.Name = "live-v0"
+		mislabeled.Labels[oam.LabelWorkflowStepDefinitionName] = "live"
+		mislabeled.Spec.Revision = 0
+		objs = append(objs, mislabeled)
+
+		r := newTestReconciler(options{defRevLimit: 1}, objs...)
+		Expect(r.sweepOrphanRevisions(ctx, live)).Should(Succeed())
+
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		var remaining []string
+		for _, rev := range revList.Items {
+			remaining = append(remaining, rev.Name)
+		}
+		// only the orphans of live beyond the limit are reclaimed, the revisions of the other definitions are left alone
+		// even if their definition is gone
+		Expect(remaining).Should(ConsistOf([]string{"live-v2", "live-v3", "other-v1", "other-v2", "other-v3", "gone-v1", "gone-v2", "gone-v3"}))
+	})
+
+	It("Sweep orphan revisions disabled", func() {
+		def := newTestDefinition("sweep", simpleTemplate)
+		gone := newTestDefinition("gone", simpleTemplate)
+		// the revisions labeled by sweep snapshot a different definition, they're left to the sweep since the regular
+		// cleanup doesn't run with the revisions disabled
+		objs := []client.Object{def}
+		for i := int64(1); i <= 3; i++ {
+			rev := newTestRevision(gone, i)
+			rev.Name = fmt.Sprintf("sweep-orphan-v%d", i)
+			rev.Labels[oam.LabelWorkflowStepDefinitionName] = def.Name
+			objs = append(objs, rev)
+		}
+
+		r := newTestReconciler(options{defRevLimit: 1, disableRevisions: true}, objs...)
+		reconcileTestDefinition(r, def)
+		Expect(r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "sweep-orphan-v1"}, &v1beta1.DefinitionRevision{})).Should(Succeed())
+
+		r.sweepOrphanRevs = true
+		reconcileTestDefinition(r, def)
+		err := r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "sweep-orphan-v1"}, &v1beta1.DefinitionRevision{})
+		Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+		Expect(r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "sweep-orphan-v2"}, &v1beta1.DefinitionRevision{})).Should(Succeed())
+	})
+})
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test paused WorkflowStepDefinition", func() {
+	It("Disable reconcile", func() {
+		ctx := context.Background()
+		def := newTestDefinition("paused", simpleTemplate)
+		def.SetAnnotations(map[string]string{
+			oam.AnnotationDisableReconcile: "true",
+			// the paused definition is skipped regardless of the controller requirement
+			oam.AnnotationControllerRequirement: "v0.0.0-unmatched",
+		})
+		recorder := &recordingRecorder{}
+		r := newTestReconciler(options{controllerVersion: "v1.7.0"}, def)
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+		Expect(got.Status.LatestRevision).Should(BeNil())
+		Expect(recorder.events).Should(HaveLen(1))
+		Expect(string(recorder.events[0].Reason)).Should(Equal("WorkflowStepDefinition reconciliation paused"))
+
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(BeEmpty())
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"))).Should(Succeed())
+		Expect(cmList.Items).Should(BeEmpty())
+
+		// removing the annotation resumes the reconciliation
+		got.SetAnnotations(nil)
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		Expect(got.Status.LatestRevision.Name).Should(Equal("paused-v1"))
+	})
+})
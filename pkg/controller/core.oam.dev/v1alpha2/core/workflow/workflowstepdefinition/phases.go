@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+)
+
+// the schema generation phases of the WorkflowStepDefinition
+const (
+	// phaseCompose embeds the parameters of the other WorkflowStepDefinitions
+	phaseCompose = "compose"
+	// phaseRender compiles the CUE template and renders the parameter schema
+	phaseRender = "render"
+	phaseLint   = "lint"
+	// phaseRevision reconciles the DefinitionRevision
+	phaseRevision = "revision"
+	// phasePersist stores the schema and the artifacts in the ConfigMaps
+	phasePersist = "persist"
+)
+
+// phaseTimer records the durations of the schema generation phases, a nil phaseTimer records nothing
+type phaseTimer struct {
+	durations map[string]metav1.Duration
+}
+
+func newPhaseTimer(enabled bool) *phaseTimer {
+	if !enabled {
+		return nil
+	}
+	return &phaseTimer{durations: map[string]metav1.Duration{}}
+}
+
+// start starts the timing of the phase, the returned func stops it and records the duration
+func (t *phaseTimer) start(phase string) func() {
+	if t == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		d := time.Since(begin)
+		t.durations[phase] = metav1.Duration{Duration: d}
+		metrics.WorkflowStepDefinitionPhaseDurationHistogram.WithLabelValues(phase).Observe(d.Seconds())
+	}
+}
+
+// setStatus sets the recorded durations in the status, they are left out of the comparison of the status so a
+// reconciliation without any other change doesn't write them
+func (t *phaseTimer) setStatus(status, current *v1beta1.WorkflowStepDefinitionStatus) {
+	if t == nil {
+		return
+	}
+	status.PhaseDurations = current.PhaseDurations
+	if apiequality.Semantic.DeepEqual(status, current) {
+		return
+	}
+	status.PhaseDurations = t.durations
+}
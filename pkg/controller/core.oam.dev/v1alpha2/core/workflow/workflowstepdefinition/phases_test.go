@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test WorkflowStepDefinition reconcile phases", func() {
+	It("Phase durations", func() {
+		def := newTestDefinition("timed", simpleTemplate)
+		r := newTestReconciler(options{phaseDurations: true}, def)
+		got := reconcileTestDefinition(r, def)
+		for _, phase := range []string{phaseCompose, phaseRender, phaseLint, phaseRevision, phasePersist} {
+			Expect(got.Status.PhaseDurations).Should(HaveKey(phase))
+			Expect(got.Status.PhaseDurations[phase].Duration.Nanoseconds()).Should(BeNumerically(">=", int64(0)))
+		}
+
+		// reconcile without change should not rewrite the durations
+		version := got.ResourceVersion
+		got = reconcileTestDefinition(r, got)
+		Expect(got.ResourceVersion).Should(Equal(version))
+
+		untimed := newTestDefinition("untimed", simpleTemplate)
+		r = newTestReconciler(options{}, untimed)
+		got = reconcileTestDefinition(r, untimed)
+		Expect(got.Status.PhaseDurations).Should(BeEmpty())
+	})
+})
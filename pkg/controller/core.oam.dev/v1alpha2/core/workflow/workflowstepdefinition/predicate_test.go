@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestDefinitionChangedPredicate(t *testing.T) {
+	p := definitionChangedPredicate()
+	old := newTestDefinition("predicate", simpleTemplate)
+	old.SetGeneration(1)
+
+	// the status-only update doesn't requeue the definition
+	updated := old.DeepCopy()
+	updated.Status.ConfigMapRef = "workflowstep-schema-predicate"
+	updated.Status.LatestRevision = &common.Revision{Name: "predicate-v1", Revision: 1}
+	require.False(t, p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}))
+
+	// the spec change bumps the generation
+	updated = old.DeepCopy()
+	updated.Spec.Schematic.CUE.Template = `parameter: {}`
+	updated.SetGeneration(2)
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}))
+
+	// the controller requirement is declared by the annotation, which doesn't bump the generation
+	updated = old.DeepCopy()
+	updated.SetAnnotations(map[string]string{oam.AnnotationControllerRequirement: "v1.7.0"})
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}))
+
+	updated = old.DeepCopy()
+	updated.SetLabels(map[string]string{"team": "a"})
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}))
+
+	require.True(t, p.Create(event.CreateEvent{Object: old}))
+	require.True(t, p.Delete(event.DeleteEvent{Object: old}))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	schemaProfileUI  = "ui"
+	schemaProfileCLI = "cli"
+	schemaProfileAPI = "api"
+)
+
+// schemaProfiles are the projections of the schema tailored for the consumers, the UI keeps the whole schema, the CLI
+// drops the examples and the extensions, and the API further drops the titles and the descriptions
+var schemaProfiles = map[string]func(schema *openapi3.Schema){
+	schemaProfileUI: func(schema *openapi3.Schema) {},
+	schemaProfileCLI: func(schema *openapi3.Schema) {
+		schema.Example = nil
+		schema.Extensions = map[string]interface{}{}
+	},
+	schemaProfileAPI: func(schema *openapi3.Schema) {
+		schema.Example = nil
+		schema.Extensions = map[string]interface{}{}
+		schema.Title = ""
+		schema.Description = ""
+	},
+}
+
+// validateSchemaProfiles checks the profiles enabled by the controller are supported
+func validateSchemaProfiles(profiles []string) error {
+	for _, profile := range profiles {
+		if _, ok := schemaProfiles[profile]; !ok {
+			return fmt.Errorf("unsupported schema profile %s, the supported ones are %s, %s and %s", profile, schemaProfileUI, schemaProfileCLI, schemaProfileAPI)
+		}
+	}
+	return nil
+}
+
+// schemaProfileKey returns the data key of the schema variant of the profile in the schema ConfigMap
+func schemaProfileKey(profile string) string {
+	return fmt.Sprintf("openapi-v3-json-schema.%s.json", profile)
+}
+
+// requestedSchemaProfiles returns the profiles requested by the annotation of the definition, or the enabled ones
+// if the definition doesn't request any
+func (r *Reconciler) requestedSchemaProfiles(def *v1beta1.WorkflowStepDefinition) []string {
+	if value, ok := def.GetAnnotations()[oam.AnnotationSchemaProfiles]; ok {
+		return parseNameList(value)
+	}
+	return r.schemaProfiles
+}
+
+// lintSchemaProfiles flags the schema profiles requested by the definition but not supported, they are not generated
+func lintSchemaProfiles(lctx *lintContext) []lintFinding {
+	value, ok := lctx.def.GetAnnotations()[oam.AnnotationSchemaProfiles]
+	if !ok {
+		return nil
+	}
+	var unsupported []string
+	for _, profile := range parseNameList(value) {
+		if _, ok := schemaProfiles[profile]; !ok {
+			unsupported = append(unsupported, profile)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	supported := make([]string, 0, len(schemaProfiles))
+	for profile := range schemaProfiles {
+		supported = append(supported, profile)
+	}
+	sort.Strings(supported)
+	return []lintFinding{{
+		Rule:     "schema-profiles",
+		Severity: lintSeverityWarning,
+		Message: fmt.Sprintf("the schema profiles %s are not supported, the supported ones are %s",
+			strings.Join(unsupported, ", "), strings.Join(supported, ", ")),
+	}}
+}
+
+// schemaProfileGenerator returns the generator of the schema variant of the profile, the variant is projected from
+// a copy of the stored schema so the other artifacts are not affected
+func schemaProfileGenerator(profile string, project func(schema *openapi3.Schema)) artifactGenerator {
+	return artifactGenerator{key: schemaProfileKey(profile), generate: func(actx *artifactContext) (string, error) {
+		schema := &openapi3.Schema{}
+		if err := schema.UnmarshalJSON(actx.schemaData); err != nil {
+			return "", err
+		}
+		walkSchema(schema, project)
+		data, err := schema.MarshalJSON()
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}}
+}
+
+// walkSchema calls the visit on the schema and all the schemas nested in it
+func walkSchema(schema *openapi3.Schema, visit func(schema *openapi3.Schema)) {
+	if schema == nil {
+		return
+	}
+	visit(schema)
+	for _, ref := range schema.Properties {
+		if ref != nil {
+			walkSchema(ref.Value, visit)
+		}
+	}
+	if schema.Items != nil {
+		walkSchema(schema.Items.Value, visit)
+	}
+	if schema.AdditionalProperties != nil {
+		walkSchema(schema.AdditionalProperties.Value, visit)
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, ref := range refs {
+			if ref != nil {
+				walkSchema(ref.Value, visit)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema profiles", func() {
+	It("Schema profiles", func() {
+		def := newTestDefinition("profiled", `
+parameter: {
+	// +usage=The name of the resource
+	name: string
+}
+`)
+		def.SetAnnotations(map[string]string{oam.AnnotationSchemaProfiles: "ui, api, mobile"})
+		r := newTestReconciler(options{schemaProfiles: []string{schemaProfileCLI}}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[schema-profiles] the schema profiles mobile are not supported, the supported ones are api, cli, ui",
+		}))
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data).ShouldNot(HaveKey(schemaProfileKey(schemaProfileCLI)))
+		description := func(profile string) interface{} {
+			Expect(cm.Data).Should(HaveKey(schemaProfileKey(profile)))
+			schema := struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			}{}
+			Expect(json.Unmarshal([]byte(cm.Data[schemaProfileKey(profile)]), &schema)).Should(Succeed())
+			Expect(schema.Properties["name"]["type"]).Should(Equal("string"))
+			return schema.Properties["name"]["description"]
+		}
+		Expect(description(schemaProfileUI)).Should(Equal("The name of the resource"))
+		Expect(description(schemaProfileAPI)).Should(BeNil())
+	})
+})
+
+func TestValidateSchemaProfiles(t *testing.T) {
+	require.NoError(t, validateSchemaProfiles([]string{schemaProfileUI, schemaProfileAPI}))
+	require.Error(t, validateSchemaProfiles([]string{"mobile"}))
+}
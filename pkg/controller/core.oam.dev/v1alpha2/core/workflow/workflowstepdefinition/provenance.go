@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// commitSHAPattern matches the full SHA-1 or SHA-256 commit hashes
+var commitSHAPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// definitionProvenance returns the provenance declared by the annotations of the definition, nil if there is none
+func definitionProvenance(def *v1beta1.WorkflowStepDefinition) *v1beta1.DefinitionProvenance {
+	annotations := def.GetAnnotations()
+	provenance := &v1beta1.DefinitionProvenance{
+		Repository: annotations[types.AnnoDefinitionSourceRepo],
+		Commit:     annotations[types.AnnoDefinitionSourceCommit],
+	}
+	if provenance.Repository == "" && provenance.Commit == "" {
+		return nil
+	}
+	return provenance
+}
+
+// lintProvenance rejects the definition whose source commit is not a full commit SHA
+func lintProvenance(lctx *lintContext) []lintFinding {
+	provenance := definitionProvenance(lctx.def)
+	if provenance == nil || provenance.Commit == "" || commitSHAPattern.MatchString(provenance.Commit) {
+		return nil
+	}
+	return []lintFinding{{
+		Rule:     "provenance",
+		Severity: lintSeverityError,
+		Message: fmt.Sprintf("invalid commit %s of the annotation %s, must be a full SHA-1 or SHA-256 hash in lowercase",
+			provenance.Commit, types.AnnoDefinitionSourceCommit),
+	}}
+}
+
+// storeProvenance propagates the provenance annotations of the definition onto the schema ConfigMap and the DefinitionRevision,
+// the latter is skipped if the revisions are disabled
+func (r *Reconciler) storeProvenance(ctx context.Context, def *v1beta1.WorkflowStepDefinition, cmName, revName string, provenance *v1beta1.DefinitionProvenance) error {
+	annotations := map[string]string{}
+	if provenance.Repository != "" {
+		annotations[types.AnnoDefinitionSourceRepo] = provenance.Repository
+	}
+	if provenance.Commit != "" {
+		annotations[types.AnnoDefinitionSourceCommit] = provenance.Commit
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.schemaNamespaceOf(def), Name: cmName}, cm); err != nil {
+		return err
+	}
+	if err := r.patchAnnotations(ctx, cm, annotations); err != nil {
+		return err
+	}
+	if r.disableRevisions {
+		// there is no DefinitionRevision to annotate
+		return nil
+	}
+	defRev := &v1beta1.DefinitionRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: revName}, defRev); err != nil {
+		return err
+	}
+	return r.patchAnnotations(ctx, defRev, annotations)
+}
+
+// patchAnnotations merges the annotations into the object, it's a no-op if none of them changes
+func (r *Reconciler) patchAnnotations(ctx context.Context, obj client.Object, annotations map[string]string) error {
+	changed := false
+	for k, v := range annotations {
+		if current, ok := obj.GetAnnotations()[k]; !ok || current != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	obj.SetAnnotations(util.MergeMapOverrideWithDst(obj.GetAnnotations(), annotations))
+	return r.Patch(ctx, obj, patch)
+}
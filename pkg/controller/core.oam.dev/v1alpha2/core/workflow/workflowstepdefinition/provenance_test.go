@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema provenance", func() {
+	It("Provenance", func() {
+		ctx := context.Background()
+		const repo, commit = "https://github.com/kubevela/catalog", "8f14e45fceea167a5a36dedd4bea2543d9a7b7d4"
+		def := newTestDefinition("provenanced", simpleTemplate)
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionSourceRepo: repo, types.AnnoDefinitionSourceCommit: commit})
+		r := newTestReconciler(options{provenance: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Provenance).Should(Equal(&v1beta1.DefinitionProvenance{Repository: repo, Commit: commit}))
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Annotations[types.AnnoDefinitionSourceRepo]).Should(Equal(repo))
+		Expect(cm.Annotations[types.AnnoDefinitionSourceCommit]).Should(Equal(commit))
+		defRev := &v1beta1.DefinitionRevision{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.LatestRevision.Name}, defRev)).Should(Succeed())
+		Expect(defRev.Annotations[types.AnnoDefinitionSourceCommit]).Should(Equal(commit))
+
+		// the provenance survives the schema ConfigMap being rewritten
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Annotations[types.AnnoDefinitionSourceCommit]).Should(Equal(commit))
+
+		invalid := newTestDefinition("invalid-provenance", simpleTemplate)
+		invalid.SetAnnotations(map[string]string{types.AnnoDefinitionSourceCommit: "main"})
+		r = newTestReconciler(options{provenance: true}, invalid)
+		got = reconcileTestDefinition(r, invalid)
+		Expect(got.Status.LatestRevision).Should(BeNil())
+		Expect(got.GetCondition(condition.TypeSynced).Message).Should(ContainSubstring("invalid commit main"))
+	})
+
+	It("Provenance without revisions", func() {
+		ctx := context.Background()
+		const repo, commit = "https://github.com/kubevela/catalog", "8f14e45fceea167a5a36dedd4bea2543d9a7b7d4"
+		def := newTestDefinition("provenanced", simpleTemplate)
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionSourceRepo: repo, types.AnnoDefinitionSourceCommit: commit})
+		r := newTestReconciler(options{provenance: true, disableRevisions: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.GetCondition(condition.TypeSynced).Reason).ShouldNot(Equal(condition.ReasonReconcileError))
+		Expect(got.Status.LatestRevision.Name).Should(Equal("provenanced-v1"))
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Annotations[types.AnnoDefinitionSourceRepo]).Should(Equal(repo))
+		Expect(cm.Annotations[types.AnnoDefinitionSourceCommit]).Should(Equal(commit))
+		revList := &v1beta1.DefinitionRevisionList{}
+		Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+		Expect(revList.Items).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// readinessCheckName is the name of the readyz check of the controller
+	readinessCheckName = "workflowstepdefinition"
+	// discoveryRetryInterval is the interval to retry refreshing the DiscoveryMapper until it succeeds on startup
+	discoveryRetryInterval = 5 * time.Second
+	// noKindMatchRequeueDelay is the delay to requeue the definition whose reconciliation meets an unknown kind
+	noKindMatchRequeueDelay = 5 * time.Second
+)
+
+// readiness tracks whether the DiscoveryMapper has been refreshed at least once and the informer cache has synced,
+// before which the controller doesn't report ready
+type readiness struct {
+	dmRefreshed atomic.Bool
+	cacheSynced atomic.Bool
+}
+
+// cacheSyncWaiter waits for the informer cache to sync, which is satisfied by the cache of the manager
+type cacheSyncWaiter interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// readinessProbe refreshes the DiscoveryMapper and waits for the informer cache to sync once the manager starts, it
+// runs on all the replicas regardless of the leader election so that the standby ones report ready as well
+type readinessProbe struct {
+	r     *Reconciler
+	cache cacheSyncWaiter
+}
+
+// Start implements manager.Runnable
+func (p *readinessProbe) Start(ctx context.Context) error {
+	err := wait.PollImmediateUntil(discoveryRetryInterval, func() (bool, error) {
+		if err := p.r.refreshDiscoveryMapper(ctx); err != nil {
+			klog.ErrorS(err, "Could not refresh the DiscoveryMapper of WorkflowStepDefinition controller, will retry")
+			return false, nil
+		}
+		return true, nil
+	}, ctx.Done())
+	if err != nil {
+		// the manager is stopping
+		return nil
+	}
+	if p.cache.WaitForCacheSync(ctx) {
+		p.r.ready.cacheSynced.Store(true)
+		klog.InfoS("WorkflowStepDefinition controller is ready")
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (p *readinessProbe) NeedLeaderElection() bool {
+	return false
+}
+
+// checkReady is the readyz check of the controller
+func (r *Reconciler) checkReady(_ *http.Request) error {
+	if !r.ready.dmRefreshed.Load() {
+		return errors.New("the DiscoveryMapper has not been refreshed")
+	}
+	if !r.ready.cacheSynced.Load() {
+		return errors.New("the informer cache has not synced")
+	}
+	return nil
+}
+
+// refreshDiscoveryMapper refreshes the DiscoveryMapper to discover the newly installed CRDs, the concurrent refreshes
+// are coalesced into one
+func (r *Reconciler) refreshDiscoveryMapper(ctx context.Context) error {
+	if r.dm != nil {
+		if err := r.dmRefresher.refresh(ctx, r.dm, r.dmRefreshInterval); err != nil {
+			return err
+		}
+	}
+	r.ready.dmRefreshed.Store(true)
+	return nil
+}
+
+// isNoKindMatch checks whether the error is caused by a kind or resource unknown to the RESTMapper
+func isNoKindMatch(err error) bool {
+	var kindErr *meta.NoKindMatchError
+	var resourceErr *meta.NoResourceMatchError
+	return errors.As(err, &kindErr) || errors.As(err, &resourceErr)
+}
+
+// requeueNoKindMatch refreshes the DiscoveryMapper and requeues the reconciliation which meets an unknown kind, e.g.
+// the CRD is installed after the controller starts, instead of failing it
+func (r *Reconciler) requeueNoKindMatch(ctx context.Context, err error) ctrl.Result {
+	reconcileLogger(ctx).Info("Refresh the DiscoveryMapper and requeue for the unknown kind", "err", err)
+	if err := r.refreshDiscoveryMapper(ctx); err != nil {
+		reconcileLogger(ctx).Error(err, "Could not refresh the DiscoveryMapper of WorkflowStepDefinition controller")
+	}
+	setReconcileResult(ctx, reconcileResultRequeue)
+	return ctrl.Result{RequeueAfter: noKindMatchRequeueDelay}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
+)
+
+// syncedCache reports the informer cache synced as configured
+type syncedCache bool
+
+func (c syncedCache) WaitForCacheSync(_ context.Context) bool {
+	return bool(c)
+}
+
+// countingDiscoveryMapper returns a mock DiscoveryMapper counting the refreshes, which fail with the given errors in order
+func countingDiscoveryMapper(refreshes *int, errs ...error) *mock.DiscoveryMapper {
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockRefresh = func() (meta.RESTMapper, error) {
+		*refreshes++
+		if len(errs) > 0 {
+			err := errs[0]
+			errs = errs[1:]
+			return nil, err
+		}
+		return nil, nil
+	}
+	return dm
+}
+
+func TestReadiness(t *testing.T) {
+	refreshes := 0
+	r := &Reconciler{}
+	r.dm = countingDiscoveryMapper(&refreshes)
+	require.Contains(t, r.checkReady(nil).Error(), "DiscoveryMapper")
+
+	// not ready until the cache syncs
+	require.NoError(t, (&readinessProbe{r: r, cache: syncedCache(false)}).Start(context.Background()))
+	require.Equal(t, 1, refreshes)
+	require.Contains(t, r.checkReady(nil).Error(), "cache")
+
+	require.NoError(t, (&readinessProbe{r: r, cache: syncedCache(true)}).Start(context.Background()))
+	require.NoError(t, r.checkReady(nil))
+	require.False(t, (&readinessProbe{}).NeedLeaderElection())
+}
+
+func TestReadinessStopped(t *testing.T) {
+	refreshes := 0
+	r := &Reconciler{}
+	r.dm = countingDiscoveryMapper(&refreshes, errors.New("boom"), errors.New("boom"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// the probe gives up refreshing once the manager stops
+	require.NoError(t, (&readinessProbe{r: r, cache: syncedCache(true)}).Start(ctx))
+	require.Contains(t, r.checkReady(nil).Error(), "DiscoveryMapper")
+}
+
+var _ = Describe("Test WorkflowStepDefinition readiness", func() {
+	It("Reconcile unknown kind", func() {
+		ctx := context.Background()
+		def := newTestDefinition("unknown-kind", simpleTemplate)
+		refreshes := 0
+		r := newTestReconciler(options{sweepOrphanRevs: true}, def)
+		r.dm = countingDiscoveryMapper(&refreshes)
+		cli := &noKindMatchClient{Client: r.Client}
+		r.Client = cli
+
+		// the unknown kind requeues the definition after refreshing the DiscoveryMapper instead of failing it
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.RequeueAfter).Should(Equal(noKindMatchRequeueDelay))
+		Expect(refreshes).Should(Equal(1))
+		got := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(def), got)).Should(Succeed())
+		Expect(got.Status.GetCondition(condition.TypeSynced).Reason).ShouldNot(Equal(condition.ReasonReconcileError))
+
+		// the requeued definition is reconciled once the CRD is installed
+		cli.installed = true
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision).ShouldNot(BeNil())
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+	})
+})
+
+// noKindMatchClient fails listing the DefinitionRevisions as if the RESTMapper didn't know their kind yet
+type noKindMatchClient struct {
+	client.Client
+	installed bool
+}
+
+func (c *noKindMatchClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*v1beta1.DefinitionRevisionList); ok && !c.installed {
+		return fmt.Errorf("cannot list: %w", &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: v1beta1.Group, Kind: v1beta1.DefinitionRevisionKind}})
+	}
+	return c.Client.List(ctx, list, opts...)
+}
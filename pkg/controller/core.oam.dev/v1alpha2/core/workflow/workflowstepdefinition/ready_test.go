@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+)
+
+var _ = Describe("Test WorkflowStepDefinition ready condition", func() {
+	It("Ready condition", func() {
+		ctx := context.Background()
+		def := newTestDefinition("ready", "parameter: {")
+		recorder := &recordingRecorder{}
+		r := newTestReconciler(options{}, def)
+		r.record = recorder
+
+		// the broken definition is never ready
+		got := reconcileTestDefinition(r, def)
+		synced := got.Status.GetCondition(condition.TypeSynced)
+		Expect(synced.Reason).Should(Equal(condition.ReasonReconcileError))
+		Expect(synced.Status).Should(Equal(corev1.ConditionFalse))
+		for _, e := range recorder.events {
+			Expect(e.Type).Should(Equal(event.TypeWarning))
+		}
+
+		// fixing the template flips the condition back to success with a single Normal event
+		got.Spec.Schematic.CUE.Template = simpleTemplate
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		recorder.events = nil
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		Expect(got.Status.LatestRevision).ShouldNot(BeNil())
+		synced = got.Status.GetCondition(condition.TypeSynced)
+		Expect(synced.Reason).Should(Equal(condition.ReasonReconcileSuccess))
+		Expect(synced.Status).Should(Equal(corev1.ConditionTrue))
+		Expect(synced.Message).Should(BeEmpty())
+		Expect(recorder.events).Should(HaveLen(1))
+		Expect(recorder.events[0].Type).Should(Equal(event.TypeNormal))
+		Expect(string(recorder.events[0].Reason)).Should(Equal("WorkflowStepDefinition is ready"))
+
+		// the steady state doesn't emit the event again
+		recorder.events = nil
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.GetCondition(condition.TypeSynced).Reason).Should(Equal(condition.ReasonReconcileSuccess))
+		Expect(recorder.events).Should(BeEmpty())
+	})
+})
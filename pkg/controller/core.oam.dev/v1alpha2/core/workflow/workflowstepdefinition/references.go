@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// the kinds of the definitions a WorkflowStepDefinition can refer to
+const (
+	referenceKindComponent = "component"
+	referenceKindTrait     = "trait"
+)
+
+// definitionReference is a ComponentDefinition or TraitDefinition the WorkflowStepDefinition refers to
+type definitionReference struct {
+	Kind string
+	Name string
+}
+
+func (ref definitionReference) String() string {
+	return ref.Kind + "/" + ref.Name
+}
+
+// definitionReferences returns the definitions declared in the references annotation of the WorkflowStepDefinition
+func definitionReferences(def *v1beta1.WorkflowStepDefinition) []definitionReference {
+	var refs []definitionReference
+	for _, item := range strings.Split(def.GetAnnotations()[oam.AnnotationDefinitionReferences], ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		kind, name, _ := strings.Cut(item, "/")
+		refs = append(refs, definitionReference{Kind: strings.TrimSpace(kind), Name: strings.TrimSpace(name)})
+	}
+	return refs
+}
+
+// lintReferences flags the referenced definitions missing in both the namespace of the WorkflowStepDefinition and the
+// system definition namespace, the references of the unknown kinds are flagged as well
+func (r *Reconciler) lintReferences(lctx *lintContext) []lintFinding {
+	ctx := util.SetNamespaceInCtx(lctx.ctx, lctx.def.Namespace)
+	var findings []lintFinding
+	for _, ref := range definitionReferences(lctx.def) {
+		var obj client.Object
+		switch ref.Kind {
+		case referenceKindComponent:
+			obj = &v1beta1.ComponentDefinition{}
+		case referenceKindTrait:
+			obj = &v1beta1.TraitDefinition{}
+		default:
+			findings = append(findings, lintFinding{
+				Rule:     "references",
+				Severity: lintSeverityWarning,
+				Message:  fmt.Sprintf("invalid reference %s, must be in the format of %s/<name> or %s/<name>", ref, referenceKindComponent, referenceKindTrait),
+			})
+			continue
+		}
+		err := util.GetDefinition(ctx, r.Client, obj, ref.Name)
+		if err == nil {
+			continue
+		}
+		message := fmt.Sprintf("cannot resolve the referenced %s definition %s: %s", ref.Kind, ref.Name, err.Error())
+		if apierrors.IsNotFound(err) {
+			message = fmt.Sprintf("the referenced %s definition %s is not found", ref.Kind, ref.Name)
+		}
+		findings = append(findings, lintFinding{Rule: "references", Severity: lintSeverityWarning, Message: message})
+	}
+	return findings
+}
+
+// lintTraitOverlap flags the top-level parameters whose names match the traits available to the WorkflowStepDefinition,
+// which may duplicate the functionality of the traits. The names are compared case-insensitively ignoring the dashes
+// and underscores, e.g. the parameter labels overlaps the trait labels and the parameter serviceAccount overlaps the
+// trait service-account.
+func (r *Reconciler) lintTraitOverlap(lctx *lintContext) []lintFinding {
+	traits := map[string]string{}
+	for _, namespace := range []string{oam.SystemDefinitionNamespace, lctx.def.Namespace} {
+		traitList := &v1beta1.TraitDefinitionList{}
+		if err := r.List(lctx.ctx, traitList, client.InNamespace(namespace)); err != nil {
+			reconcileLogger(lctx.ctx).Error(err, "Could not list the TraitDefinitions", "namespace", namespace)
+			return nil
+		}
+		for _, trait := range traitList.Items {
+			traits[normalizeDefinitionName(trait.Name)] = trait.Name
+		}
+	}
+	var findings []lintFinding
+	for _, field := range flattenParameters(lctx.schema) {
+		if field.Depth != 1 {
+			continue
+		}
+		if trait, ok := traits[normalizeDefinitionName(field.Path)]; ok {
+			findings = append(findings, lintFinding{
+				Rule:     "trait-overlap",
+				Severity: lintSeverityWarning,
+				Path:     field.Path,
+				Message:  fmt.Sprintf("the parameter may duplicate the functionality of the trait %s", trait),
+			})
+		}
+	}
+	return findings
+}
+
+func normalizeDefinitionName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
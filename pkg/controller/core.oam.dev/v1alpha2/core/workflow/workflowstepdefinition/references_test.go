@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition reference lint", func() {
+	It("Lint references", func() {
+		def := newTestDefinition("deploy-with-traits", simpleTemplate)
+		def.SetAnnotations(map[string]string{oam.AnnotationDefinitionReferences: "component/webservice, trait/scaler, trait/unknown, policy/topology"})
+		webservice := &v1beta1.ComponentDefinition{}
+		webservice.SetName("webservice")
+		webservice.SetNamespace(oam.SystemDefinitionNamespace)
+		scaler := &v1beta1.TraitDefinition{}
+		scaler.SetName("scaler")
+		scaler.SetNamespace("default")
+		r := newTestReconciler(options{checkReferences: true}, def, webservice, scaler)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[references] the referenced trait definition unknown is not found",
+			"[references] invalid reference policy/topology, must be in the format of component/<name> or trait/<name>",
+		}))
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+	})
+
+	It("Lint trait overlap", func() {
+		def := newTestDefinition("deploy", `
+parameter: {
+	image: string
+	labels?: [string]: string
+	service_account?: string
+	value: {
+		annotations?: [string]: string
+	}
+}
+`)
+		var traits []client.Object
+		for _, name := range []string{"labels", "annotations", "service-account"} {
+			trait := &v1beta1.TraitDefinition{}
+			trait.SetName(name)
+			trait.SetNamespace(oam.SystemDefinitionNamespace)
+			traits = append(traits, trait)
+		}
+		r := newTestReconciler(options{checkTraitOverlap: true}, append(traits, def)...)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[trait-overlap] labels: the parameter may duplicate the functionality of the trait labels",
+			"[trait-overlap] service_account: the parameter may duplicate the functionality of the trait service-account",
+		}))
+	})
+})
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/workflow/pkg/cue/model/value"
+)
+
+// containerListFields are the fields of the pod spec listing the containers
+var containerListFields = []string{"containers", "initContainers"}
+
+// lintResourceRequirements flags the containers run by the step without declaring the resource requirements, the
+// containers are found by the container lists of the pod specs in the template, e.g. the ones of the applied Jobs
+func lintResourceRequirements(lctx *lintContext) []lintFinding {
+	schematic := lctx.def.Spec.Schematic
+	if schematic == nil || schematic.CUE == nil {
+		return nil
+	}
+	v, err := value.NewValue(schematic.CUE.Template, nil, "")
+	if err != nil {
+		// the invalid template is left to the render to report
+		return nil
+	}
+	var findings []lintFinding
+	v.CueValue().Walk(func(v cue.Value) bool {
+		if v.Kind() != cue.StructKind {
+			return true
+		}
+		for _, field := range containerListFields {
+			list := v.LookupPath(cue.ParsePath(field))
+			if list.Kind() != cue.ListKind {
+				continue
+			}
+			iter, err := list.List()
+			if err != nil {
+				continue
+			}
+			listPath := field
+			if path := v.Path().String(); path != "" {
+				listPath = path + "." + field
+			}
+			for i := 0; iter.Next(); i++ {
+				container := iter.Value()
+				if container.LookupPath(cue.ParsePath("resources")).Exists() {
+					continue
+				}
+				name := fmt.Sprintf("%d", i)
+				if s, err := container.LookupPath(cue.ParsePath("name")).String(); err == nil {
+					name = s
+				}
+				findings = append(findings, lintFinding{
+					Rule:     "resource-requirements",
+					Severity: lintSeverityWarning,
+					Message:  fmt.Sprintf("the container %s in %s declares no resource requests or limits", name, listPath),
+				})
+			}
+		}
+		return true
+	}, nil)
+	return findings
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test WorkflowStepDefinition resource requirements lint", func() {
+	It("Lint resource requirements", func() {
+		def := newTestDefinition("run-job", `
+import "vela/op"
+
+job: op.#Apply & {
+	value: {
+		apiVersion: "batch/v1"
+		kind:       "Job"
+		spec: template: spec: {
+			initContainers: [{
+				name:  "init"
+				image: "busybox"
+				resources: limits: cpu: "100m"
+			}]
+			containers: [{
+				name:  "main"
+				image: parameter.image
+			}]
+		}
+	}
+}
+parameter: {
+	image: string
+}
+`)
+		r := newTestReconciler(options{checkResources: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[resource-requirements] the container main in job.value.spec.template.spec.containers declares no resource requests or limits",
+		}))
+
+		def = newTestDefinition("no-container", simpleTemplate)
+		r = newTestReconciler(options{checkResources: true}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(got.Status.Warnings).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// Rollback restores the spec of the WorkflowStepDefinition from the given revision and points its status.latestRevision
+// to the revision. The reconciliation is paused by the annotation oam.AnnotationDisableReconcile while the spec and the
+// status are updated, so that the controller doesn't generate a new revision from the half-restored definition. The
+// reconciliation is resumed even if the rollback fails halfway, otherwise the definition stays paused.
+func Rollback(ctx context.Context, cli client.Client, def *v1beta1.WorkflowStepDefinition, revision int64) (err error) {
+	defRev, err := findRevision(ctx, cli, def, revision)
+	if err != nil {
+		return err
+	}
+
+	paused := def.GetAnnotations()[oam.AnnotationDisableReconcile] == "true"
+	def.Spec = *defRev.Spec.WorkflowStepDefinition.Spec.DeepCopy()
+	if !paused {
+		def.SetAnnotations(util.MergeMapOverrideWithDst(def.GetAnnotations(), map[string]string{oam.AnnotationDisableReconcile: "true"}))
+	}
+	if err := cli.Update(ctx, def); err != nil {
+		return fmt.Errorf("failed to restore the WorkflowStepDefinition %s from the revision %s: %w", def.Name, defRev.Name, err)
+	}
+	if !paused {
+		defer func() {
+			if resumeErr := resumeReconcile(ctx, cli, def); resumeErr != nil && err == nil {
+				err = fmt.Errorf("failed to resume the reconciliation of WorkflowStepDefinition %s: %w", def.Name, resumeErr)
+			}
+		}()
+	}
+	def.Status.LatestRevision = &common.Revision{
+		Name:         defRev.Name,
+		Revision:     defRev.Spec.Revision,
+		RevisionHash: defRev.Spec.RevisionHash,
+	}
+	r := &Reconciler{Client: cli}
+	if err := r.UpdateStatus(ctx, def); err != nil {
+		return fmt.Errorf("failed to update the latest revision of WorkflowStepDefinition %s to %s: %w", def.Name, defRev.Name, err)
+	}
+	return nil
+}
+
+// resumeReconcile removes the annotation pausing the reconciliation of the WorkflowStepDefinition
+func resumeReconcile(ctx context.Context, cli client.Client, def *v1beta1.WorkflowStepDefinition) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := cli.Get(ctx, client.ObjectKeyFromObject(def), def); err != nil {
+			return err
+		}
+		annotations := def.GetAnnotations()
+		if _, ok := annotations[oam.AnnotationDisableReconcile]; !ok {
+			return nil
+		}
+		delete(annotations, oam.AnnotationDisableReconcile)
+		def.SetAnnotations(annotations)
+		return cli.Update(ctx, def)
+	})
+}
+
+// findRevision returns the DefinitionRevision of the given revision number owned by the WorkflowStepDefinition
+func findRevision(ctx context.Context, cli client.Client, def *v1beta1.WorkflowStepDefinition, revision int64) (*v1beta1.DefinitionRevision, error) {
+	if revision <= 0 {
+		return nil, fmt.Errorf("invalid revision %d of WorkflowStepDefinition %s, the revisions start from 1", revision, def.Name)
+	}
+	revList := &v1beta1.DefinitionRevisionList{}
+	if err := cli.List(ctx, revList, client.InNamespace(def.Namespace), client.MatchingLabels{oam.LabelWorkflowStepDefinitionName: def.Name}); err != nil {
+		return nil, err
+	}
+	var oldest int64
+	for i := range revList.Items {
+		rev := &revList.Items[i]
+		if !ownsRevision(def, rev) {
+			continue
+		}
+		if rev.Spec.Revision == revision {
+			return rev, nil
+		}
+		if oldest == 0 || rev.Spec.Revision < oldest {
+			oldest = rev.Spec.Revision
+		}
+	}
+
+	// the revision not listed by the label may be created for another definition
+	revName := coredef.ConstructDefinitionRevisionName(def.Name, strconv.FormatInt(revision, 10))
+	rev := &v1beta1.DefinitionRevision{}
+	err := cli.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: revName}, rev)
+	if err == nil && !ownsRevision(def, rev) {
+		return nil, fmt.Errorf("the DefinitionRevision %s is not owned by the WorkflowStepDefinition %s", revName, def.Name)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if latest := def.Status.LatestRevision; latest != nil && revision < latest.Revision {
+		if oldest == 0 {
+			return nil, fmt.Errorf("the revision %d of WorkflowStepDefinition %s has been garbage collected by the revision limit, there are no retained revisions",
+				revision, def.Name)
+		}
+		return nil, fmt.Errorf("the revision %d of WorkflowStepDefinition %s has been garbage collected by the revision limit, the oldest retained revision is %d",
+			revision, def.Name, oldest)
+	}
+	return nil, fmt.Errorf("the revision %d of WorkflowStepDefinition %s does not exist", revision, def.Name)
+}
+
+// ownsRevision checks whether the DefinitionRevision is generated from the WorkflowStepDefinition
+func ownsRevision(def *v1beta1.WorkflowStepDefinition, rev *v1beta1.DefinitionRevision) bool {
+	return rev.Spec.DefinitionType == common.WorkflowStepType &&
+		rev.Spec.WorkflowStepDefinition.Name == def.Name &&
+		rev.GetLabels()[oam.LabelWorkflowStepDefinitionName] == def.Name
+}
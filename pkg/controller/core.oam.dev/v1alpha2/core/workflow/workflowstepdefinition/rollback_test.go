@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// failingStatusClient fails the status updates with the error
+type failingStatusClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingStatusClient) Status() client.StatusWriter {
+	return &failingStatusWriter{StatusWriter: c.Client.Status(), err: c.err}
+}
+
+type failingStatusWriter struct {
+	client.StatusWriter
+	err error
+}
+
+func (w *failingStatusWriter) Update(context.Context, client.Object, ...client.UpdateOption) error {
+	return w.err
+}
+
+var _ = Describe("Test WorkflowStepDefinition rollback", func() {
+	Context("Rollback", func() {
+		ctx := context.Background()
+		templates := []string{`
+parameter: {
+	name: string
+}
+`, `
+parameter: {
+	name:  string
+	image: string
+}
+`, `
+parameter: {
+	image: string
+}
+`}
+		setup := func(opts options) (*Reconciler, *v1beta1.WorkflowStepDefinition) {
+			def := newTestDefinition("rollback", templates[0])
+			r := newTestReconciler(opts, def)
+			got := reconcileTestDefinition(r, def)
+			for _, template := range templates[1:] {
+				got.Spec.Schematic.CUE.Template = template
+				Expect(r.Update(ctx, got)).Should(Succeed())
+				got = reconcileTestDefinition(r, got)
+			}
+			Expect(got.Status.LatestRevision.Name).Should(Equal("rollback-v3"))
+			return r, got
+		}
+
+		It("restore the retained revision", func() {
+			r, got := setup(options{})
+			Expect(Rollback(ctx, r.Client, got, 1)).Should(Succeed())
+			got = &v1beta1.WorkflowStepDefinition{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "rollback"}, got)).Should(Succeed())
+			Expect(got.Spec.Schematic.CUE.Template).Should(Equal(templates[0]))
+			Expect(got.Status.LatestRevision.Name).Should(Equal("rollback-v1"))
+			Expect(got.Status.LatestRevision.Revision).Should(Equal(int64(1)))
+			Expect(got.GetAnnotations()).ShouldNot(HaveKey(oam.AnnotationDisableReconcile))
+
+			// the restored definition matches the revision, no new revision is generated
+			got = reconcileTestDefinition(r, got)
+			Expect(got.Status.LatestRevision.Name).Should(Equal("rollback-v1"))
+			revList := &v1beta1.DefinitionRevisionList{}
+			Expect(r.List(ctx, revList, client.InNamespace("default"))).Should(Succeed())
+			Expect(revList.Items).Should(HaveLen(3))
+
+			err := Rollback(ctx, r.Client, got, 9)
+			Expect(err).Should(MatchError("the revision 9 of WorkflowStepDefinition rollback does not exist"))
+			Expect(Rollback(ctx, r.Client, got, 0)).Should(HaveOccurred())
+
+			// the revision created for another definition is not rolled back to
+			Expect(r.Create(ctx, &v1beta1.DefinitionRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "rollback-v7", Namespace: "default"},
+				Spec: v1beta1.DefinitionRevisionSpec{
+					Revision:               7,
+					DefinitionType:         common.WorkflowStepType,
+					WorkflowStepDefinition: v1beta1.WorkflowStepDefinition{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+				},
+			})).Should(Succeed())
+			err = Rollback(ctx, r.Client, got, 7)
+			Expect(err).Should(MatchError("the DefinitionRevision rollback-v7 is not owned by the WorkflowStepDefinition rollback"))
+		})
+
+		It("refuse the revision garbage collected by the revision limit", func() {
+			r, got := setup(options{defRevLimit: 1})
+			err := Rollback(ctx, r.Client, got, 1)
+			Expect(err).Should(MatchError("the revision 1 of WorkflowStepDefinition rollback has been garbage collected by the revision limit, the oldest retained revision is 2"))
+			Expect(got.Spec.Schematic.CUE.Template).Should(Equal(templates[2]))
+
+			// no revision may be retained at all
+			Expect(r.DeleteAllOf(ctx, &v1beta1.DefinitionRevision{}, client.InNamespace("default"))).Should(Succeed())
+			err = Rollback(ctx, r.Client, got, 2)
+			Expect(err).Should(MatchError("the revision 2 of WorkflowStepDefinition rollback has been garbage collected by the revision limit, there are no retained revisions"))
+		})
+	})
+
+	It("Rollback resumes on failure", func() {
+		ctx := context.Background()
+		def := newTestDefinition("rollback", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	image: string
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+
+		cli := &failingStatusClient{Client: r.Client, err: errors.New("boom")}
+		err := Rollback(ctx, cli, got, 1)
+		Expect(err).Should(MatchError("failed to update the latest revision of WorkflowStepDefinition rollback to rollback-v1: boom"))
+		got = &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "rollback"}, got)).Should(Succeed())
+		Expect(got.GetAnnotations()).ShouldNot(HaveKey(oam.AnnotationDisableReconcile))
+		Expect(got.Status.LatestRevision.Name).Should(Equal("rollback-v2"))
+
+		// the reconciliation paused by the user stays paused
+		got.SetAnnotations(map[string]string{oam.AnnotationDisableReconcile: "true"})
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		Expect(Rollback(ctx, cli, got, 1)).Should(HaveOccurred())
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "rollback"}, got)).Should(Succeed())
+		Expect(got.GetAnnotations()[oam.AnnotationDisableReconcile]).Should(Equal("true"))
+	})
+})
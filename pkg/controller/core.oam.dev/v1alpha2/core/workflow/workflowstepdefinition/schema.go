@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// parameterField is a parameter flattened from the OpenAPI schema of the WorkflowStepDefinition
+type parameterField struct {
+	// Path is the dotted path of the parameter, the items of an array parameter are marked by `[]`
+	Path string
+	// Depth is the nesting depth of the parameter, the top-level parameters have depth 1
+	Depth int
+	// Required indicates whether the parameter is required by its parent
+	Required bool
+	Schema   *openapi3.Schema
+}
+
+// renderParameterSchema renders the OpenAPI v3 schema of the parameter section of the WorkflowStepDefinition
+func renderParameterSchema(def *utils.CapabilityStepDefinition) ([]byte, *openapi3.Schema, error) {
+	data, err := def.GetOpenAPISchema(def.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON(data); err != nil {
+		return nil, nil, err
+	}
+	return data, schema, nil
+}
+
+// renderLocatedParameterSchema renders the parameter schema like renderParameterSchema, the rendering error is located
+// in the CUE template
+func renderLocatedParameterSchema(def *utils.CapabilityStepDefinition) ([]byte, *openapi3.Schema, error) {
+	data, schema, err := renderParameterSchema(def)
+	if err != nil && def.StepDefinition.Spec.Schematic != nil && def.StepDefinition.Spec.Schematic.CUE != nil {
+		err = locateSchemaError(def.StepDefinition.Spec.Schematic.CUE.Template, err)
+	}
+	return data, schema, err
+}
+
+// requiredSchemaKey is the data key of the schema variant containing only the required parameters in the schema ConfigMap
+const requiredSchemaKey = "schema.required.json"
+
+// generateRequiredSchema generates the schema variant for the quick-start UIs, which contains only the required parameters
+// without default value, the nested parameters are pruned in the same way
+func generateRequiredSchema(actx *artifactContext) (string, error) {
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON(actx.schemaData); err != nil {
+		return "", err
+	}
+	pruneOptionalParameters(schema)
+	data, err := schema.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func pruneOptionalParameters(schema *openapi3.Schema) {
+	required := sets.NewString(schema.Required...)
+	for name, ref := range schema.Properties {
+		if ref == nil || ref.Value == nil || !isRequiredParameter(parameterField{Required: required.Has(name), Schema: ref.Value}) {
+			delete(schema.Properties, name)
+			continue
+		}
+		pruneOptionalParameters(ref.Value)
+		if items := ref.Value.Items; items != nil && items.Value != nil {
+			pruneOptionalParameters(items.Value)
+		}
+	}
+	var kept []string
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; ok {
+			kept = append(kept, name)
+		}
+	}
+	schema.Required = kept
+}
+
+// schemaFingerprint returns the sha256 fingerprint of the rendered schema
+func schemaFingerprint(schema []byte) string {
+	sum := sha256.Sum256(schema)
+	return hex.EncodeToString(sum[:])
+}
+
+// schemaID returns the versioned URL identifying the schema of the definition revision, e.g. <base>/<name>/v<revision>
+func schemaID(baseURL, name string, revision int64) string {
+	return fmt.Sprintf("%s/%s/v%d", strings.TrimSuffix(baseURL, "/"), name, revision)
+}
+
+// isRequiredParameter checks whether the parameter must be given by the user, i.e. it's required and has no default value
+func isRequiredParameter(field parameterField) bool {
+	return field.Required && field.Schema.Default == nil
+}
+
+// flattenParameters walks the schema and returns all the parameters, nested ones included, ordered by path
+func flattenParameters(schema *openapi3.Schema) []parameterField {
+	var fields []parameterField
+	var walk func(prefix string, depth int, s *openapi3.Schema)
+	walk = func(prefix string, depth int, s *openapi3.Schema) {
+		required := sets.NewString(s.Required...)
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ref := s.Properties[name]
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			fields = append(fields, parameterField{Path: path, Depth: depth, Required: required.Has(name), Schema: ref.Value})
+			walk(path, depth+1, ref.Value)
+			if items := ref.Value.Items; items != nil && items.Value != nil {
+				walk(path+"[]", depth+1, items.Value)
+			}
+		}
+	}
+	if schema != nil {
+		walk("", 1, schema)
+	}
+	return fields
+}
+
+// checkNameLengths checks the names derived from the WorkflowStepDefinition, i.e. the name of its next revision and the
+// names of the schema ConfigMaps of it and the revision, don't exceed the limit of the Kubernetes object names. The API
+// server rejects the overflowing names with the errors hardly pointing back to the definition name
+func (r *Reconciler) checkNameLengths(def *v1beta1.WorkflowStepDefinition) error {
+	revision := def.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
+	if revision == "" {
+		var next int64 = 1
+		if def.Status.LatestRevision != nil {
+			next = def.Status.LatestRevision.Revision + 1
+		}
+		revision = strconv.FormatInt(next, 10)
+	}
+	revName := coredef.ConstructDefinitionRevisionName(def.Name, revision)
+	capability := utils.CapabilityStepDefinition{}
+	capability.ConfigMapNamePrefix = r.configMapPrefixOf(def)
+	capability.ConfigMapNameTemplate = r.cmNameTemplate
+	names := []string{revName}
+	for _, revision := range []string{"", revName} {
+		name, err := capability.RenderSchemaConfigMapName(def.Namespace, def.Name, revision)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if overflow := len(name) - validation.DNS1123SubdomainMaxLength; overflow > 0 {
+			return fmt.Errorf("the derived name %s is %d characters long and exceeds the limit of %d, shorten the name of WorkflowStepDefinition by %d characters",
+				name, len(name), validation.DNS1123SubdomainMaxLength, overflow)
+		}
+	}
+	return nil
+}
+
+// configMapNameReserve is the length reserved for the definition name and the revision suffix in the schema ConfigMap names
+const configMapNameReserve = validation.DNS1123LabelMaxLength
+
+// validateConfigMapPrefix checks the prefix keeps the schema ConfigMap names valid for the definition names up to
+// configMapNameReserve characters, the longer names are checked when the ConfigMaps are stored
+func validateConfigMapPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	def := utils.CapabilityStepDefinition{}
+	def.ConfigMapNamePrefix = prefix
+	base := def.SchemaConfigMapName("")
+	if max := validation.DNS1123SubdomainMaxLength - configMapNameReserve; len(base) > max {
+		return fmt.Errorf("the ConfigMap name prefix %s is too long, the prefixed %s exceeds %d characters", prefix, base, max)
+	}
+	if errs := validation.IsDNS1123Subdomain(base + "x"); len(errs) > 0 {
+		return fmt.Errorf("invalid ConfigMap name prefix %s: %s", prefix, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// parseConfigMapNameTemplate parses the template of the schema ConfigMap names, the empty one means the default names.
+// It's rendered for a sample definition and its revision to fail fast on the template never rendering the valid and
+// distinct names, the names of the actual definitions are checked when they're reconciled.
+func parseConfigMapNameTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("configMapName").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ConfigMap name template %q: %w", text, err)
+	}
+	def := utils.CapabilityStepDefinition{ConfigMapNameTemplate: tmpl}
+	cmName, err := def.RenderSchemaConfigMapName("default", "sample", "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ConfigMap name template %q: %w", text, err)
+	}
+	revCMName, err := def.RenderSchemaConfigMapName("default", "sample", "sample-v1")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ConfigMap name template %q: %w", text, err)
+	}
+	if cmName == revCMName {
+		return nil, fmt.Errorf("invalid ConfigMap name template %q: the definition and its revisions share the name %s, render the revision in it", text, cmName)
+	}
+	return tmpl, nil
+}
+
+// cleanupStaleConfigMap deletes the schema ConfigMap the definition referred to before the ConfigMap name prefix or the
+// schema namespace changes, the empty namespace means the namespace of the definition. The ConfigMaps of the
+// revisions are left to be collected with the revisions.
+func (r *Reconciler) cleanupStaleConfigMap(ctx context.Context, def *v1beta1.WorkflowStepDefinition, staleNamespace, staleName string) error {
+	if staleNamespace == "" {
+		staleNamespace = def.Namespace
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: staleNamespace, Name: staleName}, cm); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !linkedConfigMap(cm, def) {
+		return nil
+	}
+	if err := r.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	reconcileLogger(ctx).Info("Successfully deleted the stale schema ConfigMap of WorkflowStepDefinition", "configMap", klog.KObj(cm))
+	return nil
+}
+
+// schemaStored checks whether the schema ConfigMaps of the definition and the revision already store the data managed
+// by the reconciliation, so that storing the schema again can be skipped without the ConfigMap churn. The data is
+// compared by the hash the last reconciliation recorded, the immutable ConfigMap is named after the hash of its data
+// instead.
+func (r *Reconciler) schemaStored(ctx context.Context, def *v1beta1.WorkflowStepDefinition, cmName, revCMName string, managedData map[string]string) (bool, error) {
+	status := def.Status
+	if status.ConfigMapRef != cmName || status.ConfigMapNamespace != r.configMapNamespaceRef(def) {
+		return false, nil
+	}
+	if !r.immutableSchemas && (managedData == nil || status.ConfigMapHash != configMapDataHash(managedData)) {
+		return false, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.schemaNamespaceOf(def), Name: cmName}, cm); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	labels := map[string]string{types.LabelDefinition: "schema", types.LabelDefinitionName: def.Name}
+	for k, v := range def.Labels {
+		labels[k] = v
+	}
+	if r.centralized(def) {
+		labels[oam.LabelWorkflowStepDefinitionName] = def.Name
+		labels[oam.LabelWorkflowStepDefinitionNamespace] = def.Namespace
+	}
+	if !reflect.DeepEqual(cm.Labels, labels) {
+		return false, nil
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.schemaNamespaceOf(def), Name: revCMName}, &corev1.ConfigMap{}); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return true, nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema ConfigMap", func() {
+	It("Schema ID", func() {
+		ctx := context.Background()
+		def := newTestDefinition("apply-object", simpleTemplate)
+		r := newTestReconciler(options{schemaIDBaseURL: "https://schemas.example.com/steps/"}, def)
+		got := reconcileTestDefinition(r, def)
+
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:      string
+	namespace: *"default" | string
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+
+		readSchemaID := func(cmName string) string {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: cmName}, cm)).Should(Succeed())
+			schema := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(cm.Data[types.OpenapiV3JSONSchema]), &schema)).Should(Succeed())
+			Expect(schema).Should(HaveKey("properties"))
+			return schema["$id"].(string)
+		}
+		Expect(readSchemaID(got.Status.ConfigMapRef)).Should(Equal("https://schemas.example.com/steps/apply-object/v2"))
+		Expect(readSchemaID(got.Status.ConfigMapRef + "-v1")).Should(Equal("https://schemas.example.com/steps/apply-object/v1"))
+		Expect(readSchemaID(got.Status.ConfigMapRef + "-v2")).Should(Equal("https://schemas.example.com/steps/apply-object/v2"))
+	})
+
+	It("A11y extension", func() {
+		ctx := context.Background()
+		def := newTestDefinition("a11y", `
+parameter: {
+	replicas: *1 | int @a11y(label="Number of replicas",hint="Between 1 and 10",color="red")
+	image: {
+		name: string @a11y(label="Image name")
+		tag:  *"latest" | string
+	}
+}
+`)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		schema := struct {
+			Properties map[string]struct {
+				A11y       map[string]string `json:"x-a11y"`
+				Properties map[string]struct {
+					A11y map[string]string `json:"x-a11y"`
+				} `json:"properties"`
+			} `json:"properties"`
+		}{}
+		Expect(json.Unmarshal([]byte(cm.Data[types.OpenapiV3JSONSchema]), &schema)).Should(Succeed())
+		Expect(schema.Properties["replicas"].A11y).Should(Equal(map[string]string{"label": "Number of replicas", "hint": "Between 1 and 10"}))
+		Expect(schema.Properties["image"].A11y).Should(BeNil())
+		Expect(schema.Properties["image"].Properties["name"].A11y).Should(Equal(map[string]string{"label": "Image name"}))
+		Expect(schema.Properties["image"].Properties["tag"].A11y).Should(BeNil())
+	})
+
+	It("Schema version", func() {
+		ctx := context.Background()
+		def := newTestDefinition("versioned", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.SchemaVersion).Should(Equal(int64(1)))
+		Expect(got.Status.SchemaHash).ShouldNot(BeEmpty())
+
+		// reconcile without change should keep the version
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.SchemaVersion).Should(Equal(int64(1)))
+
+		// changing the template without touching the parameter creates a revision but keeps the version
+		got.Spec.Schematic.CUE.Template = simpleTemplate + `
+output: parameter.name
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Revision).Should(Equal(int64(2)))
+		Expect(got.Status.SchemaVersion).Should(Equal(int64(1)))
+
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.SchemaVersion).Should(Equal(int64(2)))
+	})
+
+	It("Schema store dedup", func() {
+		ctx := context.Background()
+		def := newTestDefinition("deduplicated", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		resourceVersion := cm.ResourceVersion
+
+		// the unchanged schema is not stored again
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.ResourceVersion).Should(Equal(resourceVersion))
+
+		// the template edit changing the schema is stored
+		hash := got.Status.SchemaHash
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.SchemaHash).ShouldNot(Equal(hash))
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.ResourceVersion).ShouldNot(Equal(resourceVersion))
+		Expect(cm.Data[types.OpenapiV3JSONSchema]).Should(ContainSubstring("replicas"))
+
+		// the deleted ConfigMap is stored again though the schema is unchanged
+		Expect(r.Delete(ctx, cm)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data[types.OpenapiV3JSONSchema]).Should(ContainSubstring("replicas"))
+
+		// the option changing the stored schema rewrites it though the rendered parameters are unchanged, which isn't
+		// taken for the drift
+		r.schemaIDBaseURL = "https://schemas.example.com"
+		for i := 0; i < 2; i++ {
+			got = reconcileTestDefinition(r, got)
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+			Expect(cm.Data[types.OpenapiV3JSONSchema]).Should(ContainSubstring(`"$id":"https://schemas.example.com/deduplicated/v2"`))
+			Expect(got.Status.GetCondition(TypeSchemaDrift).Status).Should(Equal(corev1.ConditionUnknown))
+		}
+	})
+
+	It("Required schema", func() {
+		def := newTestDefinition("apply-object", `
+parameter: {
+	value: {
+		name:   string
+		labels?: [string]: string
+	}
+	cluster:    *"" | string
+	namespace?: string
+	ports: [...{
+		port:     int
+		protocol: *"TCP" | "UDP"
+	}]
+}
+`)
+		r := newTestReconciler(options{requiredSchema: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		schema := &openapi3.Schema{}
+		Expect(schema.UnmarshalJSON([]byte(cm.Data[requiredSchemaKey]))).Should(Succeed())
+		var params []string
+		for _, field := range flattenParameters(schema) {
+			params = append(params, field.Path)
+		}
+		Expect(params).Should(Equal([]string{"ports", "ports[].port", "value", "value.name"}))
+		Expect(schema.Required).Should(ConsistOf([]string{"ports", "value"}))
+	})
+
+	It("Config map prefix", func() {
+		ctx := context.Background()
+		def := newTestDefinition("prefixed", simpleTemplate)
+		r := newTestReconciler(options{configMapPrefix: "team-a-", requiredSchema: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(Equal("team-a-workflowstep-schema-prefixed"))
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		Expect(cm.Data).Should(HaveKey(requiredSchemaKey))
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: "team-a-workflowstep-schema-prefixed-v1"}, cm)).Should(Succeed())
+
+		// the ConfigMap of the previous prefix is cleaned up
+		r.configMapPrefix = "team-b-"
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapRef).Should(Equal("team-b-workflowstep-schema-prefixed"))
+		Expect(r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		err := r.Get(ctx, client.ObjectKey{Namespace: got.Namespace, Name: "team-a-workflowstep-schema-prefixed"}, cm)
+		Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+	})
+
+	It("Check name lengths", func() {
+		// the schema ConfigMap name of the revision overflows while the definition name is valid
+		def := newTestDefinition(strings.Repeat("a", 240), simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+		Expect(got.Status.LatestRevision).Should(BeNil())
+		cond := got.GetCondition(condition.TypeSynced)
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Message).Should(ContainSubstring("exceeds the limit of 253, shorten the name of WorkflowStepDefinition by"))
+
+		// the longest name the label values of the derived resources accept
+		def = newTestDefinition(strings.Repeat("a", 63), simpleTemplate)
+		r = newTestReconciler(options{}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+
+		// the prefix counts in the limit
+		r.configMapPrefix = strings.Repeat("p", 180) + "-"
+		Expect(r.checkNameLengths(got)).Should(HaveOccurred())
+	})
+})
+
+func TestValidateConfigMapPrefix(t *testing.T) {
+	require.NoError(t, validateConfigMapPrefix(""))
+	require.NoError(t, validateConfigMapPrefix("team-a-"))
+	require.Error(t, validateConfigMapPrefix("Team_A"))
+	require.Error(t, validateConfigMapPrefix(strings.Repeat("a", 200)))
+}
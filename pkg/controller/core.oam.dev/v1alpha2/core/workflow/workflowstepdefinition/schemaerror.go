@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model/value"
+
+	velacue "github.com/oam-dev/kubevela/pkg/cue"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+// maxSchemaErrorLength bounds the located schema error so that it fits in the message of the status condition
+const maxSchemaErrorLength = 512
+
+// locateSchemaError enriches the error rendering the parameter schema with the CUE paths and the template positions
+// of the failures, which the rendered error often lacks, e.g. `parameter.name: conflicting values string and 1 (line
+// 3, column 8)`. The template is evaluated on its own to locate the failures, the error is returned as is if none is
+// found.
+func locateSchemaError(template string, err error) error {
+	if err == nil {
+		return nil
+	}
+	// the syntax errors are located before the base template is appended, which is appended so that the positions in
+	// the template are kept
+	_, diagErr := parser.ParseFile("-", template)
+	var v *value.Value
+	if diagErr == nil {
+		v, diagErr = value.NewValue(template+"\n"+velacue.BaseTemplate, nil, "")
+	}
+	if diagErr == nil {
+		// the parameter section is what the schema is rendered from, it's missing if the template fails as a whole
+		if parameter := v.CueValue().LookupPath(cue.ParsePath(process.ParameterFieldName)); parameter.Exists() {
+			diagErr = parameter.Validate(cue.All())
+		} else {
+			diagErr = v.CueValue().Validate(cue.All())
+		}
+	}
+	var located cueerrors.Error
+	if !errors.As(diagErr, &located) {
+		return err
+	}
+	var messages []string
+	for _, e := range cueerrors.Errors(located) {
+		messages = append(messages, describeCUEError(e))
+	}
+	message := strings.Join(messages, "; ")
+	if runes := []rune(message); len(runes) > maxSchemaErrorLength {
+		message = string(runes[:maxSchemaErrorLength-3]) + "..."
+	}
+	return fmt.Errorf("invalid parameter schema: %s", message)
+}
+
+// describeCUEError formats the CUE error as its field path, message and the first position in the template
+func describeCUEError(e cueerrors.Error) string {
+	format, args := e.Msg()
+	message := fmt.Sprintf(format, args...)
+	if path := strings.Join(e.Path(), "."); path != "" {
+		message = path + ": " + message
+	}
+	for _, pos := range cueerrors.Positions(e) {
+		if pos.Line() > 0 {
+			return fmt.Sprintf("%s (line %d, column %d)", message, pos.Line(), pos.Column())
+		}
+	}
+	return message
+}
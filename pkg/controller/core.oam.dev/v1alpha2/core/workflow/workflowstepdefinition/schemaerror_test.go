@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+)
+
+func TestLocateSchemaError(t *testing.T) {
+	rendered := errors.New("template: explicit error (_|_ literal) in source")
+	testCases := map[string]struct {
+		template string
+		expected string
+	}{
+		"conflicting values": {
+			template: `
+parameter: {
+	name: string & 1
+}
+`,
+			expected: "invalid parameter schema: parameter.name: conflicting values string and 1 (mismatched types string and int) (line 3, column 8)",
+		},
+		"nested parameter": {
+			template: `
+import "vela/op"
+
+parameter: {
+	nested: {
+		replicas: int & "1"
+	}
+}
+apply: op.#Apply & {}
+`,
+			expected: `invalid parameter schema: parameter.nested.replicas: conflicting values int and "1" (mismatched types int and string) (line 6, column 13)`,
+		},
+		"reference not found": {
+			template: `
+parameter: {
+	image: unknown
+}
+`,
+			expected: `invalid parameter schema: parameter.image: reference "unknown" not found (line 3, column 9)`,
+		},
+		"syntax error": {
+			template: `
+parameter: {
+	name: string
+`,
+			expected: "invalid parameter schema: expected '}', found 'EOF' (line 3, column 15)",
+		},
+		"no failure located": {
+			template: simpleTemplate,
+			expected: rendered.Error(),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, locateSchemaError(tc.template, rendered), tc.expected)
+		})
+	}
+
+	// the message is bounded to fit in the status condition
+	var sb strings.Builder
+	sb.WriteString("parameter: {\n")
+	for i := 0; i < 50; i++ {
+		sb.WriteString("\tfield" + strings.Repeat("x", i) + ": string & 1\n")
+	}
+	sb.WriteString("}\n")
+	err := locateSchemaError(sb.String(), rendered)
+	require.True(t, strings.HasSuffix(err.Error(), "..."))
+	require.LessOrEqual(t, len(err.Error()), len("invalid parameter schema: ")+maxSchemaErrorLength)
+}
+
+var _ = Describe("Test WorkflowStepDefinition schema errors", func() {
+	It("Schema error condition", func() {
+		def := newTestDefinition("located", `
+parameter: {
+	name: string & 1
+}
+`)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		synced := got.Status.GetCondition(condition.TypeSynced)
+		Expect(synced.Reason).Should(Equal(condition.ReasonReconcileError))
+		Expect(synced.Message).Should(ContainSubstring("parameter.name: conflicting values string and 1 (mismatched types string and int) (line 3, column 8)"))
+	})
+})
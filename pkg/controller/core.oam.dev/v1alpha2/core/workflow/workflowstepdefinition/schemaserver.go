@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+const (
+	// schemaPathPrefix is the path prefix of the schemas served at /schemas/{namespace}/{name}/{revision|latest}
+	schemaPathPrefix = "/schemas/"
+	// latestSchemaRevision is the path segment of the schema of the latest revision
+	latestSchemaRevision = "latest"
+)
+
+// schemaHandler serves the stored schemas of the WorkflowStepDefinitions at deterministic paths. The ETag of a schema
+// is its fingerprint, so that the caches and the catalogs in front of it can revalidate it by a conditional GET
+type schemaHandler struct {
+	client          client.Reader
+	configMapPrefix string
+	cmNameTemplate  *template.Template
+	// schemaNamespace is the centralized namespace storing the schema ConfigMaps, empty means the namespace of the definition
+	schemaNamespace string
+}
+
+func (h *schemaHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, schemaPathPrefix), "/")
+	if !strings.HasPrefix(req.URL.Path, schemaPathPrefix) || len(segments) != 3 || segments[0] == "" || segments[1] == "" || segments[2] == "" {
+		http.NotFound(w, req)
+		return
+	}
+	namespace, name, revision := segments[0], segments[1], segments[2]
+	data, err := h.schema(req.Context(), namespace, name, revision)
+	if err != nil {
+		if apierrors.IsNotFound(err) || errors.Is(err, errSchemaNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		klog.ErrorS(err, "Could not serve the schema of WorkflowStepDefinition", "namespace", namespace, "name", name, "revision", revision)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	etag := `"` + schemaFingerprint([]byte(data)) + `"`
+	w.Header().Set("ETag", etag)
+	// the schema of a revision is rewritten by the reconciliation as well, e.g. once the schema id base url or the
+	// idempotency annotation changes, so the caches must revalidate it before reuse like the latest one
+	w.Header().Set("Cache-Control", "public, no-cache")
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodGet {
+		_, _ = w.Write([]byte(data))
+	}
+}
+
+var errSchemaNotFound = errors.New("schema not found")
+
+// schema returns the stored schema of the definition, the revision is either latest or the revision like v2 or 2
+func (h *schemaHandler) schema(ctx context.Context, namespace, name, revision string) (string, error) {
+	def := utils.CapabilityStepDefinition{}
+	def.ConfigMapNamePrefix = schemaConfigMapPrefix(h.configMapPrefix, h.schemaNamespace, namespace)
+	def.ConfigMapNameTemplate = h.cmNameTemplate
+	cmNamespace := namespace
+	if h.schemaNamespace != "" {
+		cmNamespace = h.schemaNamespace
+	}
+	var cmName string
+	if revision == latestSchemaRevision {
+		wfStepDefinition := &v1beta1.WorkflowStepDefinition{}
+		if err := h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, wfStepDefinition); err != nil {
+			return "", err
+		}
+		if cmName = wfStepDefinition.Status.ConfigMapRef; cmName == "" {
+			return "", errSchemaNotFound
+		}
+	} else {
+		var err error
+		revName := coredef.ConstructDefinitionRevisionName(name, strings.TrimPrefix(revision, "v"))
+		if cmName, err = def.RenderSchemaConfigMapName(namespace, name, revName); err != nil {
+			return "", errSchemaNotFound
+		}
+	}
+	cm := &corev1.ConfigMap{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: cmNamespace, Name: cmName}, cm); err != nil {
+		return "", err
+	}
+	data, ok := cm.Data[types.OpenapiV3JSONSchema]
+	if !ok {
+		return "", errSchemaNotFound
+	}
+	return data, nil
+}
+
+// etagMatches checks whether the If-None-Match header matches the ETag, the weak ETags are compared weakly
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaServer serves the schemas on every replica of the controller, it doesn't need the leader election
+type schemaServer struct {
+	addr    string
+	handler http.Handler
+}
+
+// Start runs the schema server until the context is done
+func (s *schemaServer) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(schemaPathPrefix, s.handler)
+	server := &http.Server{Addr: s.addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Could not shut down the schema server of WorkflowStepDefinition")
+		}
+	}()
+	klog.InfoS("Starting the schema server of WorkflowStepDefinition", "addr", s.addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface
+func (s *schemaServer) NeedLeaderElection() bool {
+	return false
+}
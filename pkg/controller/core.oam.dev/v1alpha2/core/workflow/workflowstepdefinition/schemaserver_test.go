@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema server", func() {
+	It("Schema handler", func() {
+		def := newTestDefinition("apply-config", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.SchemaHash).ShouldNot(BeEmpty())
+		h := &schemaHandler{client: r.Client}
+
+		serve := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			return w
+		}
+
+		for _, path := range []string{"/schemas/default/apply-config/latest", "/schemas/default/apply-config/v1", "/schemas/default/apply-config/1"} {
+			w := serve(path, "")
+			Expect(w.Code).Should(Equal(http.StatusOK), path)
+			etag := w.Header().Get("ETag")
+			Expect(etag).Should(Equal(`"`+got.Status.SchemaHash+`"`), path)
+			Expect(schemaFingerprint(w.Body.Bytes())).Should(Equal(got.Status.SchemaHash), path)
+
+			w = serve(path, etag)
+			Expect(w.Code).Should(Equal(http.StatusNotModified), path)
+			Expect(w.Body.Bytes()).Should(BeEmpty(), path)
+			Expect(w.Header().Get("ETag")).Should(Equal(etag), path)
+
+			w = serve(path, `"stale", W/`+etag)
+			Expect(w.Code).Should(Equal(http.StatusNotModified), path)
+
+			w = serve(path, `"stale"`)
+			Expect(w.Code).Should(Equal(http.StatusOK), path)
+		}
+		Expect(serve("/schemas/default/apply-config/latest", "").Header().Get("Cache-Control")).Should(Equal("public, no-cache"))
+		Expect(serve("/schemas/default/apply-config/v1", "").Header().Get("Cache-Control")).Should(Equal("public, no-cache"))
+
+		for _, path := range []string{"/schemas/default/apply-config/v2", "/schemas/default/unknown/latest", "/schemas/default/apply-config", "/schemas/default/apply-config/latest/extra"} {
+			Expect(serve(path, "").Code).Should(Equal(http.StatusNotFound), path)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/schemas/default/apply-config/latest", nil))
+		Expect(w.Code).Should(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("Schema handler centralized", func() {
+		def := newTestDefinition("apply-config", simpleTemplate)
+		r := newTestReconciler(options{schemaNamespace: "vela-system"}, def)
+		got := reconcileTestDefinition(r, def)
+		h := &schemaHandler{client: r.Client, schemaNamespace: "vela-system"}
+		for _, path := range []string{"/schemas/default/apply-config/latest", "/schemas/default/apply-config/v1"} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			Expect(w.Code).Should(Equal(http.StatusOK), path)
+			Expect(schemaFingerprint(w.Body.Bytes())).Should(Equal(got.Status.SchemaHash), path)
+		}
+	})
+})
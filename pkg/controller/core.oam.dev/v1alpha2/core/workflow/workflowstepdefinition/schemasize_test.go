@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+var _ = Describe("Test WorkflowStepDefinition schema size limit", func() {
+	It("Max schema size", func() {
+		ctx := context.Background()
+		def := newTestDefinition("oversized", simpleTemplate)
+		r := newTestReconciler(options{maxSchemaSize: 64}, def)
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)}
+
+		// the oversized schema fails the definition without writing any ConfigMap or requeuing
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeZero())
+		got := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).Should(Succeed())
+		c := got.Status.GetCondition(condition.TypeSynced)
+		Expect(c.Reason).Should(Equal(condition.ReasonReconcileError))
+		Expect(c.Message).Should(ContainSubstring("exceeds the limit of 64 bytes"))
+		cmList := &corev1.ConfigMapList{}
+		Expect(r.List(ctx, cmList, client.InNamespace("default"))).Should(Succeed())
+		Expect(cmList.Items).Should(BeEmpty())
+
+		// the raised limit lets the schema through
+		r.maxSchemaSize = utils.DefaultMaxSchemaSize
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.ConfigMapRef).Should(Equal("workflowstep-schema-oversized"))
+	})
+})
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+const (
+	// snapshotSpecKey is the data key of the definition spec in the snapshot ConfigMap
+	snapshotSpecKey = "spec.json"
+	// snapshotMetadataKey is the data key of the definition labels and annotations in the snapshot ConfigMap
+	snapshotMetadataKey = "metadata.json"
+	// snapshotSchemaKey is the data key of the parameter schema in the snapshot ConfigMap
+	snapshotSchemaKey = "schema.json"
+	// snapshotRevisionKey is the data key of the latest revision name in the snapshot ConfigMap
+	snapshotRevisionKey = "revision"
+	// snapshotFingerprintKey is the data key of the schema fingerprint in the snapshot ConfigMap
+	snapshotFingerprintKey = "fingerprint"
+)
+
+// snapshotConfigMapName returns the name of the snapshot ConfigMap of the definition
+func snapshotConfigMapName(def *v1beta1.WorkflowStepDefinition) string {
+	return fmt.Sprintf("workflowstep-snapshot-%s", def.Name)
+}
+
+// snapshotMetadata is the metadata of the definition captured in the snapshot
+type snapshotMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// storeSnapshot writes the consolidated snapshot of the definition, it's only updated when the content changes
+func (r *Reconciler) storeSnapshot(ctx context.Context, def *v1beta1.WorkflowStepDefinition, schemaData []byte) error {
+	spec, err := json.Marshal(def.Spec)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(snapshotMetadata{Labels: def.GetLabels(), Annotations: def.GetAnnotations()})
+	if err != nil {
+		return err
+	}
+	data := map[string]string{
+		snapshotSpecKey:        string(spec),
+		snapshotMetadataKey:    string(metadata),
+		snapshotSchemaKey:      string(schemaData),
+		snapshotFingerprintKey: schemaFingerprint(schemaData),
+	}
+	if def.Status.LatestRevision != nil {
+		data[snapshotRevisionKey] = def.Status.LatestRevision.Name
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: snapshotConfigMapName(def), Namespace: def.Namespace}}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if !apiequality.Semantic.DeepEqual(cm.Data, data) {
+			cm.Data = data
+		}
+		return controllerutil.SetControllerReference(def, cm, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+	if result != controllerutil.OperationResultNone {
+		reconcileLogger(ctx).Info("Successfully stored the snapshot of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(def), "configMap", klog.KObj(cm), "operation", result)
+	}
+	return nil
+}
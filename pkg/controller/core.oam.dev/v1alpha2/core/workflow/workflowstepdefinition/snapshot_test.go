@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+var _ = Describe("Test WorkflowStepDefinition snapshot", func() {
+	It("Snapshot", func() {
+		ctx := context.Background()
+		def := newTestDefinition("apply-object", simpleTemplate)
+		def.SetAnnotations(map[string]string{types.AnnoDefinitionDescription: "Apply raw kubernetes objects"})
+		r := newTestReconciler(options{snapshot: true}, def)
+
+		assertSnapshot := func(def *v1beta1.WorkflowStepDefinition) {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: "workflowstep-snapshot-apply-object"}, cm)).Should(Succeed())
+			Expect(cm.OwnerReferences).Should(HaveLen(1))
+			Expect(cm.OwnerReferences[0].UID).Should(Equal(def.UID))
+
+			spec := v1beta1.WorkflowStepDefinitionSpec{}
+			Expect(json.Unmarshal([]byte(cm.Data[snapshotSpecKey]), &spec)).Should(Succeed())
+			Expect(spec).Should(Equal(def.Spec))
+			metadata := snapshotMetadata{}
+			Expect(json.Unmarshal([]byte(cm.Data[snapshotMetadataKey]), &metadata)).Should(Succeed())
+			Expect(metadata.Annotations[types.AnnoDefinitionDescription]).Should(Equal("Apply raw kubernetes objects"))
+			Expect(cm.Data[snapshotRevisionKey]).Should(Equal(def.Status.LatestRevision.Name))
+			Expect(cm.Data[snapshotFingerprintKey]).Should(Equal(def.Status.SchemaHash))
+			schemaCM := &corev1.ConfigMap{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: def.Status.ConfigMapRef}, schemaCM)).Should(Succeed())
+			Expect(cm.Data[snapshotSchemaKey]).Should(Equal(schemaCM.Data[types.OpenapiV3JSONSchema]))
+		}
+
+		got := reconcileTestDefinition(r, def)
+		assertSnapshot(got)
+
+		got.Spec.Schematic.CUE.Template = `
+parameter: {
+	name:     string
+	replicas: *1 | int
+}
+`
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.LatestRevision.Name).Should(Equal("apply-object-v2"))
+		assertSnapshot(got)
+	})
+})
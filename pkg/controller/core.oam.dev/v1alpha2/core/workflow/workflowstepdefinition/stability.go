@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// stabilityLevels are the stability levels the definition can declare
+var stabilityLevels = sets.NewString(oam.StabilityAlpha, oam.StabilityBeta, oam.StabilityStable)
+
+// declaredStability returns the stability level declared by the annotation of the definition, it's empty if not declared
+func declaredStability(def *v1beta1.WorkflowStepDefinition) string {
+	return strings.ToLower(strings.TrimSpace(def.GetAnnotations()[oam.AnnotationStability]))
+}
+
+// lintStability flags the definition declaring a stability level not allowed
+func lintStability(lctx *lintContext) []lintFinding {
+	level := declaredStability(lctx.def)
+	if level == "" || stabilityLevels.Has(level) {
+		return nil
+	}
+	return []lintFinding{{
+		Rule:     "stability",
+		Severity: lintSeverityWarning,
+		Message: fmt.Sprintf("the stability %q declared by the annotation %s is not one of %s",
+			level, oam.AnnotationStability, strings.Join(stabilityLevels.List(), ", ")),
+	}}
+}
+
+// reconcileStability surfaces the valid stability level of the definition in the status and the label, so the catalogs
+// can select the definitions by it
+func (r *Reconciler) reconcileStability(ctx context.Context, def *v1beta1.WorkflowStepDefinition, status *v1beta1.WorkflowStepDefinitionStatus) error {
+	status.Stability = ""
+	if level := declaredStability(def); stabilityLevels.Has(level) {
+		status.Stability = level
+		return r.patchLabels(ctx, def, map[string]string{oam.LabelWorkflowStepDefinitionStability: level})
+	}
+	if _, ok := def.GetLabels()[oam.LabelWorkflowStepDefinitionStability]; !ok {
+		return nil
+	}
+	patch := client.MergeFrom(def.DeepCopy())
+	delete(def.Labels, oam.LabelWorkflowStepDefinitionStability)
+	return r.Patch(ctx, def, patch)
+}
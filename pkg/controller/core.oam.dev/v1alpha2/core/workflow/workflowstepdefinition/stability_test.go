@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition stability", func() {
+	It("Stability", func() {
+		ctx := context.Background()
+		def := newTestDefinition("apply-job", simpleTemplate)
+		def.SetAnnotations(map[string]string{oam.AnnotationStability: " Beta "})
+		r := newTestReconciler(options{}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.Stability).Should(Equal(oam.StabilityBeta))
+		Expect(got.Labels[oam.LabelWorkflowStepDefinitionStability]).Should(Equal(oam.StabilityBeta))
+		Expect(got.Status.Warnings).Should(BeEmpty())
+
+		// the invalid stability is warned and not surfaced
+		got.Annotations[oam.AnnotationStability] = "experimental"
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		got = reconcileTestDefinition(r, got)
+		Expect(got.Status.Stability).Should(BeEmpty())
+		Expect(got.Labels).ShouldNot(HaveKey(oam.LabelWorkflowStepDefinitionStability))
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			`[stability] the stability "experimental" declared by the annotation ` + oam.AnnotationStability + ` is not one of alpha, beta, stable`,
+		}))
+	})
+})
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+var _ = Describe("Test WorkflowStepDefinition status update", func() {
+	It("Status retry backoff", func() {
+		// the zero value falls back to the client-go default
+		r := newTestReconciler(options{})
+		Expect(r.statusRetryBackoff()).Should(Equal(retry.DefaultBackoff))
+
+		backoff := wait.Backoff{Steps: 10, Duration: 20 * time.Millisecond, Factor: 2, Jitter: 0.5}
+		r = newTestReconciler(options{statusBackoff: backoff})
+		Expect(r.statusRetryBackoff()).Should(Equal(backoff))
+
+		def := newTestDefinition("status-backoff", simpleTemplate)
+		r = newTestReconciler(options{statusBackoff: backoff}, def)
+		def.Status.ConfigMapRef = "workflowstep-schema-status-backoff"
+		Expect(r.UpdateStatus(context.Background(), def)).Should(Succeed())
+		got := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(context.Background(), client.ObjectKeyFromObject(def), got)).Should(Succeed())
+		Expect(got.Status.ConfigMapRef).Should(Equal("workflowstep-schema-status-backoff"))
+	})
+})
@@ -30,6 +30,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
@@ -74,10 +75,13 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(k8sClient).ToNot(BeNil())
 
 	By("Starting the controller in the background")
+	// the controller only watches the namespaces of the specs reconciling by it, the others reconcile by their own
+	// reconcilers configured with the options under test
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             scheme.Scheme,
 		MetricsBindAddress: "0",
 		Port:               48081,
+		NewCache:           cache.MultiNamespacedCacheBuilder([]string{"ns-wfs-def-1", "ns-wfs-def2"}),
 	})
 	Expect(err).ToNot(HaveOccurred())
 
@@ -105,6 +109,10 @@ var _ = BeforeSuite(func(done Done) {
 	close(done)
 }, 120)
 
+var _ = AfterEach(func() {
+	cleanupTestNamespaces()
+})
+
 var _ = AfterSuite(func() {
 	By("Stop the controller")
 	controllerDone()
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	monitorContext "github.com/kubevela/pkg/monitor/context"
+	"github.com/kubevela/pkg/util/rand"
+	"k8s.io/klog/v2"
+)
+
+// the structured fields tagging the log lines and the events of a reconciliation, the spanID is the reconcile ID named
+// after the field the trace context logs it by
+const (
+	traceFieldReconcileID       = "spanID"
+	traceFieldControllerVersion = "controllerVersion"
+	traceFieldRevision          = "definitionRevision"
+)
+
+type reconcileTraceKey struct{}
+
+// reconcileTrace correlates the log lines and the events of a reconciliation, so that one reconciliation can be told
+// apart from the interleaved ones of the concurrent workers
+type reconcileTrace struct {
+	logger monitorContext.Context
+	// annotations are the fields set on the events in the order of keys and values
+	annotations []string
+}
+
+// withReconcileTrace returns the context carrying the trace of the reconciliation with a new reconcile ID, the contextual
+// klog logger is tagged as well for the shared helpers logging by klog.FromContext
+func withReconcileTrace(ctx context.Context, controllerVersion string) (context.Context, *reconcileTrace) {
+	id := "r-" + rand.RandomString(8)
+	trace := &reconcileTrace{
+		logger:      monitorContext.NewTraceContext(ctx, id).AddTag(traceFieldControllerVersion, controllerVersion),
+		annotations: []string{traceFieldReconcileID, id, traceFieldControllerVersion, controllerVersion},
+	}
+	ctx = klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx),
+		traceFieldReconcileID, id, traceFieldControllerVersion, controllerVersion))
+	return context.WithValue(ctx, reconcileTraceKey{}, trace), trace
+}
+
+// tagRevision tags the following log lines and events of the reconciliation with the DefinitionRevision
+func tagRevision(ctx context.Context, revision string) {
+	if trace, ok := ctx.Value(reconcileTraceKey{}).(*reconcileTrace); ok {
+		trace.logger.AddTag(traceFieldRevision, revision)
+		trace.annotations = append(trace.annotations, traceFieldRevision, revision)
+	}
+}
+
+// reconcileLogger returns the logger tagged with the trace of the reconciliation in the context, it logs by a new
+// reconcile ID out of the reconciliations
+func reconcileLogger(ctx context.Context) monitorContext.Logger {
+	if trace, ok := ctx.Value(reconcileTraceKey{}).(*reconcileTrace); ok {
+		return trace.logger
+	}
+	return monitorContext.NewTraceContext(ctx, "")
+}
+
+// recorder returns the event recorder annotating the events with the trace of the reconciliation in the context
+func (r *Reconciler) recorder(ctx context.Context) event.Recorder {
+	if trace, ok := ctx.Value(reconcileTraceKey{}).(*reconcileTrace); ok {
+		return r.record.WithAnnotations(trace.annotations...)
+	}
+	return r.record
+}
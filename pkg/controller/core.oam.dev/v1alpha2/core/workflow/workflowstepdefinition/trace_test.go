@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test WorkflowStepDefinition reconcile trace", func() {
+	It("Reconcile trace", func() {
+		ctx := context.Background()
+		def := newTestDefinition("suspend", simpleTemplate)
+		r := newTestReconciler(options{reservedNames: sets.NewString(DefaultReservedStepNames...), controllerVersion: "v1.6.0"}, def)
+		recorder := &recordingRecorder{}
+		r.record = recorder
+		got := reconcileTestDefinition(r, def)
+		// the lint warning and the ready event
+		Expect(recorder.events).Should(HaveLen(2))
+		id := recorder.events[0].Annotations[traceFieldReconcileID]
+		Expect(id).ShouldNot(BeEmpty())
+		for _, e := range recorder.events {
+			Expect(e.Annotations[traceFieldReconcileID]).Should(Equal(id))
+			Expect(e.Annotations[traceFieldControllerVersion]).Should(Equal("v1.6.0"))
+			Expect(e.Annotations[traceFieldRevision]).Should(Equal("suspend-v1"))
+		}
+
+		// the deprecation is refused before the revision is matched
+		recorder.events = nil
+		got.SetAnnotations(map[string]string{oam.AnnotationDeprecated: "true"})
+		got.Spec.Schematic.CUE.Template = "parameter: {\n\tname: string\n\tage: int\n}\n"
+		Expect(r.Update(ctx, got)).Should(Succeed())
+		reconcileTestDefinition(r, got)
+		Expect(recorder.events).Should(HaveLen(1))
+		e := recorder.events[0]
+		Expect(e.Annotations[traceFieldReconcileID]).ShouldNot(BeEmpty())
+		Expect(e.Annotations[traceFieldReconcileID]).ShouldNot(Equal(id))
+		Expect(e.Annotations[traceFieldControllerVersion]).Should(Equal("v1.6.0"))
+		Expect(e.Annotations).ShouldNot(HaveKey(traceFieldRevision))
+	})
+})
+
+func TestReconcileTraceContextLogger(t *testing.T) {
+	var lines []string
+	ctx := logr.NewContext(context.Background(), funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{}))
+	ctx, _ = withReconcileTrace(ctx, "v1.6.0")
+	// the shared helpers logging by the contextual logger carry the trace as well
+	klog.FromContext(ctx).Info("Successfully stored Capability Schema in ConfigMap")
+	require.Len(t, lines, 1)
+	require.Regexp(t, `"spanID"="r-[a-z0-9]{8}" "controllerVersion"="v1.6.0"`, lines[0])
+}
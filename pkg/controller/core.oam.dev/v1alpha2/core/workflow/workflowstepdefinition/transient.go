@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// storeRetryBaseDelay and storeRetryMaxDelay bound the exponential backoff requeuing the definition whose schema
+	// failed to be stored by a transient error
+	storeRetryBaseDelay = time.Second
+	storeRetryMaxDelay  = 5 * time.Minute
+)
+
+// storeRetries tracks the consecutive transient failures storing the schema of each definition. The failed
+// reconciliation is requeued by itself, since no watch event may come to trigger it again.
+type storeRetries struct {
+	once    sync.Once
+	limiter workqueue.RateLimiter
+}
+
+func (s *storeRetries) rateLimiter() workqueue.RateLimiter {
+	s.once.Do(func() {
+		s.limiter = workqueue.NewItemExponentialFailureRateLimiter(storeRetryBaseDelay, storeRetryMaxDelay)
+	})
+	return s.limiter
+}
+
+// when records a failure of the definition and returns the delay to requeue it
+func (s *storeRetries) when(key types.NamespacedName) time.Duration {
+	return s.rateLimiter().When(key)
+}
+
+// forget resets the backoff of the definition once its schema is stored or it's gone
+func (s *storeRetries) forget(key types.NamespacedName) {
+	s.rateLimiter().Forget(key)
+}
+
+// isTransientError checks whether the error is likely to go away by retrying, e.g. a conflict, an overloaded or
+// unreachable API server, rather than an invalid schema which waits for the spec fix
+func isTransientError(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case apierrors.IsConflict(err), apierrors.IsServerTimeout(err), apierrors.IsTimeout(err), apierrors.IsTooManyRequests(err),
+		apierrors.IsServiceUnavailable(err), apierrors.IsInternalError(err), apierrors.IsUnexpectedServerError(err):
+		return true
+	case errors.Is(err, context.DeadlineExceeded), utilnet.IsConnectionReset(err), utilnet.IsConnectionRefused(err), utilnet.IsProbableEOF(err):
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
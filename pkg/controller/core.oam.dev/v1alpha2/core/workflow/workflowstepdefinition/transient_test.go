@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// flakyConfigMapClient fails the first writes of the ConfigMaps with the error
+type flakyConfigMapClient struct {
+	client.Client
+	failures int
+	err      error
+}
+
+func (c *flakyConfigMapClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*corev1.ConfigMap); ok && c.failures > 0 {
+		c.failures--
+		return c.err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("Test WorkflowStepDefinition transient failures", func() {
+	It("Requeue transient store failure", func() {
+		ctx := context.Background()
+		def := newTestDefinition("flaky", simpleTemplate)
+		r := newTestReconciler(options{}, def)
+		r.Client = &flakyConfigMapClient{Client: r.Client, failures: 3,
+			err: apierrors.NewServiceUnavailable("the server is currently unable to handle the request")}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)}
+
+		// the transient failures are requeued with the exponential backoff
+		for i := 0; i < 3; i++ {
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(Equal(storeRetryBaseDelay << i))
+		}
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeZero())
+		got := &v1beta1.WorkflowStepDefinition{}
+		Expect(r.Get(ctx, req.NamespacedName, got)).Should(Succeed())
+		Expect(got.Status.ConfigMapRef).Should(Equal("workflowstep-schema-flaky"))
+
+		// the backoff starts over after the success
+		Expect(r.retries.when(req.NamespacedName)).Should(Equal(storeRetryBaseDelay))
+	})
+
+	It("No requeue permanent store failure", func() {
+		ctx := context.Background()
+		def := newTestDefinition("broken", "parameter: {\n\tname: string\n")
+		r := newTestReconciler(options{}, def)
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(def)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeZero())
+	})
+})
+
+func TestIsTransientError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "configmaps"}
+	for _, c := range []struct {
+		err       error
+		transient bool
+	}{
+		{err: apierrors.NewConflict(gr, "cm", errors.New("modified")), transient: true},
+		{err: apierrors.NewServerTimeout(gr, "create", 1), transient: true},
+		{err: apierrors.NewTooManyRequests("slow down", 1), transient: true},
+		{err: fmt.Errorf("cannot store: %w", apierrors.NewInternalError(errors.New("etcd"))), transient: true},
+		{err: context.DeadlineExceeded, transient: true},
+		{err: apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "cm", nil), transient: false},
+		{err: apierrors.NewForbidden(gr, "cm", errors.New("denied")), transient: false},
+		{err: errors.New("failed to generate OpenAPI v3 JSON schema"), transient: false},
+		{err: nil, transient: false},
+	} {
+		require.Equal(t, c.transient, isTransientError(c.err), "%v", c.err)
+	}
+}
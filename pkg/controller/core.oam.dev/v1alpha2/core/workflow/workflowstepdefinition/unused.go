@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"sort"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+// lintUnusedParameters flags the top-level parameters never referenced by the template body, which are warned or
+// rejected by the options
+func (r *Reconciler) lintUnusedParameters(lctx *lintContext) []lintFinding {
+	schematic := lctx.def.Spec.Schematic
+	if lctx.schema == nil || len(lctx.schema.Properties) == 0 || schematic == nil || schematic.CUE == nil {
+		return nil
+	}
+	referenced, all, err := referencedParameters(schematic.CUE.Template)
+	if err != nil || all {
+		// the template error is left to the schema rendering to report, and the parameter referenced as a whole
+		// uses every field of it
+		return nil
+	}
+	severity := lintSeverityWarning
+	if r.rejectUnusedParams {
+		severity = lintSeverityError
+	}
+	names := make([]string, 0, len(lctx.schema.Properties))
+	for name := range lctx.schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var findings []lintFinding
+	for _, name := range names {
+		if referenced[name] {
+			continue
+		}
+		findings = append(findings, lintFinding{
+			Rule:     "unused-parameter",
+			Severity: severity,
+			Path:     name,
+			Message:  "the parameter is declared but never referenced in the template, use it or remove it",
+		})
+	}
+	return findings
+}
+
+// referencedParameters returns the top-level parameters referenced outside the parameter declaration, e.g. by
+// parameter.name or parameter["name"]. all is true if the parameter is referenced as a whole, e.g. by `spec: parameter`
+func referencedParameters(template string) (referenced map[string]bool, all bool, err error) {
+	f, err := parser.ParseFile("-", template)
+	if err != nil {
+		return nil, false, err
+	}
+	referenced = map[string]bool{}
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Field:
+			// the labels are not references, only the values are visited
+			ast.Walk(x.Value, visit, nil)
+			return false
+		case *ast.SelectorExpr:
+			if isParameterIdent(x.X) {
+				if name, _, err := ast.LabelName(x.Sel); err == nil {
+					referenced[name] = true
+				}
+				return false
+			}
+		case *ast.IndexExpr:
+			if isParameterIdent(x.X) {
+				if lit, ok := x.Index.(*ast.BasicLit); ok {
+					if name, _, err := ast.LabelName(lit); err == nil {
+						referenced[name] = true
+						return false
+					}
+				}
+				all = true
+				return false
+			}
+		case *ast.Ident:
+			if x.Name == process.ParameterFieldName {
+				all = true
+			}
+		}
+		return true
+	}
+	for _, decl := range f.Decls {
+		if field, ok := decl.(*ast.Field); ok {
+			if name, _, err := ast.LabelName(field.Label); err == nil && name == process.ParameterFieldName {
+				continue
+			}
+		}
+		ast.Walk(decl, visit, nil)
+	}
+	return referenced, all, nil
+}
+
+func isParameterIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == process.ParameterFieldName
+}
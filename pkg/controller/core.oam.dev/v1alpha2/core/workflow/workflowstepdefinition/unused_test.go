@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+)
+
+const unusedParamsTemplate = `
+import (
+	"vela/op"
+)
+
+parameter: {
+	name:      string
+	namespace: *"default" | string
+	labels?: [string]: string
+	replicas:  *1 | int
+	debug:     *false | bool
+}
+
+apply: op.#Apply & {
+	value: {
+		metadata: {
+			name:      parameter.name
+			namespace: parameter["namespace"]
+			if parameter.labels != _|_ {
+				labels: parameter.labels
+			}
+		}
+	}
+}
+`
+
+var _ = Describe("Test unused WorkflowStepDefinition", func() {
+	It("Lint unused parameters", func() {
+		def := newTestDefinition("unused", unusedParamsTemplate)
+		r := newTestReconciler(options{checkUnusedParams: true}, def)
+		got := reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		Expect(got.Status.Warnings).Should(Equal([]string{
+			"[unused-parameter] debug: the parameter is declared but never referenced in the template, use it or remove it",
+			"[unused-parameter] replicas: the parameter is declared but never referenced in the template, use it or remove it",
+		}))
+
+		def = newTestDefinition("unused-rejected", unusedParamsTemplate)
+		r = newTestReconciler(options{rejectUnusedParams: true}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).Should(BeEmpty())
+		cond := got.GetCondition(condition.TypeSynced)
+		Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+		Expect(cond.Message).Should(ContainSubstring("[unused-parameter] debug"))
+		Expect(cond.Message).Should(ContainSubstring("[unused-parameter] replicas"))
+		Expect(cond.Message).ShouldNot(ContainSubstring("[unused-parameter] name"))
+
+		def = newTestDefinition("unused-unchecked", unusedParamsTemplate)
+		r = newTestReconciler(options{}, def)
+		got = reconcileTestDefinition(r, def)
+		Expect(got.Status.ConfigMapRef).ShouldNot(BeEmpty())
+		Expect(got.Status.Warnings).Should(BeEmpty())
+	})
+})
+
+func TestReferencedParameters(t *testing.T) {
+	referenced, all, err := referencedParameters(unusedParamsTemplate)
+	require.NoError(t, err)
+	require.False(t, all)
+	require.Equal(t, map[string]bool{"name": true, "namespace": true, "labels": true}, referenced)
+
+	// the parameter referenced as a whole uses every field of it
+	_, all, err = referencedParameters(`
+parameter: {
+	name: string
+}
+apply: value: parameter
+`)
+	require.NoError(t, err)
+	require.True(t, all)
+
+	// the parameters referencing each other in the declaration are not used by the template
+	referenced, all, err = referencedParameters(`
+parameter: {
+	name: string
+	alias: *parameter.name | string
+}
+apply: value: {}
+`)
+	require.NoError(t, err)
+	require.False(t, all)
+	require.Empty(t, referenced)
+}
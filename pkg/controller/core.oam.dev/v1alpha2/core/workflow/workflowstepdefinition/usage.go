@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// usageSnippetKey is the data key of the CLI usage snippet in the schema ConfigMap
+const usageSnippetKey = "usage.txt"
+
+// generateUsageSnippet generates a copy-paste snippet showing how to use the step, the required parameters
+// which have no default value are filled with placeholders
+func generateUsageSnippet(actx *artifactContext) (string, error) {
+	name := actx.def.Name
+	var sb strings.Builder
+	sb.WriteString("# Show the parameters of the workflow step\n")
+	sb.WriteString(fmt.Sprintf("vela show %s\n", name))
+	sb.WriteString("# Use the workflow step in the workflow of an application and apply it by `vela up -f app.yaml`\n")
+	sb.WriteString("workflow:\n")
+	sb.WriteString("  steps:\n")
+	sb.WriteString(fmt.Sprintf("    - name: %s\n", name))
+	sb.WriteString(fmt.Sprintf("      type: %s\n", name))
+	var required []string
+	for _, field := range flattenParameters(actx.schema) {
+		if field.Depth == 1 && isRequiredParameter(field) {
+			required = append(required, field.Path)
+		}
+	}
+	if len(required) > 0 {
+		sb.WriteString("      properties:\n")
+		for _, param := range required {
+			sb.WriteString(fmt.Sprintf("        %s: <%s>\n", param, param))
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Test WorkflowStepDefinition usage snippet", func() {
+	It("Usage snippet", func() {
+		def := newTestDefinition("apply-object", `
+parameter: {
+	// +usage=Specify the value of the object
+	value: {...}
+	// +usage=Specify the cluster of the object
+	cluster: *"" | string
+	namespace?: string
+}
+`)
+		r := newTestReconciler(options{usageSnippet: true}, def)
+		got := reconcileTestDefinition(r, def)
+
+		cm := &corev1.ConfigMap{}
+		Expect(r.Get(context.Background(), client.ObjectKey{Namespace: got.Namespace, Name: got.Status.ConfigMapRef}, cm)).Should(Succeed())
+		snippet := cm.Data[usageSnippetKey]
+		Expect(snippet).Should(ContainSubstring("vela show apply-object"))
+		Expect(snippet).Should(ContainSubstring("type: apply-object"))
+		Expect(snippet).Should(ContainSubstring("value: <value>"))
+		Expect(snippet).ShouldNot(ContainSubstring("cluster:"))
+		Expect(snippet).ShouldNot(ContainSubstring("namespace:"))
+	})
+})
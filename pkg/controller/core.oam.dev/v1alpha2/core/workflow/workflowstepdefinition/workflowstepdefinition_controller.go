@@ -20,15 +20,29 @@ package workflowstepdefinition
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
@@ -37,6 +51,7 @@ import (
 	oamctrl "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	coredef "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/version"
@@ -49,6 +64,22 @@ type Reconciler struct {
 	Scheme *runtime.Scheme
 	record event.Recorder
 	options
+	// indexedFingerprints records the fingerprints of the catalog entries of the definitions indexed in the catalog
+	indexedFingerprints sync.Map
+	// reportedDuplicates records the identical definitions last reported of each definition
+	reportedDuplicates sync.Map
+	// revisionParams caches the parameters of the retained revisions for the parameter lifecycle
+	revisionParams revisionParamsCache
+	// dependencies indexes the composite definitions by the base definitions they embed
+	dependencies dependencyIndex
+	// locks keeps the concurrent workers from reconciling the same definition at a time
+	locks keyedMutex
+	// ready tracks the readiness reported by the readyz check
+	ready readiness
+	// dmRefresher coalesces the concurrent refreshes of the DiscoveryMapper
+	dmRefresher discoveryRefresher
+	// retries backs off requeuing the definitions whose schemas failed to be stored by the transient errors
+	retries storeRetries
 }
 
 type options struct {
@@ -56,74 +87,522 @@ type options struct {
 	concurrentReconciles int
 	ignoreDefNoCtrlReq   bool
 	controllerVersion    string
+	reconcileTimeout     time.Duration
+	statusBackoff        wait.Backoff
+	complexityScore      bool
+	// schemaNotifyConfigMap is the name of the ConfigMap in the system definition namespace bumped on schema changes
+	schemaNotifyConfigMap string
+	// reservedNames are the built-in step types the definition name must not shadow, empty means no check
+	reservedNames       sets.String
+	rejectReservedNames bool
+	usageSnippet        bool
+	strictCUE           bool
+	paramLifecycle      bool
+	requiredAnnotations []string
+	// schemaIDBaseURL is the base URL of the `$id` populated in the stored schema, empty means no `$id`
+	schemaIDBaseURL string
+	// detectDuplicates reports the identical definitions across namespaces, aliasDuplicates further points them to the canonical one
+	detectDuplicates bool
+	aliasDuplicates  bool
+	// checkParameterBounds flags the string parameters without maxLength and the number parameters without bounds
+	checkParameterBounds bool
+	catalogIndexer       oamctrl.CatalogIndexer
+	// maxDescriptionLength is the max length of the parameter descriptions, 0 means no limit
+	maxDescriptionLength int
+	checkEnumDefaults    bool
+	requiredSchema       bool
+	requireDescription   bool
+	rejectNoDescription  bool
+	checkNameCollisions  bool
+	asyncAPI             bool
+	snapshot             bool
+	// maxGroupParameters is the max count of the parameters in a group declared by @group attribute, 0 means no limit
+	maxGroupParameters int
+	auditSchemaChanges bool
+	checkArrayItems    bool
+	phaseDurations     bool
+	migrationNote      bool
+	paramDependencies  bool
+	configMapPrefix    string
+	checkIntegerTypes  bool
+	schemaEventTarget  string
+	fuzzCorpus         bool
+	checkReferences    bool
+	checkTraitOverlap  bool
+	provenance         bool
+	exampleApp         bool
+	checkIdempotency   bool
+	normalizeDefaults  bool
+	// maxEnumValues is the max count of the enum values of a parameter, 0 means no limit
+	maxEnumValues  int
+	checksums      bool
+	checkResources bool
+	graphQL        bool
+	// forbiddenPatterns are the patterns the descriptions must not match, empty means no check
+	forbiddenPatterns []*regexp.Regexp
+	// schemaProfiles are the consumer profiles the schema variants are generated for unless the definition requests its own
+	schemaProfiles []string
+	firstSeen      bool
+	checkExamples  bool
+	checkDurations bool
+	mockOutput     bool
+	// envMapConvention is the naming convention of the env vars mapped from the parameters, empty means disabled
+	envMapConvention string
+	// exportConfigMap and exportDir are where the definition manifests are exported to, empty means disabled
+	exportConfigMap string
+	exportDir       string
+	// holdBreakingChanges holds the revisions with breaking changes from becoming the latest until they're approved
+	holdBreakingChanges bool
+	checkSecretDefaults bool
+	// checkUnusedParams warns on the parameters never referenced by the template, rejectUnusedParams rejects them
+	checkUnusedParams  bool
+	rejectUnusedParams bool
+	// schemaServerAddr is the address serving the schemas at /schemas/{namespace}/{name}/{revision|latest}, empty means disabled
+	schemaServerAddr string
+	// propagatedMetadata are the label and annotation keys, or the prefixes ending with `/`, copied onto the schema ConfigMap
+	propagatedMetadata []string
+	// enforceAdditiveOnly rejects the spec changes removing or narrowing the parameters of the additive-only definitions
+	enforceAdditiveOnly bool
+	// booleanTrueKeywords and booleanFalseKeywords clarify the states of the boolean parameters, empty means no check
+	booleanTrueKeywords  []string
+	booleanFalseKeywords []string
+	// sweepOrphanRevs reclaims the DefinitionRevisions beyond the revision limit whose definitions are gone or no longer own them
+	sweepOrphanRevs bool
+	// dryRun reports the changes the reconciliation would make without writing them
+	dryRun bool
+	// schemaNamespace is the centralized namespace storing the schema ConfigMaps, empty means the namespace of the definition
+	schemaNamespace string
+	// immutableSchemas stores the schemas in the immutable ConfigMaps instead of updating them in place
+	immutableSchemas bool
+	// maxSchemaSize is the max size in bytes of the schema stored in the ConfigMap, 0 means no limit
+	maxSchemaSize int
+	// cmNameTemplate renders the names of the schema ConfigMaps, nil means the default names
+	cmNameTemplate *template.Template
+	// dmRefreshInterval is the min interval between the DiscoveryMapper refreshes, 0 means no limit
+	dmRefreshInterval time.Duration
+	// disableRevisions keeps the revisions in the status only without persisting the DefinitionRevisions
+	disableRevisions bool
 }
 
 // Reconcile is the main logic for WorkflowStepDefinition controller
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	ctx, cancel := common2.NewReconcileContext(ctx)
+	begin := time.Now()
+	ctx, outcome := withReconcileOutcome(ctx)
+	ctx, _ = withReconcileTrace(ctx, r.controllerVersion)
+	result, err := r.reconcile(ctx, req)
+	if isNoKindMatch(err) {
+		result, err = r.requeueNoKindMatch(ctx, err), nil
+	}
+	if err != nil {
+		outcome.result = reconcileResultError
+	}
+	reconcileTotalCounter.WithLabelValues(outcome.result).Inc()
+	reconcileDurationHistogram.WithLabelValues(outcome.result).Observe(time.Since(begin).Seconds())
+	return result, err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, cancel := common2.NewReconcileContextWithTimeout(ctx, r.reconcileTimeout)
 	defer cancel()
+	unlock, lockErr := r.locks.lock(ctx, req.NamespacedName)
+	if lockErr != nil {
+		return ctrl.Result{}, lockErr
+	}
+	defer unlock()
 
 	definitionName := req.NamespacedName.Name
-	klog.InfoS("Reconciling WorkflowStepDefinition...", "Name", definitionName, "Namespace", req.Namespace)
+	reconcileLogger(ctx).Info("Reconciling WorkflowStepDefinition...", "Name", definitionName, "Namespace", req.Namespace)
 
 	var wfStepDefinition v1beta1.WorkflowStepDefinition
 	if err := r.Get(ctx, req.NamespacedName, &wfStepDefinition); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.retries.forget(req.NamespacedName)
+			r.reportedDuplicates.Delete(req.NamespacedName)
+			r.indexedFingerprints.Delete(req.NamespacedName)
+			r.revisionParams.forget(req.NamespacedName)
+			r.dependencies.update(req.NamespacedName, nil)
+			definitionRevisionsGauge.DeleteLabelValues(req.Namespace, req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	r.dependencies.update(req.NamespacedName, embeddedDefinitions(&wfStepDefinition))
 
-	// this is a placeholder for finalizer here in the future
 	if wfStepDefinition.DeletionTimestamp != nil {
+		if err := r.finalize(ctx, &wfStepDefinition); err != nil {
+			reconcileLogger(ctx).Error(err, "Could not clean up the resources of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(&wfStepDefinition))
+			r.recorder(ctx).Event(&wfStepDefinition, event.Warning("Could not clean up the resources of WorkflowStepDefinition", err))
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
+	if wfStepDefinition.GetAnnotations()[oam.AnnotationDisableReconcile] == "true" {
+		reconcileLogger(ctx).Info("skip definition: the reconciliation is paused", "workflowStepDefinition", klog.KObj(&wfStepDefinition))
+		r.recorder(ctx).Event(&wfStepDefinition, event.Normal("WorkflowStepDefinition reconciliation paused",
+			fmt.Sprintf("The reconciliation is paused by the annotation %s", oam.AnnotationDisableReconcile)))
+		setReconcileResult(ctx, reconcileResultSkipped)
+		return ctrl.Result{}, nil
+	}
 	if !coredef.MatchControllerRequirement(&wfStepDefinition, r.controllerVersion, r.ignoreDefNoCtrlReq) {
-		klog.InfoS("skip definition: not match the controller requirement of definition", "workflowStepDefinition", klog.KObj(&wfStepDefinition))
+		reconcileLogger(ctx).Info("skip definition: not match the controller requirement of definition", "workflowStepDefinition", klog.KObj(&wfStepDefinition))
+		setReconcileResult(ctx, reconcileResultSkipped)
 		return ctrl.Result{}, nil
 	}
+	if r.dryRun {
+		return r.dryRunReconcile(ctx, &wfStepDefinition)
+	}
+	if err := r.ensureFinalizer(ctx, &wfStepDefinition); err != nil {
+		return r.reconcileError(ctx, &wfStepDefinition, "Could not register the finalizer of WorkflowStepDefinition", err)
+	}
+	if isDeprecated(&wfStepDefinition) {
+		changed, err := specChangedSinceLatestRevision(&wfStepDefinition)
+		if err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not check the spec change of the deprecated WorkflowStepDefinition", err)
+		}
+		if changed {
+			return r.refuseDeprecatedChange(ctx, &wfStepDefinition)
+		}
+	}
+
+	timer := newPhaseTimer(r.phaseDurations)
+	def := utils.NewCapabilityStepDef(&wfStepDefinition)
+	def.Name = req.NamespacedName.Name
+	def.ConfigMapNamePrefix = r.configMapPrefixOf(&wfStepDefinition)
+	def.ConfigMapNameTemplate = r.cmNameTemplate
+	def.NormalizeDefaults = r.normalizeDefaults
+	def.Immutable = r.immutableSchemas
+	def.EphemeralRevision = r.disableRevisions
+	def.MaxSchemaSize = r.maxSchemaSize
+	stop := timer.start(phaseCompose)
+	if err := r.composeTemplate(ctx, &wfStepDefinition, &def); err != nil {
+		return r.reconcileError(ctx, &wfStepDefinition, "Could not compose the embedded WorkflowStepDefinitions", err)
+	}
+	stop()
+	// the schema error is left to StoreOpenAPISchema to report
+	stop = timer.start(phaseRender)
+	schemaData, schema, schemaErr := renderLocatedParameterSchema(&def)
+	stop()
+	stop = timer.start(phaseLint)
+	warnings, lintErrs := r.lint(&lintContext{ctx: ctx, def: &wfStepDefinition, schema: schema})
+	stop()
+	if len(lintErrs) > 0 {
+		return r.reconcileError(ctx, &wfStepDefinition, "WorkflowStepDefinition failed the lint", lintError(lintErrs), lintHealthyCondition(warnings, lintErrs))
+	}
 
-	defRev, result, err := coredef.ReconcileDefinitionRevision(ctx, r.Client, r.record, &wfStepDefinition, r.defRevLimit, func(revision *common.Revision) error {
+	if r.enforceAdditiveOnly {
+		if err := r.checkAdditiveOnly(ctx, &wfStepDefinition, schema); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "WorkflowStepDefinition violates the additive-only evolution", err)
+		}
+	}
+
+	if err := r.checkNameLengths(&wfStepDefinition); err != nil {
+		return r.reconcileError(ctx, &wfStepDefinition, "WorkflowStepDefinition name overflows the object name limit", err)
+	}
+
+	stop = timer.start(phaseRevision)
+	if r.holdBreakingChanges {
+		if err := r.dropStalePendingRevision(ctx, &wfStepDefinition); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not drop the stale pending revision of WorkflowStepDefinition", err)
+		}
+	}
+	updateLatestRevision := func(revision *common.Revision) error {
+		if r.holdBreakingChanges {
+			pending, err := r.awaitsApproval(ctx, &wfStepDefinition, revision)
+			if err != nil {
+				return err
+			}
+			if pending {
+				wfStepDefinition.Status.PendingRevision = revision
+				return r.UpdateStatus(ctx, &wfStepDefinition)
+			}
+			wfStepDefinition.Status.PendingRevision = nil
+		}
 		wfStepDefinition.Status.LatestRevision = revision
 		if err := r.UpdateStatus(ctx, &wfStepDefinition); err != nil {
 			return err
 		}
 		return nil
-	})
-	if result != nil {
-		return *result, err
 	}
-	if err != nil {
-		return ctrl.Result{}, err
+	var defRev *v1beta1.DefinitionRevision
+	var err error
+	if r.disableRevisions {
+		if defRev, err = r.reconcileEphemeralRevision(ctx, &wfStepDefinition, updateLatestRevision); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not generate the revision of WorkflowStepDefinition", err)
+		}
+	} else {
+		var result *ctrl.Result
+		defRev, result, err = coredef.ReconcileDefinitionRevision(ctx, r.Client, r.recorder(ctx), &wfStepDefinition, r.defRevLimit, updateLatestRevision)
+		if result != nil {
+			return *result, err
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
 	}
+	// the artifacts are generated from the definition serving the schema, which is the latest revision while the
+	// revision following it is pending the approval
+	served := &wfStepDefinition
+	if wfStepDefinition.Status.PendingRevision != nil && wfStepDefinition.Status.LatestRevision != nil {
+		if defRev, served, err = r.servedRevision(ctx, &wfStepDefinition, &def); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not get the latest revision of WorkflowStepDefinition", err)
+		}
+		schemaData, schema, schemaErr = renderLocatedParameterSchema(&def)
+	}
+	tagRevision(ctx, defRev.Name)
+	if r.sweepOrphanRevs {
+		if err := r.sweepOrphanRevisions(ctx, &wfStepDefinition); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not reclaim the orphan DefinitionRevisions of WorkflowStepDefinition", err)
+		}
+	}
+	if err := r.reportRevisions(ctx, &wfStepDefinition); err != nil {
+		reconcileLogger(ctx).Error(err, "Could not count the DefinitionRevisions of WorkflowStepDefinition", "workflowStepDefinition", klog.KObj(&wfStepDefinition))
+	}
+	var compatibility *compatibilityReport
+	if r.migrationNote {
+		if compatibility, err = r.checkRevisionCompatibility(ctx, &wfStepDefinition, defRev); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not check the compatibility of WorkflowStepDefinition", err)
+		}
+	}
+	stop()
 
-	def := utils.NewCapabilityStepDef(&wfStepDefinition)
-	def.Name = req.NamespacedName.Name
-	// Store the parameter of stepDefinition to configMap
-	cmName, err := def.StoreOpenAPISchema(ctx, r.Client, req.Namespace, req.Name, defRev.Name)
+	stop = timer.start(phasePersist)
+	// the invalid declaration is left to the lint to report
+	idempotent, _ := declaredIdempotency(served)
+	if idempotent != nil {
+		def.SchemaExtensions = map[string]interface{}{idempotentExtension: *idempotent}
+	}
+	if r.schemaIDBaseURL != "" {
+		def.SchemaID = schemaID(r.schemaIDBaseURL, def.Name, defRev.Spec.Revision)
+	}
+	cmName, err := def.RenderSchemaConfigMapName(req.Namespace, req.Name, "")
+	if err != nil {
+		return r.reconcileError(ctx, &wfStepDefinition, "Could not render the schema ConfigMap name of WorkflowStepDefinition", err)
+	}
+	revCMName, err := def.RenderSchemaConfigMapName(req.Namespace, req.Name, defRev.Name)
 	if err != nil {
-		klog.InfoS("Could not store capability in ConfigMap", "err", err)
-		r.record.Event(&(wfStepDefinition), event.Warning("Could not store capability in ConfigMap", err))
-		return ctrl.Result{}, util.PatchCondition(ctx, r, &wfStepDefinition,
-			condition.ReconcileError(fmt.Errorf(util.ErrStoreCapabilityInConfigMap, wfStepDefinition.Name, err)))
+		return r.reconcileError(ctx, &wfStepDefinition, "Could not render the schema ConfigMap name of WorkflowStepDefinition", err)
+	}
+	cmNamespace := r.schemaNamespaceOf(&wfStepDefinition)
+	actx := &artifactContext{ctx: ctx, def: served, schema: schema, schemaData: schemaData, compatibility: compatibility}
+	if r.immutableSchemas && schemaErr == nil {
+		if cmName, err = r.prepareImmutableSchema(&def, actx); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not generate the artifacts of WorkflowStepDefinition", err)
+		}
+	}
+	var managedData map[string]string
+	var drifted []string
+	if !r.immutableSchemas && schemaErr == nil {
+		// the failing artifacts are reported on storing them after the schema
+		if managedData, err = r.managedConfigMapData(&def, actx); err == nil {
+			if drifted, err = r.detectDrift(ctx, &wfStepDefinition, cmNamespace, cmName, managedData); err != nil {
+				return r.reconcileError(ctx, &wfStepDefinition, "Could not check the schema ConfigMap of WorkflowStepDefinition for drift", err)
+			}
+		}
+	}
+	var firstSeen *metav1.Time
+	if r.firstSeen {
+		if firstSeen, err = r.resolveFirstSeen(ctx, &wfStepDefinition, cmName); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not resolve the first-seen timestamp of WorkflowStepDefinition", err)
+		}
+	}
+	stored := false
+	if schemaErr == nil && len(drifted) == 0 {
+		if stored, err = r.schemaStored(ctx, &wfStepDefinition, cmName, revCMName, managedData); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not check the schema ConfigMap of WorkflowStepDefinition", err)
+		}
+	}
+	if !stored {
+		// Store the parameter of stepDefinition to configMap
+		if cmName, err = def.StoreOpenAPISchema(ctx, r.Client, cmNamespace, req.Name, defRev.Name); err != nil {
+			if schemaErr != nil {
+				// the located error tells where the template fails
+				err = schemaErr
+			}
+			reconcileLogger(ctx).Info("Could not store capability in ConfigMap", "err", err)
+			r.recorder(ctx).Event(&(wfStepDefinition), event.Warning("Could not store capability in ConfigMap", err))
+			setReconcileResult(ctx, reconcileResultError)
+			result := ctrl.Result{}
+			if schemaErr == nil && isTransientError(err) {
+				result.RequeueAfter = r.retries.when(req.NamespacedName)
+				reconcileLogger(ctx).Info("Requeue WorkflowStepDefinition for the transient failure storing its schema", "workflowStepDefinition",
+					klog.KObj(&wfStepDefinition), "requeueAfter", result.RequeueAfter)
+			}
+			return result, util.PatchCondition(ctx, r, &wfStepDefinition,
+				condition.ReconcileError(fmt.Errorf(util.ErrStoreCapabilityInConfigMap, wfStepDefinition.Name, err)))
+		}
+	}
+	r.retries.forget(req.NamespacedName)
+
+	if schemaErr != nil {
+		return r.reconcileError(ctx, &wfStepDefinition, "Could not render the parameter schema of WorkflowStepDefinition", schemaErr)
+	}
+	if len(r.propagatedMetadata) > 0 {
+		if err := r.propagateMetadata(ctx, &wfStepDefinition, cmName); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not propagate the labels and annotations of WorkflowStepDefinition to ConfigMap", err)
+		}
 	}
 
-	if wfStepDefinition.Status.ConfigMapRef != cmName {
-		wfStepDefinition.Status.ConfigMapRef = cmName
+	status := wfStepDefinition.Status.DeepCopy()
+	namespaceChanged := status.ConfigMapNamespace != r.configMapNamespaceRef(&wfStepDefinition)
+	// the previous immutable ConfigMaps in the same namespace are retained up to the revision limit
+	if status.ConfigMapRef != "" && (namespaceChanged || (status.ConfigMapRef != cmName && !r.immutableSchemas)) {
+		if err := r.cleanupStaleConfigMap(ctx, &wfStepDefinition, status.ConfigMapNamespace, status.ConfigMapRef); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not clean up the stale schema ConfigMap of WorkflowStepDefinition", err)
+		}
+	}
+	if r.immutableSchemas {
+		if err := r.collectImmutableConfigMaps(ctx, &wfStepDefinition, cmName); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not collect the previous schema ConfigMaps of WorkflowStepDefinition", err)
+		}
+	}
+	if r.centralized(&wfStepDefinition) {
+		if err := r.collectCentralizedConfigMaps(ctx, &wfStepDefinition); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not collect the schema ConfigMaps of WorkflowStepDefinition", err)
+		}
+	}
+	status.ConfigMapRef = cmName
+	status.ConfigMapNamespace = r.configMapNamespaceRef(&wfStepDefinition)
+	status.Idempotent = idempotent
+	status.ConfigMapHash = ""
+	if managedData != nil {
+		status.ConfigMapHash = configMapDataHash(managedData)
+	}
+	if fingerprint := schemaFingerprint(schemaData); status.SchemaHash != fingerprint {
+		if r.auditSchemaChanges {
+			if err := r.auditSchemaChange(ctx, &wfStepDefinition, status.SchemaHash, fingerprint); err != nil {
+				return r.reconcileError(ctx, &wfStepDefinition, "Could not audit the schema change of WorkflowStepDefinition", err)
+			}
+		}
+		if status.SchemaHash != "" {
+			if err := r.recordSchemaChange(ctx, &wfStepDefinition, status.SchemaHash, fingerprint); err != nil {
+				return r.reconcileError(ctx, &wfStepDefinition, "Could not record the schema change of WorkflowStepDefinition", err)
+			}
+		}
+		status.SchemaHash = fingerprint
+		status.SchemaVersion++
+	}
+	r.recordLintWarnings(ctx, &wfStepDefinition, warnings, status)
+	status.SetConditions(lintHealthyCondition(warnings, nil))
+	if c, ok := deprecationCondition(&wfStepDefinition); ok {
+		status.SetConditions(c)
+	}
+	if err := r.reconcileStability(ctx, &wfStepDefinition, status); err != nil {
+		return r.reconcileError(ctx, &wfStepDefinition, "Could not surface the stability of WorkflowStepDefinition", err)
+	}
+	if r.complexityScore {
+		if err := r.reconcileComplexityScore(ctx, &wfStepDefinition, schema, status); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not compute the complexity score of WorkflowStepDefinition", err)
+		}
+	}
+	if !r.immutableSchemas {
+		if err := r.storeArtifacts(ctx, cmNamespace, cmName, actx); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not store the artifacts of WorkflowStepDefinition in ConfigMap", err)
+		}
+		if len(drifted) > 0 {
+			r.reportDrift(ctx, &wfStepDefinition, status, cmNamespace, cmName, drifted)
+		}
+	}
+	if r.provenance {
+		status.Provenance = definitionProvenance(&wfStepDefinition)
+		if status.Provenance != nil {
+			if err := r.storeProvenance(ctx, &wfStepDefinition, cmName, defRev.Name, status.Provenance); err != nil {
+				return r.reconcileError(ctx, &wfStepDefinition, "Could not store the provenance of WorkflowStepDefinition", err)
+			}
+		}
+	}
+	if r.firstSeen {
+		status.FirstSeen = firstSeen
+		if err := r.storeFirstSeen(ctx, cmNamespace, cmName, firstSeen); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not store the first-seen timestamp of WorkflowStepDefinition", err)
+		}
+	}
+	if r.exportConfigMap != "" || r.exportDir != "" {
+		if err := r.exportDefinition(ctx, &wfStepDefinition); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not export WorkflowStepDefinition", err)
+		}
+	}
+	if r.snapshot {
+		if err := r.storeSnapshot(ctx, &wfStepDefinition, schemaData); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not store the snapshot of WorkflowStepDefinition", err)
+		}
+	}
+	stop()
+	if r.detectDuplicates {
+		if err := r.reconcileDuplicates(ctx, &wfStepDefinition); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not detect the duplicates of WorkflowStepDefinition", err)
+		}
+	}
+	if r.schemaNotifyConfigMap != "" {
+		if err := r.notifySchemaChange(ctx, &wfStepDefinition, schemaFingerprint(schemaData)); err != nil {
+			return r.reconcileError(ctx, &wfStepDefinition, "Could not notify the schema change of WorkflowStepDefinition", err)
+		}
+	}
+
+	// the definition is ready once the schema ConfigMap and the DefinitionRevision are both in place
+	ready := status.ConfigMapRef != "" && status.LatestRevision != nil
+	becameReady := ready && !wfStepDefinition.Status.GetCondition(condition.TypeSynced).Equal(condition.ReconcileSuccess())
+	if ready {
+		status.SetConditions(condition.ReconcileSuccess())
+	}
+	timer.setStatus(status, &wfStepDefinition.Status)
+	if !apiequality.Semantic.DeepEqual(status, &wfStepDefinition.Status) {
+		wfStepDefinition.Status = *status
 		if err := r.UpdateStatus(ctx, &wfStepDefinition); err != nil {
-			klog.ErrorS(err, "Could not update WorkflowStepDefinition Status", "workflowStepDefinition", klog.KRef(req.Namespace, req.Name))
-			r.record.Event(&wfStepDefinition, event.Warning("Could not update WorkflowStepDefinition Status", err))
+			reconcileLogger(ctx).Error(err, "Could not update WorkflowStepDefinition Status", "workflowStepDefinition", klog.KRef(req.Namespace, req.Name))
+			r.recorder(ctx).Event(&wfStepDefinition, event.Warning("Could not update WorkflowStepDefinition Status", err))
+			setReconcileResult(ctx, reconcileResultError)
 			return ctrl.Result{}, util.PatchCondition(ctx, r, &wfStepDefinition,
 				condition.ReconcileError(fmt.Errorf(util.ErrUpdateWorkflowStepDefinition, wfStepDefinition.Name, err)))
 		}
-		klog.InfoS("Successfully updated the status.configMapRef of the WorkflowStepDefinition", "workflowStepDefinition",
-			klog.KRef(req.Namespace, req.Name), "status.configMapRef", cmName)
+		reconcileLogger(ctx).Info("Successfully updated the status of the WorkflowStepDefinition", "workflowStepDefinition",
+			klog.KRef(req.Namespace, req.Name), "status.configMapRef", klog.KRef(cmNamespace, cmName))
+	}
+	if becameReady {
+		r.recorder(ctx).Event(&wfStepDefinition, event.Normal("WorkflowStepDefinition is ready",
+			fmt.Sprintf("the parameter schema is stored in ConfigMap %s/%s and the revision is %s", cmNamespace, cmName, status.LatestRevision.Name)))
+	}
+
+	if err := r.indexCatalog(ctx, &wfStepDefinition, schema); err != nil {
+		reconcileLogger(ctx).Error(err, "Could not index the WorkflowStepDefinition in the catalog, will retry", "workflowStepDefinition", klog.KRef(req.Namespace, req.Name))
+		return ctrl.Result{Requeue: true}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
+// reconcileError records the error in the event and the ReconcileError condition of the WorkflowStepDefinition, the
+// extra conditions are patched along with it
+func (r *Reconciler) reconcileError(ctx context.Context, def *v1beta1.WorkflowStepDefinition, reason string, err error, conditions ...condition.Condition) (ctrl.Result, error) {
+	if isNoKindMatch(err) {
+		// the unknown kind is likely to be installed soon, so the conditions are left as they are
+		return r.requeueNoKindMatch(ctx, err), nil
+	}
+	reconcileLogger(ctx).Error(err, reason, "workflowStepDefinition", klog.KObj(def))
+	r.recorder(ctx).Event(def, event.Warning(event.Reason(reason), err))
+	setReconcileResult(ctx, reconcileResultError)
+	return ctrl.Result{}, util.PatchCondition(ctx, r, def,
+		append([]condition.Condition{condition.ReconcileError(fmt.Errorf(util.ErrUpdateWorkflowStepDefinition, def.Name, err))}, conditions...)...)
+}
+
+// patchLabels merges the labels into the WorkflowStepDefinition, it's a no-op if none of them changes
+func (r *Reconciler) patchLabels(ctx context.Context, def *v1beta1.WorkflowStepDefinition, labels map[string]string) error {
+	changed := false
+	for k, v := range labels {
+		if current, ok := def.Labels[k]; !ok || current != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	patch := client.MergeFrom(def.DeepCopy())
+	def.SetLabels(util.MergeMapOverrideWithDst(def.GetLabels(), labels))
+	return r.Patch(ctx, def, patch)
+}
+
 // UpdateStatus updates v1beta1.WorkflowStepDefinition's Status with retry.RetryOnConflict
 func (r *Reconciler) UpdateStatus(ctx context.Context, def *v1beta1.WorkflowStepDefinition, opts ...client.UpdateOption) error {
 	status := def.DeepCopy().Status
-	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+	return retry.RetryOnConflict(r.statusRetryBackoff(), func() (err error) {
 		if err = r.Get(ctx, client.ObjectKey{Namespace: def.Namespace, Name: def.Name}, def); err != nil {
 			return
 		}
@@ -132,34 +611,183 @@ func (r *Reconciler) UpdateStatus(ctx context.Context, def *v1beta1.WorkflowStep
 	})
 }
 
+// statusRetryBackoff returns the configured backoff of retrying the status updates, it defaults to retry.DefaultBackoff
+func (r *Reconciler) statusRetryBackoff() wait.Backoff {
+	if r.statusBackoff.Steps <= 0 {
+		return retry.DefaultBackoff
+	}
+	return r.statusBackoff
+}
+
 // SetupWithManager will setup with event recorder
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.record = event.NewAPIRecorder(mgr.GetEventRecorderFor("WorkflowStepDefinition")).
 		WithAnnotations("controller", "WorkflowStepDefinition")
+	registerMetrics()
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.concurrentReconciles,
 		}).
-		For(&v1beta1.WorkflowStepDefinition{}).
+		For(&v1beta1.WorkflowStepDefinition{}, builder.WithPredicates(definitionChangedPredicate())).
+		Watches(&source.Kind{Type: &v1beta1.WorkflowStepDefinition{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueDependents),
+			builder.WithPredicates(definitionChangedPredicate())).
 		Complete(r)
 }
 
+// definitionChangedPredicate filters out the status-only updates of the WorkflowStepDefinition, e.g. the ones written
+// by the controller itself. The label and annotation changes don't bump the generation but are let through, since the
+// controller requirement and many options of the controller are declared by them
+func definitionChangedPredicate() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
 // Setup adds a controller that reconciles WorkflowStepDefinition.
 func Setup(mgr ctrl.Manager, args oamctrl.Args) error {
+	if err := validateConfigMapPrefix(args.StepDefinitionConfigMapPrefix); err != nil {
+		return err
+	}
+	if err := validateSchemaProfiles(args.StepDefinitionSchemaProfiles); err != nil {
+		return err
+	}
+	if err := validateEnvConvention(args.StepDefinitionEnvMapConvention); err != nil {
+		return err
+	}
+	forbiddenPatterns, err := compileForbiddenPatterns(args.StepDefinitionForbiddenDescriptionPatterns)
+	if err != nil {
+		return err
+	}
+	cmNameTemplate, err := parseConfigMapNameTemplate(args.StepDefinitionConfigMapNameTemplate)
+	if err != nil {
+		return err
+	}
+	if args.StepDefinitionDisableRevisions && args.StepDefinitionBreakingChangeApproval {
+		return errors.New("the breaking change approval of WorkflowStepDefinitions holds the DefinitionRevisions, it can't be enabled with the revisions disabled")
+	}
 	r := Reconciler{
 		Client:  mgr.GetClient(),
 		Scheme:  mgr.GetScheme(),
 		dm:      args.DiscoveryMapper,
 		options: parseOptions(args),
 	}
+	r.forbiddenPatterns = forbiddenPatterns
+	r.cmNameTemplate = cmNameTemplate
+	if err := mgr.Add(&readinessProbe{r: &r, cache: mgr.GetCache()}); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck(readinessCheckName, r.checkReady); err != nil {
+		return err
+	}
+	if r.detectDuplicates {
+		if err := addSpecFingerprintIndex(mgr.GetFieldIndexer()); err != nil {
+			return err
+		}
+	}
+	if r.schemaNamespace != "" {
+		if err := checkSchemaNamespaceAccess(context.Background(), mgr.GetClient(), r.schemaNamespace); err != nil {
+			return err
+		}
+	}
+	if r.schemaServerAddr != "" {
+		if err := mgr.Add(&schemaServer{addr: r.schemaServerAddr, handler: &schemaHandler{client: mgr.GetClient(),
+			configMapPrefix: r.configMapPrefix, cmNameTemplate: r.cmNameTemplate, schemaNamespace: r.schemaNamespace}}); err != nil {
+			return err
+		}
+	}
+	klog.InfoS("Setup WorkflowStepDefinition controller", "reconcileTimeout", common2.ReconcileTimeoutOrDefault(r.reconcileTimeout),
+		"statusBackoff", r.statusRetryBackoff())
 	return r.SetupWithManager(mgr)
 }
 
 func parseOptions(args oamctrl.Args) options {
-	return options{
-		defRevLimit:          args.DefRevisionLimit,
-		concurrentReconciles: args.ConcurrentReconciles,
-		ignoreDefNoCtrlReq:   args.IgnoreDefinitionWithoutControllerRequirement,
-		controllerVersion:    version.VelaVersion,
+	opts := options{
+		defRevLimit:           args.DefRevisionLimit,
+		concurrentReconciles:  args.ConcurrentReconciles,
+		ignoreDefNoCtrlReq:    args.IgnoreDefinitionWithoutControllerRequirement,
+		controllerVersion:     version.VelaVersion,
+		complexityScore:       args.StepDefinitionComplexityScore,
+		schemaNotifyConfigMap: args.StepDefinitionSchemaNotifyConfigMap,
+		rejectReservedNames:   args.StepDefinitionRejectReservedNames,
+		usageSnippet:          args.StepDefinitionUsageSnippet,
+		strictCUE:             args.StepDefinitionStrictCUE,
+		paramLifecycle:        args.StepDefinitionParamLifecycle,
+		requiredAnnotations:   args.StepDefinitionRequiredAnnotations,
+		schemaIDBaseURL:       args.StepDefinitionSchemaIDBaseURL,
+		detectDuplicates:      args.StepDefinitionDetectDuplicates,
+		aliasDuplicates:       args.StepDefinitionAliasDuplicates,
+		checkParameterBounds:  args.StepDefinitionCheckParameterBounds,
+		catalogIndexer:        args.StepDefinitionCatalogIndexer,
+		maxDescriptionLength:  args.StepDefinitionMaxDescriptionLength,
+		checkEnumDefaults:     args.StepDefinitionCheckEnumDefaults,
+		requiredSchema:        args.StepDefinitionRequiredSchema,
+		requireDescription:    args.StepDefinitionRequireDescription,
+		rejectNoDescription:   args.StepDefinitionRejectNoDescription,
+		checkNameCollisions:   args.StepDefinitionCheckNameCollisions,
+		asyncAPI:              args.StepDefinitionAsyncAPI,
+		snapshot:              args.StepDefinitionSnapshot,
+		maxGroupParameters:    args.StepDefinitionMaxGroupParameters,
+		auditSchemaChanges:    args.StepDefinitionAuditSchemaChanges,
+		checkArrayItems:       args.StepDefinitionCheckArrayItems,
+		phaseDurations:        args.StepDefinitionPhaseDurations,
+		migrationNote:         args.StepDefinitionMigrationNote,
+		paramDependencies:     args.StepDefinitionParamDependencies,
+		configMapPrefix:       args.StepDefinitionConfigMapPrefix,
+		checkIntegerTypes:     args.StepDefinitionCheckIntegerTypes,
+		schemaEventTarget:     args.StepDefinitionSchemaEventTarget,
+		fuzzCorpus:            args.StepDefinitionFuzzCorpus,
+		checkReferences:       args.StepDefinitionCheckReferences,
+		checkTraitOverlap:     args.StepDefinitionCheckTraitOverlap,
+		provenance:            args.StepDefinitionProvenance,
+		exampleApp:            args.StepDefinitionExampleApp,
+		checkIdempotency:      args.StepDefinitionCheckIdempotency,
+		normalizeDefaults:     args.StepDefinitionNormalizeDefaults,
+		maxEnumValues:         args.StepDefinitionMaxEnumValues,
+		checksums:             args.StepDefinitionChecksums,
+		checkResources:        args.StepDefinitionCheckResourceRequirements,
+		graphQL:               args.StepDefinitionGraphQL,
+		schemaProfiles:        args.StepDefinitionSchemaProfiles,
+		firstSeen:             args.StepDefinitionFirstSeen,
+		checkExamples:         args.StepDefinitionCheckExamples,
+		exportConfigMap:       args.StepDefinitionExportConfigMap,
+		exportDir:             args.StepDefinitionExportDir,
+		holdBreakingChanges:   args.StepDefinitionBreakingChangeApproval,
+		reconcileTimeout:      args.StepDefinitionReconcileTimeout,
+		statusBackoff:         args.StepDefinitionStatusBackoff,
+		envMapConvention:      args.StepDefinitionEnvMapConvention,
+		checkDurations:        args.StepDefinitionCheckDurations,
+		mockOutput:            args.StepDefinitionMockOutput,
+		checkSecretDefaults:   args.StepDefinitionCheckSecretDefaults,
+		checkUnusedParams:     args.StepDefinitionCheckUnusedParameters,
+		rejectUnusedParams:    args.StepDefinitionRejectUnusedParameters,
+		schemaServerAddr:      args.StepDefinitionSchemaServerAddr,
+		propagatedMetadata:    args.StepDefinitionPropagatedMetadata,
+		enforceAdditiveOnly:   args.StepDefinitionEnforceAdditiveOnly,
+		sweepOrphanRevs:       args.StepDefinitionSweepOrphanRevisions,
+		dryRun:                args.StepDefinitionDryRun,
+		schemaNamespace:       args.StepDefinitionSchemaNamespace,
+		immutableSchemas:      args.StepDefinitionImmutableSchemas,
+		maxSchemaSize:         args.StepDefinitionMaxSchemaSize,
+		dmRefreshInterval:     args.StepDefinitionDiscoveryRefreshInterval,
+		disableRevisions:      args.StepDefinitionDisableRevisions,
+	}
+	if opts.catalogIndexer == nil {
+		opts.catalogIndexer = oamctrl.NopCatalogIndexer{}
+	}
+	if args.StepDefinitionCheckReservedNames {
+		reservedNames := args.StepDefinitionReservedNames
+		if len(reservedNames) == 0 {
+			reservedNames = DefaultReservedStepNames
+		}
+		opts.reservedNames = sets.NewString(reservedNames...)
+	}
+	if args.StepDefinitionCheckBooleanDescriptions {
+		opts.booleanTrueKeywords = args.StepDefinitionBooleanTrueKeywords
+		if len(opts.booleanTrueKeywords) == 0 {
+			opts.booleanTrueKeywords = DefaultBooleanTrueKeywords
+		}
+		opts.booleanFalseKeywords = args.StepDefinitionBooleanFalseKeywords
+		if len(opts.booleanFalseKeywords) == 0 {
+			opts.booleanFalseKeywords = DefaultBooleanFalseKeywords
+		}
 	}
+	return opts
 }
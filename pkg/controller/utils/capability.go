@@ -19,17 +19,26 @@ package utils
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
 	"gopkg.in/src-d/go-git.v4"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +49,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/appfile/helm"
 	"github.com/oam-dev/kubevela/pkg/cue/script"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/pkg/utils/terraform"
@@ -78,6 +88,22 @@ func (e ErrNoSectionParameterInCue) Error() string {
 	return fmt.Sprintf("capability %s doesn't contain section `parameter`", e.capName)
 }
 
+// DefaultMaxSchemaSize is the default max size in bytes of the schema stored in ConfigMap, which is just under the 1MiB
+// limit of the ConfigMap to leave room for the metadata
+const DefaultMaxSchemaSize = 1000 * 1024
+
+// ErrSchemaTooLarge means the rendered schema exceeds the max size to be stored in ConfigMap
+type ErrSchemaTooLarge struct {
+	capName string
+	size    int
+	limit   int
+}
+
+func (e ErrSchemaTooLarge) Error() string {
+	return fmt.Sprintf("the schema of capability %s is %d bytes and exceeds the limit of %d bytes, reduce the parameters or their descriptions",
+		e.capName, e.size, e.limit)
+}
+
 // CapabilityDefinitionInterface is the interface for Capability (WorkloadDefinition and TraitDefinition)
 type CapabilityDefinitionInterface interface {
 	GetCapabilityObject(ctx context.Context, k8sClient client.Client, namespace, name string) (*types.Capability, error)
@@ -493,6 +519,28 @@ func (def *CapabilityTraitDefinition) StoreOpenAPISchema(ctx context.Context, k8
 type CapabilityStepDefinition struct {
 	Name           string                         `json:"name"`
 	StepDefinition v1beta1.WorkflowStepDefinition `json:"stepDefinition"`
+	// SchemaID is populated as the `$id` of the stored OpenAPI v3 schema if it's not empty
+	SchemaID string `json:"schemaID,omitempty"`
+	// SchemaExtensions are set as the extensions of the stored OpenAPI v3 schema, e.g. x-idempotent
+	SchemaExtensions map[string]interface{} `json:"schemaExtensions,omitempty"`
+	// NormalizeDefaults rewrites the quantity and duration defaults of the stored OpenAPI v3 schema to the canonical form
+	NormalizeDefaults bool `json:"normalizeDefaults,omitempty"`
+	// DryRun only renders the OpenAPI v3 schema in StoreOpenAPISchema without writing any ConfigMap
+	DryRun bool `json:"dryRun,omitempty"`
+	// Immutable stores the schema in the immutable ConfigMaps, the one of the StepDefinition is named after the hash of
+	// its data so that a changed schema is stored in a new ConfigMap instead of updating the existing one
+	Immutable bool `json:"immutable,omitempty"`
+	// Data are the extra data keys stored along with the schema in the immutable ConfigMap of the StepDefinition, which
+	// can't be added afterwards
+	Data map[string]string `json:"data,omitempty"`
+	// MaxSchemaSize is the max size in bytes of the data stored in the ConfigMap of the StepDefinition, 0 means no limit
+	MaxSchemaSize int `json:"maxSchemaSize,omitempty"`
+	// ConfigMapNameTemplate renders the names of the schema ConfigMaps of the StepDefinition and its revisions from the
+	// SchemaConfigMapNameFields, the default names are used if it's nil
+	ConfigMapNameTemplate *template.Template `json:"-"`
+	// EphemeralRevision tells the DefinitionRevision of the revision name passed to StoreOpenAPISchema isn't persisted,
+	// the ConfigMap of the revision is labeled and owned as the one of the StepDefinition instead
+	EphemeralRevision bool `json:"ephemeralRevision,omitempty"`
 
 	CapabilityBaseDefinition
 }
@@ -505,6 +553,41 @@ func NewCapabilityStepDef(stepdefinition *v1beta1.WorkflowStepDefinition) Capabi
 	return def
 }
 
+// SchemaConfigMapName returns the name of the ConfigMap storing the schema of the StepDefinition or its revision
+func (def *CapabilityStepDefinition) SchemaConfigMapName(name string) string {
+	return def.ConfigMapName(name, typeWorkflowStepDefinition)
+}
+
+// SchemaConfigMapNameFields are the fields the ConfigMap name template of the StepDefinition is rendered with
+type SchemaConfigMapNameFields struct {
+	// Name and Namespace are the ones of the StepDefinition
+	Name      string
+	Namespace string
+	// Revision is the name of the DefinitionRevision for the ConfigMap of the revision, empty for the one of the StepDefinition
+	Revision string
+}
+
+// RenderSchemaConfigMapName returns the name of the ConfigMap storing the schema of the StepDefinition, or its revision if
+// the revision name isn't empty. The name is rendered by the ConfigMapNameTemplate if it's set, prefixed by the
+// ConfigMapNamePrefix as well, and must be a valid DNS-1123 subdomain.
+func (def *CapabilityStepDefinition) RenderSchemaConfigMapName(namespace, name, revision string) (string, error) {
+	if def.ConfigMapNameTemplate == nil {
+		if revision != "" {
+			return def.SchemaConfigMapName(revision), nil
+		}
+		return def.SchemaConfigMapName(name), nil
+	}
+	var b strings.Builder
+	if err := def.ConfigMapNameTemplate.Execute(&b, SchemaConfigMapNameFields{Name: name, Namespace: namespace, Revision: revision}); err != nil {
+		return "", fmt.Errorf("failed to render the ConfigMap name of capability %s: %w", name, err)
+	}
+	cmName := def.ConfigMapNamePrefix + b.String()
+	if errs := validation.IsDNS1123Subdomain(cmName); len(errs) > 0 {
+		return "", fmt.Errorf("invalid ConfigMap name %q rendered for capability %s: %s", cmName, name, strings.Join(errs, "; "))
+	}
+	return cmName, nil
+}
+
 // GetOpenAPISchema gets OpenAPI v3 schema by StepDefinition name
 func (def *CapabilityStepDefinition) GetOpenAPISchema(name string) ([]byte, error) {
 	capability, err := appfile.ConvertTemplateJSON2Object(name, nil, def.StepDefinition.Spec.Schematic)
@@ -514,50 +597,277 @@ func (def *CapabilityStepDefinition) GetOpenAPISchema(name string) ([]byte, erro
 	return getOpenAPISchema(capability)
 }
 
-// StoreOpenAPISchema stores OpenAPI v3 schema from StepDefinition in ConfigMap
-func (def *CapabilityStepDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client, namespace, name string, revName string) (string, error) {
-	var jsonSchema []byte
-	var err error
-
-	jsonSchema, err = def.GetOpenAPISchema(name)
+// RenderOpenAPISchema renders the OpenAPI v3 schema to be stored for the StepDefinition, with the $id,
+// the extensions and the normalized defaults applied
+func (def *CapabilityStepDefinition) RenderOpenAPISchema(name string) ([]byte, error) {
+	jsonSchema, err := def.GetOpenAPISchema(name)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+		return nil, fmt.Errorf("failed to generate OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
 	}
+	if def.SchemaID != "" {
+		if jsonSchema, err = setSchemaExtensions(jsonSchema, map[string]interface{}{"$id": def.SchemaID}); err != nil {
+			return nil, fmt.Errorf("failed to set the $id of OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+		}
+	}
+	if len(def.SchemaExtensions) > 0 {
+		if jsonSchema, err = setSchemaExtensions(jsonSchema, def.SchemaExtensions); err != nil {
+			return nil, fmt.Errorf("failed to set the extensions of OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+		}
+	}
+	if def.NormalizeDefaults {
+		if jsonSchema, err = normalizeSchemaDefaults(jsonSchema); err != nil {
+			return nil, fmt.Errorf("failed to normalize the defaults of OpenAPI v3 JSON schema for capability %s: %w", def.Name, err)
+		}
+	}
+	return jsonSchema, nil
+}
 
+// StoreOpenAPISchema stores OpenAPI v3 schema from StepDefinition in ConfigMap. If the namespace isn't the one of the
+// StepDefinition, the ConfigMaps are linked to the StepDefinition by the labels instead of the ownerReferences which
+// can't cross the namespaces, and their garbage collection is left to the caller.
+func (def *CapabilityStepDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client, namespace, name string, revName string) (string, error) {
+	jsonSchema, err := def.RenderOpenAPISchema(name)
+	if err != nil {
+		return "", err
+	}
+	if err = def.CheckSchemaSize(jsonSchema); err != nil {
+		return "", err
+	}
 	stepDefinition := def.StepDefinition
-	ownerReference := []metav1.OwnerReference{{
-		APIVersion:         stepDefinition.APIVersion,
-		Kind:               stepDefinition.Kind,
-		Name:               stepDefinition.Name,
-		UID:                stepDefinition.GetUID(),
-		Controller:         pointer.BoolPtr(true),
-		BlockOwnerDeletion: pointer.BoolPtr(true),
-	}}
-	cmName, err := def.CreateOrUpdateConfigMap(ctx, k8sClient, namespace, stepDefinition.Name, typeWorkflowStepDefinition, stepDefinition.Labels, nil, jsonSchema, ownerReference)
+	cmName, err := def.RenderSchemaConfigMapName(stepDefinition.Namespace, stepDefinition.Name, "")
+	if err != nil {
+		return "", err
+	}
+	if def.Immutable {
+		cmName = immutableConfigMapName(cmName, def.schemaData(jsonSchema))
+	}
+	if def.DryRun {
+		return cmName, nil
+	}
+	revCMName, err := def.RenderSchemaConfigMapName(stepDefinition.Namespace, stepDefinition.Name, revName)
+	if err != nil {
+		return "", err
+	}
+	if revCMName == cmName {
+		return "", fmt.Errorf("the ConfigMap name %s of capability %s is the same as the one of its revision %s", cmName, def.Name, revName)
+	}
+
+	centralized := namespace != stepDefinition.Namespace
+	var ownerReference []metav1.OwnerReference
+	labels := stepDefinition.Labels
+	if centralized {
+		labels = centralizedSchemaLabels(&stepDefinition, stepDefinition.Labels)
+	} else {
+		ownerReference = []metav1.OwnerReference{{
+			APIVersion:         stepDefinition.APIVersion,
+			Kind:               stepDefinition.Kind,
+			Name:               stepDefinition.Name,
+			UID:                stepDefinition.GetUID(),
+			Controller:         pointer.BoolPtr(true),
+			BlockOwnerDeletion: pointer.BoolPtr(true),
+		}}
+	}
+	if def.Immutable {
+		err = def.CreateOrReplaceImmutableConfigMap(ctx, k8sClient, namespace, cmName, stepDefinition.Name, labels, def.schemaData(jsonSchema), ownerReference)
+	} else {
+		err = def.CreateOrUpdateNamedConfigMap(ctx, k8sClient, namespace, cmName, stepDefinition.Name, labels, nil, jsonSchema, ownerReference)
+	}
 	if err != nil {
 		return cmName, err
 	}
 
 	// Create a configmap to store parameter for each definitionRevision
-	defRev := new(v1beta1.DefinitionRevision)
-	if err = k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: revName}, defRev); err != nil {
-		return "", err
+	if def.EphemeralRevision {
+		// the ownerReference to the StepDefinition is kept for the ConfigMap of the revision
+		labels = make(map[string]string, len(stepDefinition.Labels))
+		for k, v := range stepDefinition.Labels {
+			labels[k] = v
+		}
+		if centralized {
+			labels = centralizedSchemaLabels(&stepDefinition, labels)
+		}
+	} else {
+		defRev := new(v1beta1.DefinitionRevision)
+		if err = k8sClient.Get(ctx, client.ObjectKey{Namespace: stepDefinition.Namespace, Name: revName}, defRev); err != nil {
+			return "", err
+		}
+		labels = defRev.Spec.WorkflowStepDefinition.Labels
+		if centralized {
+			labels = centralizedSchemaLabels(&stepDefinition, labels)
+		} else {
+			ownerReference = []metav1.OwnerReference{{
+				APIVersion:         defRev.APIVersion,
+				Kind:               defRev.Kind,
+				Name:               defRev.Name,
+				UID:                defRev.GetUID(),
+				Controller:         pointer.BoolPtr(true),
+				BlockOwnerDeletion: pointer.BoolPtr(true),
+			}}
+		}
+	}
+	if def.Immutable {
+		// the name of the revision ConfigMap already tells the revision
+		err = def.CreateOrReplaceImmutableConfigMap(ctx, k8sClient, namespace, revCMName, revName, labels,
+			map[string]string{types.OpenapiV3JSONSchema: string(jsonSchema)}, ownerReference)
+	} else {
+		err = def.CreateOrUpdateNamedConfigMap(ctx, k8sClient, namespace, revCMName, revName, labels, nil, jsonSchema, ownerReference)
 	}
-	ownerReference = []metav1.OwnerReference{{
-		APIVersion:         defRev.APIVersion,
-		Kind:               defRev.Kind,
-		Name:               defRev.Name,
-		UID:                defRev.GetUID(),
-		Controller:         pointer.BoolPtr(true),
-		BlockOwnerDeletion: pointer.BoolPtr(true),
-	}}
-	_, err = def.CreateOrUpdateConfigMap(ctx, k8sClient, namespace, revName, typeWorkflowStepDefinition, defRev.Spec.WorkflowStepDefinition.Labels, nil, jsonSchema, ownerReference)
 	if err != nil {
 		return cmName, err
 	}
 	return cmName, nil
 }
 
+// ImmutableSchemaConfigMapName returns the name of the immutable ConfigMap storing the schema and the extra data of the
+// StepDefinition, which is suffixed by the hash of the data
+func (def *CapabilityStepDefinition) ImmutableSchemaConfigMapName(jsonSchema []byte) (string, error) {
+	cmName, err := def.RenderSchemaConfigMapName(def.StepDefinition.Namespace, def.StepDefinition.Name, "")
+	if err != nil {
+		return "", err
+	}
+	return immutableConfigMapName(cmName, def.schemaData(jsonSchema)), nil
+}
+
+func immutableConfigMapName(cmName string, data map[string]string) string {
+	return fmt.Sprintf("%s-%s", cmName, configMapDataHash(data))
+}
+
+// schemaData returns the data of the immutable ConfigMap of the StepDefinition
+func (def *CapabilityStepDefinition) schemaData(jsonSchema []byte) map[string]string {
+	data := make(map[string]string, len(def.Data)+1)
+	for k, v := range def.Data {
+		data[k] = v
+	}
+	data[types.OpenapiV3JSONSchema] = string(jsonSchema)
+	return data
+}
+
+// CheckSchemaSize checks the data stored along with the schema in the ConfigMap of the StepDefinition doesn't exceed
+// the max size, so that the oversized schema fails fast rather than with the opaque error of the API server
+func (def *CapabilityStepDefinition) CheckSchemaSize(jsonSchema []byte) error {
+	if def.MaxSchemaSize <= 0 {
+		return nil
+	}
+	size := 0
+	for k, v := range def.schemaData(jsonSchema) {
+		size += len(k) + len(v)
+	}
+	if size > def.MaxSchemaSize {
+		return ErrSchemaTooLarge{capName: def.Name, size: size, limit: def.MaxSchemaSize}
+	}
+	return nil
+}
+
+// configMapDataHash returns the short sha256 hash of the ConfigMap data in the order of the keys
+func configMapDataHash(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, k := range keys {
+		hash.Write([]byte(k))
+		hash.Write([]byte{0})
+		hash.Write([]byte(data[k]))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:configMapHashLength]
+}
+
+// configMapHashLength is the length of the hash suffixed to the names of the immutable ConfigMaps
+const configMapHashLength = 10
+
+// centralizedSchemaLabels returns the labels of the schema ConfigMap stored out of the namespace of the StepDefinition,
+// which link the ConfigMap to the StepDefinition
+func centralizedSchemaLabels(stepDefinition *v1beta1.WorkflowStepDefinition, labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(labels)+2)
+	for k, v := range labels {
+		merged[k] = v
+	}
+	merged[oam.LabelWorkflowStepDefinitionName] = stepDefinition.Name
+	merged[oam.LabelWorkflowStepDefinitionNamespace] = stepDefinition.Namespace
+	return merged
+}
+
+// setSchemaExtensions sets the extensions of the OpenAPI v3 JSON schema, e.g. the `$id`
+func setSchemaExtensions(jsonSchema []byte, extensions map[string]interface{}) ([]byte, error) {
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON(jsonSchema); err != nil {
+		return nil, err
+	}
+	if schema.Extensions == nil {
+		schema.Extensions = map[string]interface{}{}
+	}
+	for k, v := range extensions {
+		schema.Extensions[k] = v
+	}
+	return schema.MarshalJSON()
+}
+
+var (
+	quantityDefaultPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(Ki|Mi|Gi|Ti|Pi|Ei|m|k|M|G|T|P|E)$`)
+	durationDefaultPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`)
+	durationNameHints      = []string{"timeout", "interval", "duration", "period", "delay", "ttl"}
+)
+
+// normalizeSchemaDefaults rewrites the quantity and duration defaults of the OpenAPI v3 JSON schema to the canonical
+// form, e.g. `1024Mi` to `1Gi` and `90s` to `1m30s`, so that the defaults written differently don't show up as diffs
+func normalizeSchemaDefaults(jsonSchema []byte) ([]byte, error) {
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON(jsonSchema); err != nil {
+		return nil, err
+	}
+	normalizeDefaults("", schema)
+	return schema.MarshalJSON()
+}
+
+func normalizeDefaults(name string, schema *openapi3.Schema) {
+	if value, ok := schema.Default.(string); ok {
+		schema.Default = normalizeDefault(name, value)
+	}
+	for key, property := range schema.Properties {
+		if property != nil && property.Value != nil {
+			normalizeDefaults(key, property.Value)
+		}
+	}
+	if schema.Items != nil && schema.Items.Value != nil {
+		normalizeDefaults(name, schema.Items.Value)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Value != nil {
+		normalizeDefaults(name, schema.AdditionalProperties.Value)
+	}
+}
+
+// normalizeDefault returns the canonical form of the quantity or duration default. The value like `500m` is both a
+// quantity and a duration, it's taken as a duration only if the parameter name suggests so.
+func normalizeDefault(name, value string) string {
+	isQuantity := quantityDefaultPattern.MatchString(value)
+	if durationDefaultPattern.MatchString(value) && (!isQuantity || IsDurationName(name)) {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d.String()
+		}
+		return value
+	}
+	if isQuantity {
+		if q, err := resource.ParseQuantity(value); err == nil {
+			return q.String()
+		}
+	}
+	return value
+}
+
+// IsDurationName returns whether the parameter name suggests a duration, e.g. timeout or retryInterval
+func IsDurationName(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range durationNameHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
 // CapabilityPolicyDefinition is the Capability struct for PolicyDefinition
 type CapabilityPolicyDefinition struct {
 	Name             string                   `json:"name"`
@@ -629,12 +939,31 @@ func (def *CapabilityPolicyDefinition) StoreOpenAPISchema(ctx context.Context, k
 
 // CapabilityBaseDefinition is the base struct for CapabilityWorkloadDefinition and CapabilityTraitDefinition
 type CapabilityBaseDefinition struct {
+	// ConfigMapNamePrefix is prepended to the names of the schema ConfigMaps if it's not empty
+	ConfigMapNamePrefix string `json:"configMapNamePrefix,omitempty"`
+}
+
+// ConfigMapName returns the name of the ConfigMap storing the schema of the definition
+func (def *CapabilityBaseDefinition) ConfigMapName(definitionName, definitionType string) string {
+	return fmt.Sprintf("%s%s-%s%s", def.ConfigMapNamePrefix, definitionType, types.CapabilityConfigMapNamePrefix, definitionName)
 }
 
 // CreateOrUpdateConfigMap creates ConfigMap to store OpenAPI v3 schema or or updates data in ConfigMap
 func (def *CapabilityBaseDefinition) CreateOrUpdateConfigMap(ctx context.Context, k8sClient client.Client, namespace,
 	definitionName, definitionType string, labels map[string]string, appliedWorkloads []string, jsonSchema []byte, ownerReferences []metav1.OwnerReference) (string, error) {
-	cmName := fmt.Sprintf("%s-%s%s", definitionType, types.CapabilityConfigMapNamePrefix, definitionName)
+	cmName := def.ConfigMapName(definitionName, definitionType)
+	if def.ConfigMapNamePrefix != "" {
+		// the name without the prefix is as valid as the definition name
+		if errs := validation.IsDNS1123Subdomain(cmName); len(errs) > 0 {
+			return cmName, fmt.Errorf("invalid ConfigMap name %s with the prefix %s: %s", cmName, def.ConfigMapNamePrefix, strings.Join(errs, "; "))
+		}
+	}
+	return cmName, def.CreateOrUpdateNamedConfigMap(ctx, k8sClient, namespace, cmName, definitionName, labels, appliedWorkloads, jsonSchema, ownerReferences)
+}
+
+// CreateOrUpdateNamedConfigMap creates the ConfigMap of the name to store OpenAPI v3 schema or updates data in it
+func (def *CapabilityBaseDefinition) CreateOrUpdateNamedConfigMap(ctx context.Context, k8sClient client.Client, namespace, cmName,
+	definitionName string, labels map[string]string, appliedWorkloads []string, jsonSchema []byte, ownerReferences []metav1.OwnerReference) error {
 	var cm v1.ConfigMap
 	var data = map[string]string{
 		types.OpenapiV3JSONSchema: string(jsonSchema),
@@ -669,20 +998,91 @@ func (def *CapabilityBaseDefinition) CreateOrUpdateConfigMap(ctx context.Context
 		}
 		err = k8sClient.Create(ctx, &cm)
 		if err != nil {
-			return cmName, fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+			return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
 		}
-		klog.InfoS("Successfully stored Capability Schema in ConfigMap", "configMap", klog.KRef(namespace, cmName))
-		return cmName, nil
+		klog.FromContext(ctx).Info("Successfully stored Capability Schema in ConfigMap", "configMap", klog.KRef(namespace, cmName))
+		return nil
 	}
 
 	cm.Data = data
 	cm.Labels = labels
 	cm.Annotations = annotations
 	if err = k8sClient.Update(ctx, &cm); err != nil {
-		return cmName, fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+		return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
 	}
-	klog.InfoS("Successfully update Capability Schema in ConfigMap", "configMap", klog.KRef(namespace, cmName))
-	return cmName, nil
+	klog.FromContext(ctx).Info("Successfully update Capability Schema in ConfigMap", "configMap", klog.KRef(namespace, cmName))
+	return nil
+}
+
+// CreateOrReplaceImmutableConfigMap creates the immutable ConfigMap to store the data. The existing ConfigMap is turned
+// immutable if it's mutable, e.g. stored before the upgrade, and replaced if it's immutable but stores different data.
+// Only the labels of the immutable ConfigMap storing the same data are updated.
+func (def *CapabilityBaseDefinition) CreateOrReplaceImmutableConfigMap(ctx context.Context, k8sClient client.Client, namespace, cmName,
+	definitionName string, labels map[string]string, data map[string]string, ownerReferences []metav1.OwnerReference) error {
+	if def.ConfigMapNamePrefix != "" {
+		if errs := validation.IsDNS1123Subdomain(cmName); len(errs) > 0 {
+			return fmt.Errorf("invalid ConfigMap name %s with the prefix %s: %s", cmName, def.ConfigMapNamePrefix, strings.Join(errs, "; "))
+		}
+	}
+	merged := make(map[string]string, len(labels)+2)
+	for k, v := range labels {
+		merged[k] = v
+	}
+	merged[types.LabelDefinition] = "schema"
+	merged[types.LabelDefinitionName] = definitionName
+
+	var cm v1.ConfigMap
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: cmName}, &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+	}
+	if err == nil {
+		switch {
+		case cm.Immutable == nil || !*cm.Immutable:
+			cm.Data = data
+			cm.Labels = merged
+			cm.Immutable = pointer.BoolPtr(true)
+			if err = k8sClient.Update(ctx, &cm); err != nil {
+				return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+			}
+			klog.FromContext(ctx).Info("Successfully turned the Capability Schema ConfigMap immutable", "configMap", klog.KRef(namespace, cmName))
+			return nil
+		case reflect.DeepEqual(cm.Data, data):
+			if reflect.DeepEqual(cm.Labels, merged) {
+				return nil
+			}
+			cm.Labels = merged
+			if err = k8sClient.Update(ctx, &cm); err != nil {
+				return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+			}
+			return nil
+		default:
+			// the data of the immutable ConfigMap can't be updated in place
+			if err = k8sClient.Delete(ctx, &cm); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+			}
+		}
+	}
+
+	cm = v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            cmName,
+			Namespace:       namespace,
+			OwnerReferences: ownerReferences,
+			Labels:          merged,
+		},
+		Data:      data,
+		Immutable: pointer.BoolPtr(true),
+	}
+	if err = k8sClient.Create(ctx, &cm); err != nil {
+		return fmt.Errorf(util.ErrUpdateCapabilityInConfigMap, definitionName, err)
+	}
+	klog.FromContext(ctx).Info("Successfully stored Capability Schema in immutable ConfigMap", "configMap", klog.KRef(namespace, cmName))
+	return nil
 }
 
 // getOpenAPISchema is the main function for GetDefinition API
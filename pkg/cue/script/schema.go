@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"cuelang.org/go/cue"
 	"github.com/getkin/kin-openapi/openapi3"
 
 	"github.com/oam-dev/kubevela/pkg/appfile"
@@ -49,9 +50,53 @@ func (c CUE) ParsePropertiesToSchema() (*openapi3.Schema, error) {
 		return nil, err
 	}
 	FixOpenAPISchema("", schema)
+	if parameter, err := template.LookupValue(process.ParameterFieldName); err == nil {
+		SetA11yExtensions(parameter.CueValue(), schema)
+	}
 	return schema, nil
 }
 
+const (
+	// A11yAttr is the CUE attribute carrying the accessibility hints of a parameter, e.g. @a11y(label="Replicas",hint="...")
+	A11yAttr = "a11y"
+	// A11yExtension is the schema extension of the accessibility hints for UI
+	A11yExtension = "x-a11y"
+)
+
+// a11yKeys are the supported keys of the accessibility hints, the other keys are ignored
+var a11yKeys = []string{"label", "description", "hint"}
+
+// SetA11yExtensions sets the accessibility hints of the parameters carried by the CUE attributes as the extensions of the schema
+func SetA11yExtensions(v cue.Value, schema *openapi3.Schema) {
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		prop, ok := schema.Properties[iter.Label()]
+		if !ok || prop.Value == nil {
+			continue
+		}
+		field := iter.Value()
+		attr := field.Attribute(A11yAttr)
+		if attr.Err() == nil {
+			hints := map[string]string{}
+			for _, key := range a11yKeys {
+				if hint, found, _ := attr.Lookup(0, key); found {
+					hints[key] = hint
+				}
+			}
+			if len(hints) > 0 {
+				if prop.Value.Extensions == nil {
+					prop.Value.Extensions = map[string]interface{}{}
+				}
+				prop.Value.Extensions[A11yExtension] = hints
+			}
+		}
+		SetA11yExtensions(field, prop.Value)
+	}
+}
+
 // FixOpenAPISchema fixes tainted `description` filed, missing of title `field`.
 func FixOpenAPISchema(name string, schema *openapi3.Schema) {
 	t := schema.Type
@@ -130,6 +130,16 @@ var (
 	}, []string{"controller"})
 )
 
+var (
+	// WorkflowStepDefinitionPhaseDurationHistogram report the schema generation phase duration of the WorkflowStepDefinition.
+	WorkflowStepDefinitionPhaseDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        "workflowstepdefinition_phase_time_seconds",
+		Help:        "workflowStepDefinition schema generation phase duration distributions.",
+		Buckets:     velametrics.FineGrainedBuckets,
+		ConstLabels: prometheus.Labels{},
+	}, []string{"phase"})
+)
+
 var (
 	// ResourceTrackerNumberGauge report the number of resourceTracker
 	ResourceTrackerNumberGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -48,6 +48,7 @@ var collectorGroup = []prometheus.Collector{
 	ApplicationPhaseCounter,
 	WorkflowStepPhaseGauge,
 	ResourceTrackerNumberGauge,
+	WorkflowStepDefinitionPhaseDurationHistogram,
 	ClusterIsConnectedGauge,
 	ClusterWorkerNumberGauge,
 	ClusterMasterNumberGauge,
@@ -59,6 +59,13 @@ const (
 	LabelPolicyDefinitionName = "policydefinition.oam.dev/name"
 	// LabelWorkflowStepDefinitionName records the name of WorkflowStepDefinition
 	LabelWorkflowStepDefinitionName = "workflowstepdefinition.oam.dev/name"
+	// LabelWorkflowStepDefinitionNamespace records the namespace of WorkflowStepDefinition on the schema ConfigMaps stored
+	// in a centralized namespace, where the ownerReferences can't link them to the definition
+	LabelWorkflowStepDefinitionNamespace = "workflowstepdefinition.oam.dev/namespace"
+	// LabelWorkflowStepDefinitionComplexityScore records the complexity score of the WorkflowStepDefinition parameter schema
+	LabelWorkflowStepDefinitionComplexityScore = "workflowstepdefinition.oam.dev/complexity-score"
+	// LabelWorkflowStepDefinitionStability records the stability level of the WorkflowStepDefinition
+	LabelWorkflowStepDefinitionStability = "workflowstepdefinition.oam.dev/stability"
 
 	// LabelControllerRevisionComponent indicate which component the revision belong to
 	LabelControllerRevisionComponent = "controller.oam.dev/component"
@@ -110,6 +117,9 @@ const (
 
 	// LabelControllerName indicates the controller name
 	LabelControllerName = "controller.oam.dev/name"
+
+	// LabelNamespaceProduction marks the namespace as a production one, e.g. the alpha workflow steps can be blocked from it
+	LabelNamespaceProduction = "namespace.oam.dev/production"
 )
 
 const (
@@ -119,6 +129,15 @@ const (
 	VelaNamespaceUsageTarget = "target"
 )
 
+const (
+	// StabilityAlpha marks the WorkflowStepDefinition as alpha, which may change incompatibly or be removed
+	StabilityAlpha = "alpha"
+	// StabilityBeta marks the WorkflowStepDefinition as beta, which is well tested but may still change
+	StabilityBeta = "beta"
+	// StabilityStable marks the WorkflowStepDefinition as stable
+	StabilityStable = "stable"
+)
+
 const (
 	// ResourceTypeTrait mark this K8s Custom Resource is an OAM trait
 	ResourceTypeTrait = "TRAIT"
@@ -213,6 +232,68 @@ const (
 	// AnnotationControllerRequirement indicates the controller version that can process the application/definition.
 	AnnotationControllerRequirement = "app.oam.dev/controller-version-require"
 
+	// AnnotationDisableReconcile pauses the reconciliation of the definition while it's set to "true"
+	AnnotationDisableReconcile = "app.oam.dev/disable-reconcile"
+
+	// AnnotationSchemaGeneration records the generation of the schema notify ConfigMap, it's bumped on every WorkflowStepDefinition schema change
+	AnnotationSchemaGeneration = "workflowstepdefinition.oam.dev/schema-generation"
+
+	// AnnotationArtifactKeys records the data keys of the artifacts the WorkflowStepDefinition controller stores in the
+	// schema ConfigMap, separated by comma, so that the ones no longer generated are removed
+	AnnotationArtifactKeys = "workflowstepdefinition.oam.dev/artifact-keys"
+
+	// AnnotationAliasOf points the duplicated WorkflowStepDefinition to the identical one it's an alias of, in the format of <namespace>/<name>
+	AnnotationAliasOf = "workflowstepdefinition.oam.dev/alias-of"
+
+	// AnnotationEmbeddedStepDefinitions lists the base WorkflowStepDefinitions in the same namespace, separated by comma,
+	// whose parameters are embedded in the composite WorkflowStepDefinition
+	AnnotationEmbeddedStepDefinitions = "workflowstepdefinition.oam.dev/embeds"
+
+	// AnnotationEventChannels declares the event channels the WorkflowStepDefinition publishes to or subscribes from,
+	// in the format of <channel>=<publish|subscribe> separated by comma
+	AnnotationEventChannels = "workflowstepdefinition.oam.dev/event-channels"
+
+	// AnnotationSchemaChangeAudit records the last schema change of the WorkflowStepDefinition in JSON, it's written on every
+	// schema change to be captured by the audit policies
+	AnnotationSchemaChangeAudit = "workflowstepdefinition.oam.dev/schema-change-audit"
+
+	// AnnotationDefinitionReferences declares the ComponentDefinitions and TraitDefinitions the WorkflowStepDefinition orchestrates
+	// by name, in the format of <component|trait>/<name> separated by comma
+	AnnotationDefinitionReferences = "workflowstepdefinition.oam.dev/references"
+
+	// AnnotationIdempotent declares whether running the WorkflowStepDefinition repeatedly has the same effect as running it once,
+	// in the format of true or false
+	AnnotationIdempotent = "workflowstepdefinition.oam.dev/idempotent"
+
+	// AnnotationSchemaProfiles lists the consumer profiles, separated by comma, the schema variants of the WorkflowStepDefinition
+	// are generated for, e.g. ui,api. It overrides the profiles enabled by the controller
+	AnnotationSchemaProfiles = "workflowstepdefinition.oam.dev/schema-profiles"
+
+	// AnnotationFirstSeen records when the WorkflowStepDefinition was first reconciled on its schema ConfigMap, in the format of RFC 3339
+	AnnotationFirstSeen = "workflowstepdefinition.oam.dev/first-seen"
+
+	// AnnotationExamples declares the documented examples of the WorkflowStepDefinition properties, in the format of a YAML or
+	// JSON list of the properties objects
+	AnnotationExamples = "workflowstepdefinition.oam.dev/examples"
+
+	// AnnotationApprovedRevision approves the WorkflowStepDefinition revision of the name with breaking changes to become the
+	// latest revision
+	AnnotationApprovedRevision = "workflowstepdefinition.oam.dev/approved-revision"
+
+	// AnnotationAdditiveOnly commits the definition to the additive-only evolution of its parameters, the spec changes
+	// removing or narrowing a parameter are rejected
+	AnnotationAdditiveOnly = "definition.oam.dev/additive-only"
+
+	// AnnotationOutputSchema declares the OpenAPI v3 schema of the output of the WorkflowStepDefinition, in the format of YAML or JSON
+	AnnotationOutputSchema = "workflowstepdefinition.oam.dev/output-schema"
+
+	// AnnotationStability declares the stability level of the WorkflowStepDefinition, one of alpha, beta or stable
+	AnnotationStability = "workflowstepdefinition.oam.dev/stability"
+
+	// AnnotationDeprecated marks the definition as deprecated by "true", its spec is frozen at the latest revision and
+	// the further spec changes are not revisioned until the annotation is removed
+	AnnotationDeprecated = "definition.oam.dev/deprecated"
+
 	// AnnotationApplicationServiceAccountName indicates the name of the ServiceAccount to use to apply Components and run Workflow.
 	// ServiceAccount will be used in the local cluster only.
 	AnnotationApplicationServiceAccountName = "app.oam.dev/service-account-name"
@@ -90,7 +90,7 @@ const (
 	// ErrGenerateOpenAPIV2JSONSchemaForCapability is the error while generating OpenAPI v3 schema
 	ErrGenerateOpenAPIV2JSONSchemaForCapability = "cannot generate OpenAPI v3 JSON schema for capability %s: %v"
 	// ErrUpdateCapabilityInConfigMap is the error while creating or updating a capability
-	ErrUpdateCapabilityInConfigMap = "cannot create or update capability %s in ConfigMap: %v"
+	ErrUpdateCapabilityInConfigMap = "cannot create or update capability %s in ConfigMap: %w"
 
 	// ErrUpdateComponentDefinition is the error while update ComponentDefinition
 	ErrUpdateComponentDefinition = "cannot update ComponentDefinition %s: %v"
@@ -26,6 +26,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/component"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/componentdefinition"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/traitdefinition"
+	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/workflowstepdefinition"
 )
 
 // Register will be called in main and register all validation handlers
@@ -36,6 +37,7 @@ func Register(mgr manager.Manager, args controller.Args) {
 		componentdefinition.RegisterMutatingHandler(mgr, args)
 		componentdefinition.RegisterValidatingHandler(mgr, args)
 		traitdefinition.RegisterValidatingHandler(mgr, args)
+		workflowstepdefinition.RegisterValidatingHandler(mgr, args)
 		applicationconfiguration.RegisterMutatingHandler(mgr)
 		applicationconfiguration.RegisterValidatingHandler(mgr, args)
 		component.RegisterMutatingHandler(mgr, args)
@@ -45,12 +47,14 @@ func Register(mgr manager.Manager, args controller.Args) {
 		componentdefinition.RegisterMutatingHandler(mgr, args)
 		componentdefinition.RegisterValidatingHandler(mgr, args)
 		traitdefinition.RegisterValidatingHandler(mgr, args)
+		workflowstepdefinition.RegisterValidatingHandler(mgr, args)
 	case "v0.3":
 		application.RegisterValidatingHandler(mgr, args)
 		application.RegisterMutatingHandler(mgr)
 		componentdefinition.RegisterMutatingHandler(mgr, args)
 		componentdefinition.RegisterValidatingHandler(mgr, args)
 		traitdefinition.RegisterValidatingHandler(mgr, args)
+		workflowstepdefinition.RegisterValidatingHandler(mgr, args)
 	case "v0.2":
 		applicationconfiguration.RegisterMutatingHandler(mgr)
 		applicationconfiguration.RegisterValidatingHandler(mgr, args)
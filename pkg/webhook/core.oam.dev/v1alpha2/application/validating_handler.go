@@ -44,6 +44,8 @@ type ValidatingHandler struct {
 	dm     discoverymapper.DiscoveryMapper
 	pd     *packages.PackageDiscover
 	Client client.Client
+	// blockAlphaSteps blocks the applications in the production namespaces from using the alpha workflow steps
+	blockAlphaSteps bool
 	// Decoder decodes objects
 	Decoder *admission.Decoder
 }
@@ -120,5 +122,9 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 // RegisterValidatingHandler will register application validate handler to the webhook
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1beta1-applications", &webhook.Admission{Handler: &ValidatingHandler{dm: args.DiscoveryMapper, pd: args.PackageDiscover}})
+	server.Register("/validating-core-oam-dev-v1beta1-applications", &webhook.Admission{Handler: &ValidatingHandler{
+		dm:              args.DiscoveryMapper,
+		pd:              args.PackageDiscover,
+		blockAlphaSteps: args.StepDefinitionBlockAlphaInProduction,
+	}})
 }
@@ -21,10 +21,15 @@ import (
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admission/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
@@ -469,4 +474,41 @@ var _ = Describe("Test Application Validator", func() {
 		resp := handler.Handle(ctx, req)
 		Expect(resp.Allowed).Should(BeFalse())
 	})
+
+	It("Test Application Validator alpha workflow step in production namespace", func() {
+		prodNs := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "stability-production",
+			Labels: map[string]string{oam.LabelNamespaceProduction: "true"},
+		}}
+		Expect(k8sClient.Create(ctx, &prodNs)).Should(BeNil())
+		for name, stability := range map[string]string{"alpha-step": oam.StabilityAlpha, "stable-step": oam.StabilityStable} {
+			def := &v1beta1.WorkflowStepDefinition{ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   oam.SystemDefinitionNamespace,
+				Annotations: map[string]string{oam.AnnotationStability: stability},
+			}}
+			def.Spec.Schematic = &common.Schematic{CUE: &common.CUE{Template: "parameter: {}"}}
+			Expect(k8sClient.Create(ctx, def)).Should(BeNil())
+		}
+		app := &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-with-alpha-step", Namespace: prodNs.Name},
+			Spec: v1beta1.ApplicationSpec{Workflow: &v1beta1.Workflow{Steps: []workflowv1alpha1.WorkflowStep{{
+				WorkflowStepBase: workflowv1alpha1.WorkflowStepBase{Name: "deploy", Type: "stable-step"},
+				SubSteps:         []workflowv1alpha1.WorkflowStepBase{{Name: "try", Type: "alpha-step"}},
+			}}}},
+		}
+		stabilityHandler := &ValidatingHandler{Client: k8sClient}
+		nsCtx := util.SetNamespaceInCtx(ctx, prodNs.Name)
+		Expect(stabilityHandler.ValidateStepStability(nsCtx, app)).Should(BeEmpty())
+
+		By("block the alpha step when the enforcement is on")
+		stabilityHandler.blockAlphaSteps = true
+		errs := stabilityHandler.ValidateStepStability(nsCtx, app)
+		Expect(errs).Should(HaveLen(1))
+		Expect(errs[0].Field).Should(Equal("spec.workflow.steps[0].subSteps[0].type"))
+		Expect(errs[0].Detail).Should(ContainSubstring("the alpha workflow step alpha-step cannot be used in the production namespace stability-production"))
+
+		By("allow the alpha step in the namespace not labeled as production")
+		Expect(stabilityHandler.ValidateStepStability(util.SetNamespaceInCtx(ctx, "default"), app)).Should(BeEmpty())
+	})
 })
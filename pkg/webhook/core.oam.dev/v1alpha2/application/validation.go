@@ -19,9 +19,11 @@ package application
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -59,6 +61,42 @@ func (h *ValidatingHandler) ValidateWorkflow(ctx context.Context, app *v1beta1.A
 	return errs
 }
 
+// ValidateStepStability validates the Application in the production namespace doesn't use the alpha workflow steps
+func (h *ValidatingHandler) ValidateStepStability(ctx context.Context, app *v1beta1.Application) field.ErrorList {
+	if !h.blockAlphaSteps || app.Spec.Workflow == nil {
+		return nil
+	}
+	ns := &corev1.Namespace{}
+	if err := h.Client.Get(ctx, client.ObjectKey{Name: util.GetDefinitionNamespaceWithCtx(ctx)}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return field.ErrorList{field.InternalError(field.NewPath("metadata", "namespace"), err)}
+	}
+	if ns.Labels[oam.LabelNamespaceProduction] != "true" {
+		return nil
+	}
+	var errs field.ErrorList
+	checkStep := func(path *field.Path, stepType string) {
+		def := &v1beta1.WorkflowStepDefinition{}
+		if err := util.GetDefinition(ctx, h.Client, def, stepType); err != nil {
+			// the builtin steps have no definition, and the missing definitions are left to the workflow to report
+			return
+		}
+		if strings.EqualFold(strings.TrimSpace(def.GetAnnotations()[oam.AnnotationStability]), oam.StabilityAlpha) {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("the alpha workflow step %s cannot be used in the production namespace %s", stepType, ns.Name)))
+		}
+	}
+	for i, step := range app.Spec.Workflow.Steps {
+		path := field.NewPath("spec", "workflow", "steps").Index(i)
+		checkStep(path.Child("type"), step.Type)
+		for j, sub := range step.SubSteps {
+			checkStep(path.Child("subSteps").Index(j).Child("type"), sub.Type)
+		}
+	}
+	return errs
+}
+
 // ValidateTimeout validates the timeout of steps
 func (h *ValidatingHandler) ValidateTimeout(name, timeout string) field.ErrorList {
 	var errs field.ErrorList
@@ -99,6 +137,7 @@ func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.App
 	var errs field.ErrorList
 
 	errs = append(errs, h.ValidateWorkflow(ctx, app)...)
+	errs = append(errs, h.ValidateStepStability(ctx, app)...)
 	errs = append(errs, h.ValidateComponents(ctx, app)...)
 	return errs
 }
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+var workflowStepDefGVR = v1beta1.SchemeGroupVersion.WithResource("workflowstepdefinitions")
+
+// ValidatingHandler handles validation of workflow step definition
+type ValidatingHandler struct {
+	// Decoder decodes object
+	Decoder *admission.Decoder
+	Client  client.Client
+}
+
+var _ inject.Client = &ValidatingHandler{}
+
+// InjectClient injects the client into the ValidatingHandler
+func (h *ValidatingHandler) InjectClient(c client.Client) error {
+	if h.Client != nil {
+		return nil
+	}
+	h.Client = c
+	return nil
+}
+
+var _ admission.Handler = &ValidatingHandler{}
+
+// Handle validate workflow step definition
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &v1beta1.WorkflowStepDefinition{}
+	if req.Resource.String() != workflowStepDefGVR.String() {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("expect resource to be %s", workflowStepDefGVR))
+	}
+
+	if req.Operation == admissionv1.Create || req.Operation == admissionv1.Update {
+		err := h.Decoder.Decode(req, obj)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if req.Operation == admissionv1.Update {
+			// the metadata-only updates, e.g. the finalizer patches of the controller, must pass even if the stored
+			// schema can't be rendered, otherwise such definition can't be finalized and deleted
+			if obj.DeletionTimestamp != nil {
+				return admission.ValidationResponse(true, "")
+			}
+			oldObj := &v1beta1.WorkflowStepDefinition{}
+			if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
+				return admission.Errored(http.StatusBadRequest, err)
+			}
+			if apiequality.Semantic.DeepEqual(oldObj.Spec, obj.Spec) {
+				return admission.ValidationResponse(true, "")
+			}
+		}
+		if err = ValidateSchema(ctx, h.Client, obj); err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+var _ admission.DecoderInjector = &ValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ValidatingHandler
+func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
+
+// RegisterValidatingHandler will register WorkflowStepDefinition validation to webhook
+func RegisterValidatingHandler(mgr manager.Manager, _ controller.Args) {
+	server := mgr.GetWebhookServer()
+	server.Register("/validating-core-oam-dev-v1beta1-workflowstepdefinitions", &webhook.Admission{Handler: &ValidatingHandler{}})
+}
+
+// ValidateSchema validates whether the parameter schema of the WorkflowStepDefinition can be rendered. The schema is
+// rendered in the dry-run mode of StoreOpenAPISchema, so no ConfigMap is written at admission
+func ValidateSchema(ctx context.Context, k8sClient client.Client, wd *v1beta1.WorkflowStepDefinition) error {
+	def := utils.NewCapabilityStepDef(wd)
+	def.DryRun = true
+	if _, err := def.StoreOpenAPISchema(ctx, k8sClient, wd.Namespace, wd.Name, ""); err != nil {
+		return fmt.Errorf("the parameter schema of WorkflowStepDefinition %s cannot be rendered: %w", wd.Name, err)
+	}
+	return nil
+}
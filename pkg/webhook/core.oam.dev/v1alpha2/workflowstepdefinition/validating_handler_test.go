@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+var handler ValidatingHandler
+var reqResource metav1.GroupVersionResource
+var decoder *admission.Decoder
+
+func TestWorkflowStepDefinition(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "WorkflowStepDefinition Suite")
+}
+
+var _ = BeforeSuite(func(done Done) {
+	var err error
+	decoder, err = admission.NewDecoder(velacommon.Scheme)
+	Expect(err).Should(BeNil())
+
+	close(done)
+})
+
+func newStepDefinitionRequest(template string) admission.Request {
+	wd := v1beta1.WorkflowStepDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.WorkflowStepDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "apply-config", Namespace: "default"},
+		Spec: v1beta1.WorkflowStepDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+		},
+	}
+	wdRaw, err := json.Marshal(wd)
+	Expect(err).Should(BeNil())
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Resource:  reqResource,
+			Object:    runtime.RawExtension{Raw: wdRaw},
+		},
+	}
+}
+
+var _ = Describe("Test WorkflowStepDefinition validating handler", func() {
+	BeforeEach(func() {
+		reqResource = metav1.GroupVersionResource{
+			Group:    v1beta1.Group,
+			Version:  v1beta1.Version,
+			Resource: "workflowstepdefinitions"}
+		handler = ValidatingHandler{}
+		Expect(handler.InjectClient(fake.NewClientBuilder().WithScheme(velacommon.Scheme).Build())).Should(Succeed())
+		Expect(handler.InjectDecoder(decoder)).Should(Succeed())
+	})
+
+	It("Test wrong resource of admission request", func() {
+		req := newStepDefinitionRequest("")
+		req.Resource = metav1.GroupVersionResource{
+			Group:    v1beta1.Group,
+			Version:  v1beta1.Version,
+			Resource: "foos"}
+		resp := handler.Handle(context.TODO(), req)
+		Expect(resp.Allowed).Should(BeFalse())
+	})
+
+	It("Test bad admission request", func() {
+		req := newStepDefinitionRequest("")
+		req.Object = runtime.RawExtension{Raw: []byte("bad request")}
+		resp := handler.Handle(context.TODO(), req)
+		Expect(resp.Allowed).Should(BeFalse())
+	})
+
+	It("Test workflow step definition with a valid parameter block", func() {
+		resp := handler.Handle(context.TODO(), newStepDefinitionRequest(`
+parameter: {
+	name: string
+	replicas: *1 | int
+}
+`))
+		Expect(resp.Allowed).Should(BeTrue())
+	})
+
+	It("Test workflow step definition with a malformed parameter block", func() {
+		resp := handler.Handle(context.TODO(), newStepDefinitionRequest(`
+parameter: {
+	name: string
+	replicas: *1 | int
+`))
+		Expect(resp.Allowed).Should(BeFalse())
+		Expect(string(resp.Result.Reason)).Should(ContainSubstring("the parameter schema of WorkflowStepDefinition apply-config cannot be rendered"))
+
+		By("no ConfigMap should be written at admission")
+		cms := &corev1.ConfigMapList{}
+		Expect(handler.Client.List(context.TODO(), cms, client.InNamespace("default"))).Should(Succeed())
+		Expect(cms.Items).Should(BeEmpty())
+	})
+
+	It("Test metadata-only updates of workflow step definition with a malformed parameter block", func() {
+		malformed := `
+parameter: {
+	name: string
+`
+		newUpdateRequest := func(mutate func(wd *v1beta1.WorkflowStepDefinition)) admission.Request {
+			req := newStepDefinitionRequest(malformed)
+			req.Operation = admissionv1.Update
+			req.OldObject = req.Object
+			wd := &v1beta1.WorkflowStepDefinition{}
+			Expect(json.Unmarshal(req.Object.Raw, wd)).Should(Succeed())
+			mutate(wd)
+			wdRaw, err := json.Marshal(wd)
+			Expect(err).Should(BeNil())
+			req.Object = runtime.RawExtension{Raw: wdRaw}
+			return req
+		}
+
+		By("the finalizer can be added")
+		resp := handler.Handle(context.TODO(), newUpdateRequest(func(wd *v1beta1.WorkflowStepDefinition) {
+			wd.Finalizers = []string{"workflowstepdefinition.finalizer.core.oam.dev"}
+		}))
+		Expect(resp.Allowed).Should(BeTrue())
+
+		By("the finalizer can be removed from the deleting definition")
+		req := newUpdateRequest(func(wd *v1beta1.WorkflowStepDefinition) {
+			wd.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			wd.Finalizers = []string{"workflowstepdefinition.finalizer.core.oam.dev"}
+		})
+		req.OldObject = req.Object
+		wd := &v1beta1.WorkflowStepDefinition{}
+		Expect(json.Unmarshal(req.Object.Raw, wd)).Should(Succeed())
+		wd.Finalizers = nil
+		wdRaw, err := json.Marshal(wd)
+		Expect(err).Should(BeNil())
+		req.Object = runtime.RawExtension{Raw: wdRaw}
+		resp = handler.Handle(context.TODO(), req)
+		Expect(resp.Allowed).Should(BeTrue())
+
+		By("the spec change is still validated")
+		resp = handler.Handle(context.TODO(), newUpdateRequest(func(wd *v1beta1.WorkflowStepDefinition) {
+			wd.Spec.Schematic.CUE.Template += "\n"
+		}))
+		Expect(resp.Allowed).Should(BeFalse())
+	})
+})
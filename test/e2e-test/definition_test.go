@@ -226,6 +226,21 @@ var _ = Describe("ComponentDefinition Normal tests", func() {
 		})
 	})
 
+	Context("Test dynamic admission control for workflowStepDefinition", func() {
+		It("Test workflowStepDefinition with a malformed parameter block", func() {
+			wd := &v1beta1.WorkflowStepDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-wd-malformed-parameter", Namespace: namespace},
+				Spec: v1beta1.WorkflowStepDefinitionSpec{
+					Schematic: &common.Schematic{CUE: &common.CUE{Template: `
+parameter: {
+	name: string
+`}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, wd)).Should(HaveOccurred())
+		})
+	})
+
 	It("Test notification step definition", func() {
 		_, file, _, _ := runtime.Caller(0)
 		Expect(testdef.InstallDefinitionFromYAML(ctx, k8sClient, filepath.Join(file, "../../../charts/vela-core/templates/defwithtemplate/notification.yaml"), func(s string) string {